@@ -6,10 +6,12 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func RegisterPublicEndpoints(router *gin.Engine, userHandlers *handler.User, authHandlers *handler.Auth, leaveRequestHandlers *handler.LeaveRequest) {
+func RegisterPublicEndpoints(router *gin.Engine, userHandlers *handler.User, authHandlers *handler.Auth, leaveRequestHandlers *handler.LeaveRequest, leaveBalanceHandlers *handler.LeaveBalance, accrualPolicyHandlers *handler.AccrualPolicy, leaveTypeHandlers *handler.LeaveType, leavePolicyHandlers *handler.LeavePolicy, autoApprovalRuleHandlers *handler.AutoApprovalRule, holidayHandlers *handler.Holiday, approvalChainHandlers *handler.ApprovalChain, delegationHandlers *handler.Delegation, calendarHandlers *handler.Calendar) {
 	public := router.Group("/api/v1")
 	{
 		public.POST("/auth/login", authHandlers.Login)
+		public.GET("/calendar-feeds/:token/me.ics", calendarHandlers.GetUserFeed)
+		public.GET("/calendar-feeds/:token/team.ics", calendarHandlers.GetTeamFeed)
 	}
 
 	protected := router.Group("/api/v1")
@@ -17,7 +19,33 @@ func RegisterPublicEndpoints(router *gin.Engine, userHandlers *handler.User, aut
 	{
 		protected.POST("/leave-requests", leaveRequestHandlers.CreateLeaveRequest)
 		protected.GET("/my-leave-requests", leaveRequestHandlers.GetMyLeaveRequests)
+		protected.PUT("/leave-requests/:id", leaveRequestHandlers.UpdateLeaveRequest)
+		protected.PATCH("/leave-requests/:id", leaveRequestHandlers.UpdateLeaveRequest)
+		protected.DELETE("/leave-requests/:id", leaveRequestHandlers.DeleteLeaveRequest)
 		protected.PATCH("/leave-requests/:id/submit", leaveRequestHandlers.Submit)
+		protected.PATCH("/leave-requests/:id/cancel", leaveRequestHandlers.Cancel)
+		protected.GET("/leave-requests/:id/history", leaveRequestHandlers.GetLeaveRequestHistory)
+		protected.GET("/leave-requests/:id/actions", leaveRequestHandlers.GetLeaveRequestActions)
+		protected.POST("/leave-requests/:id/attachments", leaveRequestHandlers.UploadAttachment)
+		protected.GET("/leave-requests/:id/attachments", leaveRequestHandlers.GetAttachments)
+		protected.GET("/leave-requests/:id/attachments/:attachmentId", leaveRequestHandlers.DownloadAttachment)
+		protected.DELETE("/leave-requests/:id/attachments/:attachmentId", leaveRequestHandlers.DeleteAttachment)
+		protected.GET("/my-balances", leaveBalanceHandlers.GetMyBalances)
+		protected.GET("/team/leave-requests", leaveRequestHandlers.GetTeamLeaveRequests)
+		protected.GET("/my-delegations", delegationHandlers.GetMyDelegations)
+		protected.POST("/my-delegations", delegationHandlers.CreateDelegation)
+		protected.DELETE("/my-delegations/:id", delegationHandlers.DeleteDelegation)
+		protected.PATCH("/leave-requests/:id/approve", leaveRequestHandlers.Approve)
+		protected.PATCH("/leave-requests/:id/reject", leaveRequestHandlers.Reject)
+		protected.POST("/leave-requests/decisions", leaveRequestHandlers.BulkDecide)
+		protected.PATCH("/leave-requests/:id/cancellation/approve", leaveRequestHandlers.ApproveCancellation)
+		protected.PATCH("/leave-requests/:id/cancellation/reject", leaveRequestHandlers.RejectCancellation)
+		protected.GET("/holidays", holidayHandlers.GetHolidays)
+		protected.GET("/leave-types", leaveTypeHandlers.GetLeaveTypes)
+		protected.GET("/calendar", calendarHandlers.GetCalendar)
+		protected.POST("/my-calendar-feed-token", calendarHandlers.CreateFeedToken)
+		protected.DELETE("/my-calendar-feed-token", calendarHandlers.RevokeFeedToken)
+		protected.GET("/leave-requests/:id/ics", calendarHandlers.DownloadLeaveRequest)
 
 	}
 
@@ -27,10 +55,37 @@ func RegisterPublicEndpoints(router *gin.Engine, userHandlers *handler.User, aut
 		protectedAdmin.GET("/users", userHandlers.GetAllUsers)
 		protectedAdmin.GET("/users/:id", userHandlers.GetUser)
 		protectedAdmin.POST("/users", userHandlers.CreateUser)
+		protectedAdmin.POST("/users/import", userHandlers.ImportUsers)
+		protectedAdmin.PUT("/users/:id/manager", userHandlers.SetManager)
+		protectedAdmin.GET("/users/:id/balances", leaveBalanceHandlers.GetUserBalances)
+		protectedAdmin.POST("/users/:id/balance-entries", leaveBalanceHandlers.CreateBalanceEntry)
+
+		protectedAdmin.GET("/accrual-policies", accrualPolicyHandlers.GetAccrualPolicies)
+		protectedAdmin.PUT("/accrual-policies/:type", accrualPolicyHandlers.UpsertAccrualPolicy)
+
+		protectedAdmin.PUT("/leave-types/:code", leaveTypeHandlers.UpsertLeaveType)
+
+		protectedAdmin.GET("/leave-policies", leavePolicyHandlers.GetLeavePolicies)
+		protectedAdmin.POST("/leave-policies", leavePolicyHandlers.CreateLeavePolicy)
+		protectedAdmin.PUT("/leave-policies/:id", leavePolicyHandlers.UpdateLeavePolicy)
+		protectedAdmin.DELETE("/leave-policies/:id", leavePolicyHandlers.DeleteLeavePolicy)
+
+		protectedAdmin.GET("/auto-approval-rules", autoApprovalRuleHandlers.GetAutoApprovalRules)
+		protectedAdmin.POST("/auto-approval-rules", autoApprovalRuleHandlers.CreateAutoApprovalRule)
+		protectedAdmin.PUT("/auto-approval-rules/:id", autoApprovalRuleHandlers.UpdateAutoApprovalRule)
+		protectedAdmin.DELETE("/auto-approval-rules/:id", autoApprovalRuleHandlers.DeleteAutoApprovalRule)
+
+		protectedAdmin.GET("/approval-chains", approvalChainHandlers.GetApprovalChains)
+		protectedAdmin.PUT("/approval-chains", approvalChainHandlers.UpsertApprovalChain)
+		protectedAdmin.DELETE("/approval-chains/:id", approvalChainHandlers.DeleteApprovalChain)
+
+		protectedAdmin.POST("/holidays", holidayHandlers.CreateHoliday)
+		protectedAdmin.POST("/holidays/import", holidayHandlers.ImportHolidays)
+		protectedAdmin.PUT("/holidays/:id", holidayHandlers.UpdateHoliday)
+		protectedAdmin.DELETE("/holidays/:id", holidayHandlers.DeleteHoliday)
 
 		protectedAdmin.GET("/leave-requests", leaveRequestHandlers.GetAllLeaveRequests)
+		protectedAdmin.GET("/leave-requests/export", leaveRequestHandlers.ExportLeaveRequests)
 		protectedAdmin.GET("/leave-requests/:id", leaveRequestHandlers.GetLeaveRequest)
-		protectedAdmin.PATCH("/leave-requests/:id/approve", leaveRequestHandlers.Approve)
-		protectedAdmin.PATCH("/leave-requests/:id/reject", leaveRequestHandlers.Reject)
 	}
 }
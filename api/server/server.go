@@ -3,6 +3,7 @@ package serve
 import (
 	"context"
 	"errors"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -26,9 +27,15 @@ func NewServer(l zerolog.Logger, router *gin.Engine, config *config.Config) *Ser
 
 // Serve creates a new http.Server with support for graceful shutdown
 func (s *Server) Serve() {
+	// Requests run on baseCtx, so queries still in flight when the shutdown timeout passes
+	// are cancelled instead of outliving the server.
+	baseCtx, cancelRequests := context.WithCancel(context.Background())
+	defer cancelRequests()
+
 	srv := &http.Server{
-		Addr:    s.config.Server.Address,
-		Handler: s.router.Handler(),
+		Addr:        s.config.Server.Address,
+		Handler:     s.router.Handler(),
+		BaseContext: func(net.Listener) context.Context { return baseCtx },
 	}
 
 	go func() {
@@ -51,6 +58,7 @@ func (s *Server) Serve() {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 	if err := srv.Shutdown(ctx); err != nil {
+		cancelRequests()
 		s.l.Fatal().Err(err).Msg("Server Shutdown")
 	}
 	// catching ctx.Done(). timeout of 30 seconds.
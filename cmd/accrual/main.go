@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"time"
+
+	"github.com/devonLoen/leave-request-service/config"
+	"github.com/devonLoen/leave-request-service/internal/app/rest_api/database"
+	"github.com/devonLoen/leave-request-service/internal/app/rest_api/repository"
+	"github.com/devonLoen/leave-request-service/internal/app/rest_api/usecase"
+)
+
+func parseDate(name, value string, fallback time.Time) time.Time {
+	if value == "" {
+		return fallback
+	}
+
+	date, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		log.Fatalf("Invalid -%s date %q, expected YYYY-MM-DD", name, value)
+	}
+
+	return date
+}
+
+func main() {
+	fromFlag := flag.String("from", "", "First day to replay (YYYY-MM-DD), defaults to January 1st of the current year")
+	toFlag := flag.String("to", "", "Last day to replay (YYYY-MM-DD), defaults to today")
+	flag.Parse()
+
+	now := time.Now().UTC()
+	from := parseDate("from", *fromFlag, time.Date(now.Year(), time.January, 1, 0, 0, 0, 0, time.UTC))
+	to := parseDate("to", *toFlag, now)
+
+	if from.After(to) {
+		log.Fatal("-from cannot be after -to")
+	}
+
+	conf := config.NewConfig()
+
+	client, err := database.NewSQLClient(database.Config{
+		DBDriver:          conf.Database.DatabaseDriver,
+		DBSource:          conf.Database.DatabaseSource,
+		MaxOpenConns:      5,
+		MaxIdleConns:      5,
+		ConnMaxIdleTime:   time.Minute,
+		ConnectionTimeout: 5 * time.Second,
+	})
+	if err != nil {
+		log.Fatal("Failed to connect to DB:", err)
+	}
+	defer client.Close()
+
+	database.SetTimeouts(database.Timeouts{
+		Read:        conf.Database.ReadTimeout,
+		Write:       conf.Database.WriteTimeout,
+		Transaction: conf.Database.TransactionTimeout,
+		Export:      conf.Database.ExportTimeout,
+	})
+
+	accrualUsecase := usecase.NewAccrualUsecase(
+		repository.NewAccrualPolicyRepository(client.DB),
+		repository.NewLeaveTypeRepository(client.DB),
+		repository.NewLeavePolicyRepository(client.DB),
+		repository.NewLeaveBalanceRepository(client.DB),
+		repository.NewUserRepository(client.DB),
+	)
+
+	log.Printf("Running accrual from %s to %s...", from.Format(time.DateOnly), to.Format(time.DateOnly))
+
+	result, err := accrualUsecase.Run(context.Background(), from, to)
+	if err != nil {
+		log.Fatal("Accrual failed:", err)
+	}
+
+	log.Printf("Accrual finished: %d accrued, %d carried over, %d expired", result.Accrued, result.CarriedOver, result.Expired)
+}
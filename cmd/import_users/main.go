@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"time"
+
+	"github.com/devonLoen/leave-request-service/config"
+	"github.com/devonLoen/leave-request-service/internal/app/rest_api/database"
+	"github.com/devonLoen/leave-request-service/internal/app/rest_api/repository"
+	"github.com/devonLoen/leave-request-service/internal/app/rest_api/usecase"
+)
+
+func main() {
+	fileFlag := flag.String("file", "", "CSV file with fullName, email, role and optional manager and department columns")
+	dryRunFlag := flag.Bool("dry-run", false, "Validate the file without creating any user")
+	flag.Parse()
+
+	if *fileFlag == "" {
+		log.Fatal("-file is required")
+	}
+
+	file, err := os.Open(*fileFlag)
+	if err != nil {
+		log.Fatal("Failed to open file:", err)
+	}
+	defer file.Close()
+
+	conf := config.NewConfig()
+
+	client, err := database.NewSQLClient(database.Config{
+		DBDriver:          conf.Database.DatabaseDriver,
+		DBSource:          conf.Database.DatabaseSource,
+		MaxOpenConns:      5,
+		MaxIdleConns:      5,
+		ConnMaxIdleTime:   time.Minute,
+		ConnectionTimeout: 5 * time.Second,
+	})
+	if err != nil {
+		log.Fatal("Failed to connect to DB:", err)
+	}
+	defer client.Close()
+
+	database.SetTimeouts(database.Timeouts{
+		Read:        conf.Database.ReadTimeout,
+		Write:       conf.Database.WriteTimeout,
+		Transaction: conf.Database.TransactionTimeout,
+		Export:      conf.Database.ExportTimeout,
+	})
+
+	userUsecase := usecase.NewUserUsecase(
+		repository.NewUserRepository(client.DB),
+		repository.NewUnitOfWork(client.DB),
+	)
+
+	result, errResp := userUsecase.ImportUsers(context.Background(), file, *dryRunFlag)
+	if errResp != nil {
+		log.Fatal("Import failed: ", errResp.Message)
+	}
+
+	for _, row := range result.Rows {
+		for field, message := range row.Errors {
+			log.Printf("Row %d (%s): %s: %s", row.Row, row.Email, field, message)
+		}
+	}
+
+	log.Printf("Import finished: %d created, %d valid, %d invalid (dry run: %t)", result.Created, result.Valid, result.Invalid, result.DryRun)
+}
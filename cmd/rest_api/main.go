@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"time"
 
 	serve "github.com/devonLoen/leave-request-service/api/server"
@@ -8,6 +9,8 @@ import (
 	"github.com/devonLoen/leave-request-service/config"
 	"github.com/devonLoen/leave-request-service/internal/app/rest_api/database"
 	"github.com/devonLoen/leave-request-service/internal/app/rest_api/handler"
+	"github.com/devonLoen/leave-request-service/internal/app/rest_api/job"
+	"github.com/devonLoen/leave-request-service/internal/app/rest_api/pkg/storage"
 	"github.com/devonLoen/leave-request-service/internal/app/rest_api/pkg/util"
 	"github.com/devonLoen/leave-request-service/internal/app/rest_api/repository"
 	"github.com/devonLoen/leave-request-service/internal/app/rest_api/usecase"
@@ -41,11 +44,20 @@ func main() {
 		}
 	}()
 
+	database.SetTimeouts(database.Timeouts{
+		Read:        config.Database.ReadTimeout,
+		Write:       config.Database.WriteTimeout,
+		Transaction: config.Database.TransactionTimeout,
+		Export:      config.Database.ExportTimeout,
+	})
+
 	util.SetupJWT(config.JWT.Secret)
 
 	userRepo := repository.NewUserRepository(client.DB)
 
-	userUsecase := usecase.NewUserUsecase(userRepo)
+	unitOfWork := repository.NewUnitOfWork(client.DB)
+
+	userUsecase := usecase.NewUserUsecase(userRepo, unitOfWork)
 
 	userHandler := handler.NewUserHandler(userUsecase)
 
@@ -55,16 +67,83 @@ func main() {
 
 	leaveRequestRepo := repository.NewLeaveRequestRepository(client.DB)
 
-	leaveRequestUsecase := usecase.NewLeaveRequestUsecase(leaveRequestRepo)
+	leaveBalanceRepo := repository.NewLeaveBalanceRepository(client.DB)
+
+	holidayRepo := repository.NewHolidayRepository(client.DB)
+
+	leaveTypeRepo := repository.NewLeaveTypeRepository(client.DB)
+
+	leavePolicyRepo := repository.NewLeavePolicyRepository(client.DB)
+
+	autoApprovalRuleRepo := repository.NewAutoApprovalRuleRepository(client.DB)
+
+	approvalRepo := repository.NewApprovalRepository(client.DB)
+
+	delegationRepo := repository.NewDelegationRepository(client.DB)
+
+	attachmentRepo := repository.NewAttachmentRepository(client.DB)
+
+	attachmentStorage, err := storage.NewLocalStorage(config.Storage.AttachmentDir)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to initialize attachment storage")
+		return
+	}
+
+	leaveRequestUsecase := usecase.NewLeaveRequestUsecase(leaveRequestRepo, leaveBalanceRepo, holidayRepo, leaveTypeRepo, leavePolicyRepo, autoApprovalRuleRepo, userRepo, approvalRepo, delegationRepo, attachmentRepo, attachmentStorage, unitOfWork)
 
 	leaveRequestHandler := handler.NewLeaveRequestHandler(leaveRequestUsecase)
 
+	leaveBalanceUsecase := usecase.NewLeaveBalanceUsecase(leaveBalanceRepo, leaveTypeRepo, userRepo)
+
+	leaveBalanceHandler := handler.NewLeaveBalanceHandler(leaveBalanceUsecase)
+
+	accrualPolicyRepo := repository.NewAccrualPolicyRepository(client.DB)
+
+	accrualUsecase := usecase.NewAccrualUsecase(accrualPolicyRepo, leaveTypeRepo, leavePolicyRepo, leaveBalanceRepo, userRepo)
+
+	accrualPolicyHandler := handler.NewAccrualPolicyHandler(accrualUsecase)
+
+	leaveTypeUsecase := usecase.NewLeaveTypeUsecase(leaveTypeRepo)
+
+	leaveTypeHandler := handler.NewLeaveTypeHandler(leaveTypeUsecase)
+
+	leavePolicyUsecase := usecase.NewLeavePolicyUsecase(leavePolicyRepo, leaveTypeRepo)
+
+	leavePolicyHandler := handler.NewLeavePolicyHandler(leavePolicyUsecase)
+
+	autoApprovalRuleUsecase := usecase.NewAutoApprovalRuleUsecase(autoApprovalRuleRepo, leaveTypeRepo)
+
+	autoApprovalRuleHandler := handler.NewAutoApprovalRuleHandler(autoApprovalRuleUsecase)
+
+	holidayUsecase := usecase.NewHolidayUsecase(holidayRepo, leaveRequestRepo, leaveBalanceRepo, leaveTypeRepo, unitOfWork)
+
+	holidayHandler := handler.NewHolidayHandler(holidayUsecase)
+
+	approvalChainUsecase := usecase.NewApprovalChainUsecase(approvalRepo, leaveTypeRepo)
+
+	approvalChainHandler := handler.NewApprovalChainHandler(approvalChainUsecase)
+
+	delegationUsecase := usecase.NewDelegationUsecase(delegationRepo, userRepo)
+
+	delegationHandler := handler.NewDelegationHandler(delegationUsecase)
+
+	calendarFeedTokenRepo := repository.NewCalendarFeedTokenRepository(client.DB)
+
+	calendarUsecase := usecase.NewCalendarUsecase(leaveRequestRepo, holidayRepo, userRepo, calendarFeedTokenRepo)
+
+	calendarHandler := handler.NewCalendarHandler(calendarUsecase)
+
+	jobCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+
+	go job.NewAccrualJob(log.Logger, accrualUsecase, config.Accrual.Interval).Start(jobCtx)
+
 	cors := config.CorsNew()
 
 	router := gin.Default()
 	router.Use(cors)
 
-	routes.RegisterPublicEndpoints(router, userHandler, authHandler, leaveRequestHandler)
+	routes.RegisterPublicEndpoints(router, userHandler, authHandler, leaveRequestHandler, leaveBalanceHandler, accrualPolicyHandler, leaveTypeHandler, leavePolicyHandler, autoApprovalRuleHandler, holidayHandler, approvalChainHandler, delegationHandler, calendarHandler)
 
 	server := serve.NewServer(log.Logger, router, config)
 	server.Serve()
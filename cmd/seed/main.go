@@ -24,4 +24,6 @@ func main() {
 	}
 
 	seeder.SeedSuperAdmin(db)
+	seeder.SeedAccrualPolicies(db)
+	seeder.SeedApprovalChains(db)
 }
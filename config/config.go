@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"time"
 
 	constants "github.com/devonLoen/leave-request-service/internal/app/rest_api/constant"
 	"github.com/gin-contrib/cors"
@@ -16,6 +17,16 @@ type Config struct {
 	Database   databaseConfig
 	SuperAdmin superAdminConfig
 	JWT        jwtConfig
+	Accrual    accrualConfig
+	Storage    storageConfig
+}
+
+type storageConfig struct {
+	AttachmentDir string
+}
+
+type accrualConfig struct {
+	Interval time.Duration
 }
 
 type jwtConfig struct {
@@ -32,8 +43,12 @@ type serverConfig struct {
 }
 
 type databaseConfig struct {
-	DatabaseDriver string
-	DatabaseSource string
+	DatabaseDriver     string
+	DatabaseSource     string
+	ReadTimeout        time.Duration
+	WriteTimeout       time.Duration
+	TransactionTimeout time.Duration
+	ExportTimeout      time.Duration
 }
 
 func NewConfig() *Config {
@@ -48,8 +63,12 @@ func NewConfig() *Config {
 			Address: GetEnvOrPanic(constants.EnvKeys.ServerAddress),
 		},
 		Database: databaseConfig{
-			DatabaseDriver: GetEnvOrPanic(constants.EnvKeys.DBDriver),
-			DatabaseSource: GetEnvOrPanic(constants.EnvKeys.DBSource),
+			DatabaseDriver:     GetEnvOrPanic(constants.EnvKeys.DBDriver),
+			DatabaseSource:     GetEnvOrPanic(constants.EnvKeys.DBSource),
+			ReadTimeout:        GetDurationEnvOrDefault(constants.EnvKeys.DBReadTimeout, 3*time.Second),
+			WriteTimeout:       GetDurationEnvOrDefault(constants.EnvKeys.DBWriteTimeout, 3*time.Second),
+			TransactionTimeout: GetDurationEnvOrDefault(constants.EnvKeys.DBTxTimeout, 10*time.Second),
+			ExportTimeout:      GetDurationEnvOrDefault(constants.EnvKeys.DBExportTimeout, 5*time.Minute),
 		},
 		SuperAdmin: superAdminConfig{
 			Email:    GetEnvOrPanic(constants.EnvKeys.SuperAdminEmail),
@@ -58,6 +77,12 @@ func NewConfig() *Config {
 		JWT: jwtConfig{
 			Secret: GetEnvOrPanic(constants.EnvKeys.JwtSecret),
 		},
+		Accrual: accrualConfig{
+			Interval: GetDurationEnvOrDefault(constants.EnvKeys.AccrualInterval, 24*time.Hour),
+		},
+		Storage: storageConfig{
+			AttachmentDir: GetEnvOrDefault(constants.EnvKeys.AttachmentDir, "storage/attachments"),
+		},
 	}
 
 	return c
@@ -72,13 +97,36 @@ func GetEnvOrPanic(key string) string {
 	return value
 }
 
+func GetEnvOrDefault(key, fallback string) string {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+
+	return value
+}
+
+func GetDurationEnvOrDefault(key string, fallback time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		panic(fmt.Sprintf("environment variable %s is not a valid duration", key))
+	}
+
+	return duration
+}
+
 func (conf *Config) CorsNew() gin.HandlerFunc {
 	allowedOrigin := GetEnvOrPanic(constants.EnvKeys.CorsAllowedOrigin)
 
 	return cors.New(cors.Config{
-		AllowMethods:     []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodDelete},
-		AllowHeaders:     []string{constants.Headers.Origin},
-		ExposeHeaders:    []string{constants.Headers.ContentLength},
+		AllowMethods:     []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete},
+		AllowHeaders:     []string{constants.Headers.Origin, constants.Headers.IfMatch},
+		ExposeHeaders:    []string{constants.Headers.ContentLength, constants.Headers.ETag},
 		AllowCredentials: true,
 		AllowOriginFunc: func(origin string) bool {
 			return origin == allowedOrigin
@@ -11,11 +11,19 @@ var EnvKeys = envKeys{
 	SuperAdminEmail:    "SUPER_ADMIN_EMAIL",
 	SuperAdminPassword: "SUPER_ADMIN_PASSWORD",
 	JwtSecret:          "JWT_SECRET",
+	AccrualInterval:    "ACCRUAL_INTERVAL",
+	DBReadTimeout:      "DB_READ_TIMEOUT",
+	DBWriteTimeout:     "DB_WRITE_TIMEOUT",
+	DBTxTimeout:        "DB_TRANSACTION_TIMEOUT",
+	DBExportTimeout:    "DB_EXPORT_TIMEOUT",
+	AttachmentDir:      "ATTACHMENT_DIR",
 }
 
 var Headers = headers{
 	Origin:        "Origin",
 	ContentLength: "Content-Length",
+	ETag:          "ETag",
+	IfMatch:       "If-Match",
 }
 
 var MaxAge = 12 * time.Hour
@@ -29,9 +37,17 @@ type envKeys struct {
 	SuperAdminEmail    string
 	SuperAdminPassword string
 	JwtSecret          string
+	AccrualInterval    string
+	DBReadTimeout      string
+	DBWriteTimeout     string
+	DBTxTimeout        string
+	DBExportTimeout    string
+	AttachmentDir      string
 }
 
 type headers struct {
 	Origin        string
 	ContentLength string
+	ETag          string
+	IfMatch       string
 }
@@ -7,12 +7,37 @@ import (
 	"time"
 )
 
+// Querier runs statements. It is a *sql.DB, or a *sql.Tx for repositories that take part in
+// a transaction.
+type Querier interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// Timeouts bound how long a statement may run: Read for the Select methods, Write for Insert
+// and ExecuteQuery. Transaction bounds a whole transaction started by RunInTx, and Export a
+// query streamed by SelectEach.
+type Timeouts struct {
+	Read        time.Duration
+	Write       time.Duration
+	Transaction time.Duration
+	Export      time.Duration
+}
+
+var timeouts = Timeouts{Read: 3 * time.Second, Write: 3 * time.Second, Transaction: 10 * time.Second, Export: 5 * time.Minute}
+
+// SetTimeouts replaces the default timeouts. Call it once, before serving requests.
+func SetTimeouts(t Timeouts) {
+	timeouts = t
+}
+
 type BaseSQLRepository[T any] struct {
-	DB *sql.DB
+	DB Querier
 }
 
-func (repo *BaseSQLRepository[T]) SelectMultiple(mapRow func(*sql.Rows, *T) error, query string, args ...any) ([]*T, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+func (repo *BaseSQLRepository[T]) SelectMultiple(ctx context.Context, mapRow func(*sql.Rows, *T) error, query string, args ...any) ([]*T, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeouts.Read)
 	defer cancel()
 
 	rows, err := repo.DB.QueryContext(ctx, query, args...)
@@ -43,8 +68,33 @@ func (repo *BaseSQLRepository[T]) SelectMultiple(mapRow func(*sql.Rows, *T) erro
 	return list, nil
 }
 
-func (repo *BaseSQLRepository[T]) SelectSingle(mapRow func(*sql.Row, *T) error, query string, args ...any) (*T, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+// SelectEach hands the rows of query to fn one at a time instead of collecting them, for
+// results too large to hold in memory. It stops at the first error fn returns.
+func (repo *BaseSQLRepository[T]) SelectEach(ctx context.Context, mapRow func(*sql.Rows, *T) error, fn func(*T) error, query string, args ...any) error {
+	ctx, cancel := context.WithTimeout(ctx, timeouts.Export)
+	defer cancel()
+
+	rows, err := repo.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var t T
+		if err := mapRow(rows, &t); err != nil {
+			return err
+		}
+		if err := fn(&t); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+func (repo *BaseSQLRepository[T]) SelectSingle(ctx context.Context, mapRow func(*sql.Row, *T) error, query string, args ...any) (*T, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeouts.Read)
 	defer cancel()
 
 	row := repo.DB.QueryRowContext(ctx, query, args...)
@@ -59,8 +109,15 @@ func (repo *BaseSQLRepository[T]) SelectSingle(mapRow func(*sql.Row, *T) error,
 	return &t, nil
 }
 
-func (repo *BaseSQLRepository[T]) Insert(query string, args ...any) (int, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+func (repo *BaseSQLRepository[T]) SelectScalar(ctx context.Context, dest any, query string, args ...any) error {
+	ctx, cancel := context.WithTimeout(ctx, timeouts.Read)
+	defer cancel()
+
+	return repo.DB.QueryRowContext(ctx, query, args...).Scan(dest)
+}
+
+func (repo *BaseSQLRepository[T]) Insert(ctx context.Context, query string, args ...any) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeouts.Write)
 	defer cancel()
 
 	var id int
@@ -74,8 +131,8 @@ func (repo *BaseSQLRepository[T]) Insert(query string, args ...any) (int, error)
 	return id, nil
 }
 
-func (repo *BaseSQLRepository[T]) ExecuteQuery(query string, args ...any) (sql.Result, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+func (repo *BaseSQLRepository[T]) ExecuteQuery(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeouts.Write)
 	defer cancel()
 
 	result, err := repo.DB.ExecContext(ctx, query, args...)
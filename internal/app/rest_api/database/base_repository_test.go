@@ -0,0 +1,41 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+type record struct {
+	ID int
+}
+
+func mapRecord(row *sql.Row, r *record) error {
+	return row.Scan(&r.ID)
+}
+
+func TestCancelledContextStopsQuery(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating mock database: %v", err)
+	}
+	defer db.Close()
+	repo := &BaseSQLRepository[record]{DB: db}
+
+	mock.ExpectQuery(`SELECT id FROM records WHERE id = \$1`).
+		WithArgs(7).
+		WillDelayFor(time.Second).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = repo.SelectSingle(ctx, mapRecord, "SELECT id FROM records WHERE id = $1", 7)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected the query to be cancelled, got %v", err)
+	}
+}
@@ -0,0 +1,34 @@
+package entity
+
+import (
+	"time"
+)
+
+type AccrualPolicy struct {
+	ID                    int              `json:"id" db:"id"`
+	Type                  LeaveRequestType `json:"type" db:"type"`
+	DaysPerMonth          float64          `json:"daysPerMonth" db:"days_per_month"`
+	CarryOverCap          float64          `json:"carryOverCap" db:"carry_over_cap"`
+	CarryOverExpiryMonths int              `json:"carryOverExpiryMonths" db:"carry_over_expiry_months"`
+	Active                bool             `json:"active" db:"active"`
+	CreatedAt             time.Time        `json:"createdAt" db:"created_at"`
+	UpdatedAt             time.Time        `json:"updatedAt" db:"updated_at"`
+}
+
+// CarryOverExpiresAt returns when the days carried into leaveYear stop being usable,
+// or false when carried-over days never expire.
+func (p *AccrualPolicy) CarryOverExpiresAt(leaveYear int) (time.Time, bool) {
+	if p.CarryOverExpiryMonths == 0 {
+		return time.Time{}, false
+	}
+
+	return time.Date(leaveYear, time.January, 1, 0, 0, 0, 0, time.UTC).AddDate(0, p.CarryOverExpiryMonths, 0), true
+}
+
+type AccrualRunResult struct {
+	From        time.Time `json:"from"`
+	To          time.Time `json:"to"`
+	Accrued     int       `json:"accrued"`
+	CarriedOver int       `json:"carriedOver"`
+	Expired     int       `json:"expired"`
+}
@@ -0,0 +1,75 @@
+package entity
+
+import (
+	"time"
+)
+
+type ApproverKind string
+
+const (
+	// ApproverManager is decided by a manager above the employee in the reporting line.
+	ApproverManager ApproverKind = "manager"
+	// ApproverAdmin is decided by an admin acting as HR.
+	ApproverAdmin ApproverKind = "admin"
+)
+
+func (k ApproverKind) IsValid() bool {
+	switch k {
+	case ApproverManager, ApproverAdmin:
+		return true
+	}
+	return false
+}
+
+type ApprovalStepStatus string
+
+const (
+	StepPending  ApprovalStepStatus = "pending"
+	StepApproved ApprovalStepStatus = "approved"
+	StepRejected ApprovalStepStatus = "rejected"
+	StepSkipped  ApprovalStepStatus = "skipped"
+)
+
+// DefaultApprovalSteps applies to requests no approval chain is configured for.
+var DefaultApprovalSteps = []ApproverKind{ApproverManager}
+
+// ApprovalChain lists who has to approve requests of a leave type once they cost at least
+// MinWorkingDays. The chain with the highest threshold a request reaches is used.
+type ApprovalChain struct {
+	ID             int              `json:"id" db:"id"`
+	Type           LeaveRequestType `json:"type" db:"type"`
+	MinWorkingDays float64          `json:"minWorkingDays" db:"min_working_days"`
+	Steps          []ApproverKind   `json:"steps" db:"steps"`
+	CreatedAt      time.Time        `json:"createdAt" db:"created_at"`
+	UpdatedAt      time.Time        `json:"updatedAt" db:"updated_at"`
+}
+
+// ApprovalStep is one decision a submitted request is waiting for or has received.
+type ApprovalStep struct {
+	ID             int                `json:"id" db:"id"`
+	LeaveRequestId int                `json:"leaveRequestId" db:"leave_request_id"`
+	StepOrder      int                `json:"stepOrder" db:"step_order"`
+	ApproverKind   ApproverKind       `json:"approverKind" db:"approver_kind"`
+	Status         ApprovalStepStatus `json:"status" db:"status"`
+	ApproverId     *int               `json:"approverId" db:"approver_id"`
+	ApproverName   string             `json:"approverName" db:"approver_name"`
+	OnBehalfOfId   *int               `json:"onBehalfOfId" db:"on_behalf_of_id"`
+	OnBehalfOfName string             `json:"onBehalfOfName" db:"on_behalf_of_name"`
+	// AutoApprovalRuleId is set instead of ApproverId when an auto-approval rule decided the
+	// step. ApproverName then holds the name of the rule.
+	AutoApprovalRuleId *int       `json:"autoApprovalRuleId" db:"auto_approval_rule_id"`
+	Comment            string     `json:"comment" db:"comment"`
+	DecidedAt          *time.Time `json:"decidedAt" db:"decided_at"`
+	CreatedAt          time.Time  `json:"createdAt" db:"created_at"`
+}
+
+// ApprovalDelegation lets DelegateId decide in place of DelegatorId between StartDate and
+// EndDate, inclusive.
+type ApprovalDelegation struct {
+	ID          int       `json:"id" db:"id"`
+	DelegatorId int       `json:"delegatorId" db:"delegator_id"`
+	DelegateId  int       `json:"delegateId" db:"delegate_id"`
+	StartDate   time.Time `json:"startDate" db:"start_date"`
+	EndDate     time.Time `json:"endDate" db:"end_date"`
+	CreatedAt   time.Time `json:"createdAt" db:"created_at"`
+}
@@ -0,0 +1,67 @@
+package entity
+
+import (
+	"strings"
+	"time"
+)
+
+// AutoApprovalRule approves a request as soon as it is submitted when every condition it
+// sets holds. Conditions left nil or empty are not checked: LeaveType, Role and Department
+// narrow who and what the rule covers, the others bound the request itself.
+type AutoApprovalRule struct {
+	ID         int               `json:"id" db:"id"`
+	Name       string            `json:"name" db:"name"`
+	LeaveType  *LeaveRequestType `json:"leaveType" db:"leave_type"`
+	Role       *UserRole         `json:"role" db:"role"`
+	Department string            `json:"department" db:"department"`
+	// MaxWorkingDays is the longest request the rule approves.
+	MaxWorkingDays *float64 `json:"maxWorkingDays" db:"max_working_days"`
+	// MinNoticeDays is how many calendar days before its start the request must be submitted.
+	MinNoticeDays *int `json:"minNoticeDays" db:"min_notice_days"`
+	// MinBalanceAfter is the balance that must be left once the request is taken. Leave types
+	// that do not keep a balance never meet it.
+	MinBalanceAfter *float64 `json:"minBalanceAfter" db:"min_balance_after"`
+	// MaxTeamAbsent is how many teammates (users with the same manager) may already be on
+	// approved leave during the request.
+	MaxTeamAbsent *int      `json:"maxTeamAbsent" db:"max_team_absent"`
+	Active        bool      `json:"active" db:"active"`
+	CreatedAt     time.Time `json:"createdAt" db:"created_at"`
+	UpdatedAt     time.Time `json:"updatedAt" db:"updated_at"`
+}
+
+// AutoApprovalFacts describe a submitted request the way auto-approval rules look at it.
+type AutoApprovalFacts struct {
+	LeaveType   LeaveRequestType
+	Role        UserRole
+	Department  string
+	WorkingDays float64
+	NoticeDays  int
+	// BalanceAfter is nil for leave types that do not keep a balance.
+	BalanceAfter *float64
+	TeamAbsent   int
+}
+
+func (r *AutoApprovalRule) Matches(facts *AutoApprovalFacts) bool {
+	if r.LeaveType != nil && *r.LeaveType != facts.LeaveType {
+		return false
+	}
+	if r.Role != nil && *r.Role != facts.Role {
+		return false
+	}
+	if r.Department != "" && !strings.EqualFold(r.Department, facts.Department) {
+		return false
+	}
+	if r.MaxWorkingDays != nil && facts.WorkingDays > *r.MaxWorkingDays {
+		return false
+	}
+	if r.MinNoticeDays != nil && facts.NoticeDays < *r.MinNoticeDays {
+		return false
+	}
+	if r.MinBalanceAfter != nil && (facts.BalanceAfter == nil || *facts.BalanceAfter < *r.MinBalanceAfter) {
+		return false
+	}
+	if r.MaxTeamAbsent != nil && facts.TeamAbsent > *r.MaxTeamAbsent {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,13 @@
+package entity
+
+import (
+	"time"
+)
+
+// CalendarFeedToken lets calendar clients, which cannot send a bearer token, read a user's
+// iCalendar feeds. Only the SHA-256 hash of the token is stored.
+type CalendarFeedToken struct {
+	UserId    int       `json:"userId" db:"user_id"`
+	TokenHash string    `json:"-" db:"token_hash"`
+	CreatedAt time.Time `json:"createdAt" db:"created_at"`
+}
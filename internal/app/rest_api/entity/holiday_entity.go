@@ -0,0 +1,13 @@
+package entity
+
+import (
+	"time"
+)
+
+type Holiday struct {
+	ID        int       `json:"id" db:"id"`
+	Date      time.Time `json:"date" db:"holiday_date"`
+	Name      string    `json:"name" db:"name"`
+	CreatedAt time.Time `json:"createdAt" db:"created_at"`
+	UpdatedAt time.Time `json:"updatedAt" db:"updated_at"`
+}
@@ -0,0 +1,51 @@
+package entity
+
+import (
+	"time"
+)
+
+type BalanceEntryType string
+
+const (
+	BalanceCredit      BalanceEntryType = "credit"
+	BalanceDebit       BalanceEntryType = "debit"
+	BalanceReservation BalanceEntryType = "reservation"
+	BalanceAccrual     BalanceEntryType = "accrual"
+	BalanceCarryOver   BalanceEntryType = "carry_over"
+	BalanceExpiry      BalanceEntryType = "expiry"
+)
+
+func (r BalanceEntryType) IsValidEntryType() bool {
+	switch r {
+	case BalanceCredit, BalanceDebit, BalanceReservation, BalanceAccrual, BalanceCarryOver, BalanceExpiry:
+		return true
+	}
+	return false
+}
+
+type LeaveBalanceEntry struct {
+	ID             int              `json:"id" db:"id"`
+	UserId         int              `json:"userId" db:"user_id"`
+	LeaveYear      int              `json:"leaveYear" db:"leave_year"`
+	Type           LeaveRequestType `json:"type" db:"type"`
+	EntryType      BalanceEntryType `json:"entryType" db:"entry_type"`
+	Days           float64          `json:"days" db:"days"`
+	LeaveRequestId *int             `json:"leaveRequestId" db:"leave_request_id"`
+	Note           string           `json:"note" db:"note"`
+	AccrualPeriod  *time.Time       `json:"accrualPeriod" db:"accrual_period"`
+	CreatedAt      time.Time        `json:"createdAt" db:"created_at"`
+}
+
+// LeaveBalance is the aggregated view of the ledger for one user, leave year and leave type.
+type LeaveBalance struct {
+	UserId    int
+	LeaveYear int
+	Type      LeaveRequestType
+	Entitled  float64
+	Used      float64
+	Reserved  float64
+}
+
+func (b *LeaveBalance) Available() float64 {
+	return b.Entitled - b.Used - b.Reserved
+}
@@ -0,0 +1,75 @@
+package entity
+
+import (
+	"time"
+)
+
+// LeavePolicy adjusts the rules of one leave type for a group of users: those with Role (any
+// role when nil), in Department (any department when empty) and whose length of service is
+// at least MinTenureMonths and below MaxTenureMonths. It applies to leave starting between
+// EffectiveFrom and EffectiveTo. When several policies match, the one naming the most
+// criteria wins, then the one for the longest service, then the most recent one.
+type LeavePolicy struct {
+	ID              int              `json:"id" db:"id"`
+	Name            string           `json:"name" db:"name"`
+	LeaveType       LeaveRequestType `json:"leaveType" db:"leave_type"`
+	Role            *UserRole        `json:"role" db:"role"`
+	Department      string           `json:"department" db:"department"`
+	MinTenureMonths int              `json:"minTenureMonths" db:"min_tenure_months"`
+	MaxTenureMonths *int             `json:"maxTenureMonths" db:"max_tenure_months"`
+	EffectiveFrom   time.Time        `json:"effectiveFrom" db:"effective_from"`
+	EffectiveTo     *time.Time       `json:"effectiveTo" db:"effective_to"`
+	// Entitled is false for groups that may not take this leave at all.
+	Entitled bool `json:"entitled" db:"entitled"`
+	// DaysPerYear replaces the monthly accrual of the leave type's accrual policy.
+	DaysPerYear *float64 `json:"daysPerYear" db:"days_per_year"`
+	// MaxConsecutiveDays and MinNoticeDays replace those of the leave type when set.
+	MaxConsecutiveDays *float64  `json:"maxConsecutiveDays" db:"max_consecutive_days"`
+	MinNoticeDays      *int      `json:"minNoticeDays" db:"min_notice_days"`
+	CreatedAt          time.Time `json:"createdAt" db:"created_at"`
+	UpdatedAt          time.Time `json:"updatedAt" db:"updated_at"`
+}
+
+// MonthlyAccrual returns the days a user under this policy accrues each month, or false when
+// the accrual policy of the leave type decides.
+func (p *LeavePolicy) MonthlyAccrual() (float64, bool) {
+	if !p.Entitled {
+		return 0, true
+	}
+	if p.DaysPerYear == nil {
+		return 0, false
+	}
+	return *p.DaysPerYear / 12, true
+}
+
+// LeaveRules are the rules a leave request is checked against: those of its leave type,
+// adjusted by the leave policy of its owner when one applies.
+type LeaveRules struct {
+	Type   *LeaveType
+	Policy *LeavePolicy
+}
+
+func (r *LeaveRules) Entitled() bool {
+	return r.Policy == nil || r.Policy.Entitled
+}
+
+func (r *LeaveRules) MaxConsecutiveDays() *float64 {
+	if r.Policy != nil && r.Policy.MaxConsecutiveDays != nil {
+		return r.Policy.MaxConsecutiveDays
+	}
+	return r.Type.MaxConsecutiveDays
+}
+
+func (r *LeaveRules) MinNoticeDays() int {
+	if r.Policy != nil && r.Policy.MinNoticeDays != nil {
+		return *r.Policy.MinNoticeDays
+	}
+	return r.Type.MinNoticeDays
+}
+
+// ExceedsMaxConsecutiveDays reports whether a request of workingDays is longer than one
+// request may be.
+func (r *LeaveRules) ExceedsMaxConsecutiveDays(workingDays float64) bool {
+	maxDays := r.MaxConsecutiveDays()
+	return maxDays != nil && workingDays > *maxDays
+}
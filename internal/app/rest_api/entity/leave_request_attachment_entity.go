@@ -0,0 +1,18 @@
+package entity
+
+import (
+	"time"
+)
+
+// LeaveRequestAttachment is a supporting document of a leave request, such as a medical
+// certificate. The file itself is kept in the attachment storage under StorageKey.
+type LeaveRequestAttachment struct {
+	ID             int       `json:"id" db:"id"`
+	LeaveRequestId int       `json:"leaveRequestId" db:"leave_request_id"`
+	UploadedBy     int       `json:"uploadedBy" db:"uploaded_by"`
+	FileName       string    `json:"fileName" db:"file_name"`
+	ContentType    string    `json:"contentType" db:"content_type"`
+	Size           int64     `json:"size" db:"size_bytes"`
+	StorageKey     string    `json:"-" db:"storage_key"`
+	CreatedAt      time.Time `json:"createdAt" db:"created_at"`
+}
@@ -7,20 +7,24 @@ import (
 type LeaveRequestStatus string
 
 const (
-	Draft           LeaveRequestStatus = "draft"
-	WaitingApproval LeaveRequestStatus = "waiting_approval"
-	Approved        LeaveRequestStatus = "approved"
-	Rejected        LeaveRequestStatus = "rejected"
+	Draft                 LeaveRequestStatus = "draft"
+	WaitingApproval       LeaveRequestStatus = "waiting_approval"
+	Approved              LeaveRequestStatus = "approved"
+	Rejected              LeaveRequestStatus = "rejected"
+	CancellationRequested LeaveRequestStatus = "cancellation_requested"
+	Cancelled             LeaveRequestStatus = "cancelled"
 )
 
 func (r LeaveRequestStatus) IsValidStatus() bool {
 	switch r {
-	case Draft, WaitingApproval, Approved, Rejected:
+	case Draft, WaitingApproval, Approved, Rejected, CancellationRequested, Cancelled:
 		return true
 	}
 	return false
 }
 
+// LeaveRequestType is the code of a row in the leave_types table. The constants are the types
+// every installation starts with.
 type LeaveRequestType string
 
 const (
@@ -29,27 +33,186 @@ const (
 	Unpaid LeaveRequestType = "unpaid"
 )
 
-func (r LeaveRequestType) IsValidType() bool {
-	switch r {
-	case Annual, Sick, Unpaid:
-		return true
+type LeaveDurationUnit string
+
+const (
+	DurationDay  LeaveDurationUnit = "day"
+	DurationHour LeaveDurationUnit = "hour"
+)
+
+type DaySession string
+
+const (
+	SessionAM DaySession = "am"
+	SessionPM DaySession = "pm"
+)
+
+const (
+	// WorkingHoursPerDay converts hourly leave into working days.
+	WorkingHoursPerDay = 8
+	// MiddayHour splits a working day into its AM and PM sessions.
+	MiddayHour = 12
+)
+
+type LeaveRequest struct {
+	ID           int                `json:"id" db:"id"`
+	UserId       int                `json:"userId" db:"user_id"`
+	StartDate    time.Time          `json:"startDate" db:"start_date"`
+	EndDate      time.Time          `json:"endDate" db:"end_date"`
+	WorkingDays  float64            `json:"workingDays" db:"working_days"`
+	DurationUnit LeaveDurationUnit  `json:"durationUnit" db:"duration_unit"`
+	StartSession DaySession         `json:"startSession" db:"start_session"`
+	EndSession   DaySession         `json:"endSession" db:"end_session"`
+	PeriodStart  time.Time          `json:"periodStart" db:"period_start"`
+	PeriodEnd    time.Time          `json:"periodEnd" db:"period_end"`
+	Reason       string             `json:"reason" db:"reason"`
+	Type         LeaveRequestType   `json:"type" db:"type"`
+	Status       LeaveRequestStatus `json:"status" db:"status"`
+	// DecidedBy and DecidedAt record the final approval or the rejection of the request.
+	DecidedBy       *int       `json:"decidedBy" db:"decided_by"`
+	DecidedAt       *time.Time `json:"decidedAt" db:"decided_at"`
+	RejectionReason string     `json:"rejectionReason" db:"rejection_reason"`
+	ApprovalComment string     `json:"approvalComment" db:"approval_comment"`
+	// Version is bumped by every change, so a stale copy cannot overwrite a newer one.
+	Version   int       `json:"version" db:"version"`
+	CreatedAt time.Time `json:"createdAt" db:"created_at"`
+	UpdatedAt time.Time `json:"updatedAt" db:"updated_at"`
+}
+
+// SetDayPeriod sets the wall-clock bounds of a day-based request. An AM start or PM end
+// covers the whole first or last day; a PM start or AM end covers only half of it.
+func (lr *LeaveRequest) SetDayPeriod(startSession, endSession DaySession) {
+	lr.DurationUnit = DurationDay
+	lr.StartSession = startSession
+	lr.EndSession = endSession
+
+	start := time.Date(lr.StartDate.Year(), lr.StartDate.Month(), lr.StartDate.Day(), 0, 0, 0, 0, time.UTC)
+	if startSession == SessionPM {
+		start = start.Add(MiddayHour * time.Hour)
 	}
-	return false
+
+	end := time.Date(lr.EndDate.Year(), lr.EndDate.Month(), lr.EndDate.Day(), 0, 0, 0, 0, time.UTC)
+	if endSession == SessionAM {
+		end = end.Add(MiddayHour * time.Hour)
+	} else {
+		end = end.AddDate(0, 0, 1)
+	}
+
+	lr.PeriodStart = start
+	lr.PeriodEnd = end
 }
 
-type LeaveRequest struct {
-	ID        int                `json:"id" db:"id"`
-	UserId    int                `json:"userId" db:"user_id"`
-	StartDate time.Time          `json:"startDate" db:"start_date"`
-	EndDate   time.Time          `json:"endDate" db:"end_date"`
-	Reason    string             `json:"reason" db:"reason"`
-	Type      LeaveRequestType   `json:"type" db:"type"`
-	Status    LeaveRequestStatus `json:"status" db:"status"`
-	CreatedAt time.Time          `json:"createdAt" db:"created_at"`
-	UpdatedAt time.Time          `json:"updatedAt" db:"updated_at"`
+// SetHourPeriod sets the wall-clock bounds of an hourly request on its start date.
+// startTime and endTime are offsets from midnight.
+func (lr *LeaveRequest) SetHourPeriod(startTime, endTime time.Duration) {
+	lr.DurationUnit = DurationHour
+	lr.StartSession = SessionAM
+	lr.EndSession = SessionPM
+
+	day := time.Date(lr.StartDate.Year(), lr.StartDate.Month(), lr.StartDate.Day(), 0, 0, 0, 0, time.UTC)
+	lr.EndDate = lr.StartDate
+	lr.PeriodStart = day.Add(startTime)
+	lr.PeriodEnd = day.Add(endTime)
+}
+
+func (lr *LeaveRequest) Hours() float64 {
+	return lr.PeriodEnd.Sub(lr.PeriodStart).Hours()
+}
+
+// AbsencePortion is the part of a day a leave request takes off.
+type AbsencePortion string
+
+const (
+	PortionFullDay AbsencePortion = "full"
+	PortionAM      AbsencePortion = "am"
+	PortionPM      AbsencePortion = "pm"
+	PortionHours   AbsencePortion = "hours"
+)
+
+// PortionOn reports which part of day the request's period covers, or false when the period
+// does not touch the day. day is a date at midnight UTC. Hourly leave is always PortionHours.
+func (lr *LeaveRequest) PortionOn(day time.Time) (AbsencePortion, bool) {
+	dayEnd := day.AddDate(0, 0, 1)
+	if !lr.PeriodStart.Before(dayEnd) || !lr.PeriodEnd.After(day) {
+		return "", false
+	}
+
+	if lr.DurationUnit == DurationHour {
+		return PortionHours, true
+	}
+
+	midday := day.Add(MiddayHour * time.Hour)
+	switch {
+	case !lr.PeriodStart.After(day) && !lr.PeriodEnd.Before(dayEnd):
+		return PortionFullDay, true
+	case !lr.PeriodEnd.After(midday):
+		return PortionAM, true
+	case !lr.PeriodStart.Before(midday):
+		return PortionPM, true
+	}
+	return PortionHours, true
+}
+
+// Absence is a leave request shown on the team calendar, together with its owner's name.
+type Absence struct {
+	LeaveRequest
+	FullName string `json:"fullName" db:"full_name"`
+}
+
+// LeaveRequestExport is a leave request exported for payroll, with its owner's name and email.
+type LeaveRequestExport struct {
+	LeaveRequest
+	FullName string `json:"fullName" db:"full_name"`
+	Email    string `json:"email" db:"email"`
+}
+
+// LeaveYear is the ledger year a request is charged to, taken from its start date. Requests
+// are validated to start and end in the same year, so all of their days fall into it.
+func (lr *LeaveRequest) LeaveYear() int {
+	return lr.StartDate.Year()
 }
 
 type LeaveRequestFilter struct {
 	UserId string
 	Status string
+	// ManagerId limits the result to the manager's direct reports, or to everyone below
+	// them in the reporting line when IncludeIndirect is set.
+	ManagerId       int
+	IncludeIndirect bool
+	// Statuses limits the result to any of the listed statuses, on top of Status.
+	Statuses []LeaveRequestStatus
+	// From and To limit the result to requests whose period touches the dates from From to
+	// To, both inclusive. Either may be left zero.
+	From time.Time
+	To   time.Time
+}
+
+// Changes lists the fields that differ between lr and updated as {"from": ..., "to": ...}
+// pairs keyed by their JSON name.
+func (lr *LeaveRequest) Changes(updated *LeaveRequest) map[string]any {
+	fields := []struct {
+		name       string
+		from, to   any
+		hasChanged bool
+	}{
+		{"startDate", lr.StartDate, updated.StartDate, !lr.StartDate.Equal(updated.StartDate)},
+		{"endDate", lr.EndDate, updated.EndDate, !lr.EndDate.Equal(updated.EndDate)},
+		{"durationUnit", lr.DurationUnit, updated.DurationUnit, lr.DurationUnit != updated.DurationUnit},
+		{"startSession", lr.StartSession, updated.StartSession, lr.StartSession != updated.StartSession},
+		{"endSession", lr.EndSession, updated.EndSession, lr.EndSession != updated.EndSession},
+		{"periodStart", lr.PeriodStart, updated.PeriodStart, !lr.PeriodStart.Equal(updated.PeriodStart)},
+		{"periodEnd", lr.PeriodEnd, updated.PeriodEnd, !lr.PeriodEnd.Equal(updated.PeriodEnd)},
+		{"workingDays", lr.WorkingDays, updated.WorkingDays, lr.WorkingDays != updated.WorkingDays},
+		{"type", lr.Type, updated.Type, lr.Type != updated.Type},
+		{"reason", lr.Reason, updated.Reason, lr.Reason != updated.Reason},
+	}
+
+	changes := make(map[string]any)
+	for _, field := range fields {
+		if field.hasChanged {
+			changes[field.name] = map[string]any{"from": field.from, "to": field.to}
+		}
+	}
+
+	return changes
 }
@@ -0,0 +1,36 @@
+package entity
+
+import (
+	"time"
+)
+
+type LeaveRequestAction string
+
+const (
+	ActionCreate              LeaveRequestAction = "create"
+	ActionEdit                LeaveRequestAction = "edit"
+	ActionSubmit              LeaveRequestAction = "submit"
+	ActionApprove             LeaveRequestAction = "approve"
+	ActionReject              LeaveRequestAction = "reject"
+	ActionCancel              LeaveRequestAction = "cancel"
+	ActionRequestCancellation LeaveRequestAction = "request_cancellation"
+	ActionRejectCancellation  LeaveRequestAction = "reject_cancellation"
+	ActionDelete              LeaveRequestAction = "delete"
+)
+
+// LeaveRequestEvent is one entry in the history of a leave request. PreviousStatus is empty
+// for the creation of the request and NewStatus for its deletion.
+type LeaveRequestEvent struct {
+	ID             int  `json:"id" db:"id"`
+	LeaveRequestId int  `json:"leaveRequestId" db:"leave_request_id"`
+	ActorId        *int `json:"actorId" db:"actor_id"`
+	// AutoApprovalRuleId is set instead of ActorId when an auto-approval rule took the
+	// decision. ActorName then holds the name of the rule.
+	AutoApprovalRuleId *int               `json:"autoApprovalRuleId" db:"auto_approval_rule_id"`
+	ActorName          string             `json:"actorName" db:"actor_name"`
+	Action             LeaveRequestAction `json:"action" db:"action"`
+	PreviousStatus     LeaveRequestStatus `json:"previousStatus" db:"previous_status"`
+	NewStatus          LeaveRequestStatus `json:"newStatus" db:"new_status"`
+	Payload            map[string]any     `json:"payload" db:"payload"`
+	CreatedAt          time.Time          `json:"createdAt" db:"created_at"`
+}
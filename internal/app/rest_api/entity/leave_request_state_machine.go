@@ -0,0 +1,98 @@
+package entity
+
+// LeaveRequestTransition is something a user can do with an existing leave request.
+type LeaveRequestTransition string
+
+const (
+	TransitionEdit                LeaveRequestTransition = "edit"
+	TransitionDelete              LeaveRequestTransition = "delete"
+	TransitionSubmit              LeaveRequestTransition = "submit"
+	TransitionCancel              LeaveRequestTransition = "cancel"
+	TransitionApprove             LeaveRequestTransition = "approve"
+	TransitionReject              LeaveRequestTransition = "reject"
+	TransitionApproveCancellation LeaveRequestTransition = "approve_cancellation"
+	TransitionRejectCancellation  LeaveRequestTransition = "reject_cancellation"
+)
+
+// TransitionActor is who may trigger a transition.
+type TransitionActor string
+
+const (
+	// ActorOwner is the employee the request belongs to.
+	ActorOwner TransitionActor = "owner"
+	// ActorApprover is anyone entitled to decide on the request, never its owner.
+	ActorApprover TransitionActor = "approver"
+)
+
+// TransitionEffect is a side effect a transition has on the approval chain and the leave
+// balance ledger, applied after the new status is stored.
+type TransitionEffect string
+
+const (
+	// EffectResetApproval deletes the approval chain and releases the reservation.
+	EffectResetApproval TransitionEffect = "reset_approval"
+	// EffectStartApproval reserves the days and records the approval chain.
+	EffectStartApproval TransitionEffect = "start_approval"
+	// EffectEndApproval skips the remaining steps and releases the reservation.
+	EffectEndApproval TransitionEffect = "end_approval"
+	// EffectConsumeBalance turns the reservation into a debit.
+	EffectConsumeBalance TransitionEffect = "consume_balance"
+	// EffectRefundBalance gives the debited days back.
+	EffectRefundBalance TransitionEffect = "refund_balance"
+)
+
+// TransitionRule allows a transition to move a request from one status to another. Effects
+// are applied in order. A delete has no To status: the request is gone.
+type TransitionRule struct {
+	Transition LeaveRequestTransition
+	From       LeaveRequestStatus
+	To         LeaveRequestStatus
+	Actor      TransitionActor
+	Effects    []TransitionEffect
+}
+
+// LeaveRequestTransitions is the leave request state machine. A transition that is not
+// listed for the current status is not allowed.
+var LeaveRequestTransitions = []TransitionRule{
+	{Transition: TransitionEdit, From: Draft, To: Draft, Actor: ActorOwner},
+	{Transition: TransitionEdit, From: Draft, To: WaitingApproval, Actor: ActorOwner, Effects: []TransitionEffect{EffectStartApproval}},
+	{Transition: TransitionEdit, From: WaitingApproval, To: Draft, Actor: ActorOwner, Effects: []TransitionEffect{EffectResetApproval}},
+	{Transition: TransitionEdit, From: WaitingApproval, To: WaitingApproval, Actor: ActorOwner, Effects: []TransitionEffect{EffectResetApproval, EffectStartApproval}},
+	{Transition: TransitionDelete, From: Draft, Actor: ActorOwner},
+	{Transition: TransitionDelete, From: WaitingApproval, Actor: ActorOwner, Effects: []TransitionEffect{EffectResetApproval}},
+	{Transition: TransitionSubmit, From: Draft, To: WaitingApproval, Actor: ActorOwner, Effects: []TransitionEffect{EffectStartApproval}},
+	{Transition: TransitionCancel, From: Draft, To: Cancelled, Actor: ActorOwner},
+	{Transition: TransitionCancel, From: WaitingApproval, To: Cancelled, Actor: ActorOwner, Effects: []TransitionEffect{EffectEndApproval}},
+	{Transition: TransitionCancel, From: Approved, To: CancellationRequested, Actor: ActorOwner},
+	{Transition: TransitionApprove, From: WaitingApproval, To: Approved, Actor: ActorApprover, Effects: []TransitionEffect{EffectConsumeBalance}},
+	{Transition: TransitionReject, From: WaitingApproval, To: Rejected, Actor: ActorApprover, Effects: []TransitionEffect{EffectEndApproval}},
+	{Transition: TransitionApproveCancellation, From: CancellationRequested, To: Cancelled, Actor: ActorApprover, Effects: []TransitionEffect{EffectRefundBalance}},
+	{Transition: TransitionRejectCancellation, From: CancellationRequested, To: Approved, Actor: ActorApprover},
+}
+
+// FindTransitionRule returns the rule that lets the transition move a request out of status
+// from. Edits can end in more than one status, so to picks among them; an empty to takes the
+// first rule that matches.
+func FindTransitionRule(transition LeaveRequestTransition, from, to LeaveRequestStatus) (*TransitionRule, bool) {
+	for i, rule := range LeaveRequestTransitions {
+		if rule.Transition == transition && rule.From == from && (to == "" || rule.To == to) {
+			return &LeaveRequestTransitions[i], true
+		}
+	}
+	return nil, false
+}
+
+// AvailableTransitions lists the transitions a request in the given status allows, in the
+// order of the state machine and without duplicates.
+func AvailableTransitions(from LeaveRequestStatus) []TransitionRule {
+	var rules []TransitionRule
+	seen := map[LeaveRequestTransition]bool{}
+	for _, rule := range LeaveRequestTransitions {
+		if rule.From != from || seen[rule.Transition] {
+			continue
+		}
+		seen[rule.Transition] = true
+		rules = append(rules, rule)
+	}
+	return rules
+}
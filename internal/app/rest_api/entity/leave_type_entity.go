@@ -0,0 +1,45 @@
+package entity
+
+import (
+	"time"
+)
+
+// LeaveType holds the rules an administrator has set for one kind of leave. Requests refer
+// to it by its code.
+type LeaveType struct {
+	Code           LeaveRequestType `json:"code" db:"code"`
+	Name           string           `json:"name" db:"name"`
+	Paid           bool             `json:"paid" db:"paid"`
+	DeductsBalance bool             `json:"deductsBalance" db:"deducts_balance"`
+	// RequiresAttachment asks for a supporting document once a request is longer than
+	// AttachmentAfterDays working days.
+	RequiresAttachment  bool    `json:"requiresAttachment" db:"requires_attachment"`
+	AttachmentAfterDays float64 `json:"attachmentAfterDays" db:"attachment_after_days"`
+	// MaxConsecutiveDays caps the working days of one request. Nil means no cap.
+	MaxConsecutiveDays *float64 `json:"maxConsecutiveDays" db:"max_consecutive_days"`
+	MinNoticeDays      int      `json:"minNoticeDays" db:"min_notice_days"`
+	// AllowedRoles limits who may request this type. Empty means every role.
+	AllowedRoles []UserRole `json:"allowedRoles" db:"allowed_roles"`
+	Active       bool       `json:"active" db:"active"`
+	CreatedAt    time.Time  `json:"createdAt" db:"created_at"`
+	UpdatedAt    time.Time  `json:"updatedAt" db:"updated_at"`
+}
+
+func (t *LeaveType) AllowsRole(role UserRole) bool {
+	if len(t.AllowedRoles) == 0 {
+		return true
+	}
+
+	for _, allowed := range t.AllowedRoles {
+		if allowed == role {
+			return true
+		}
+	}
+	return false
+}
+
+// RequiresAttachmentFor reports whether a request of workingDays needs a supporting document
+// before it can be submitted.
+func (t *LeaveType) RequiresAttachmentFor(workingDays float64) bool {
+	return t.RequiresAttachment && workingDays > t.AttachmentAfterDays
+}
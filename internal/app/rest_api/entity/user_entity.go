@@ -12,6 +12,12 @@ const (
 	RoleEmployee   UserRole = "employee"
 )
 
+// IsAdmin reports whether the role may manage users and decide on leave of employees
+// without a manager.
+func (r UserRole) IsAdmin() bool {
+	return r == RoleSuperAdmin || r == RoleAdmin
+}
+
 func (r UserRole) IsValid() bool {
 	switch r {
 	case RoleSuperAdmin, RoleAdmin, RoleEmployee:
@@ -21,10 +27,15 @@ func (r UserRole) IsValid() bool {
 }
 
 type User struct {
-	ID        int       `json:"id" db:"id"`
-	FullName  string    `json:"fullName" db:"full_name"`
-	Email     string    `json:"email" db:"email"`
-	Role      UserRole  `json:"role" db:"role"`
+	ID        int      `json:"id" db:"id"`
+	FullName  string   `json:"fullName" db:"full_name"`
+	Email     string   `json:"email" db:"email"`
+	Role      UserRole `json:"role" db:"role"`
+	ManagerId *int     `json:"managerId" db:"manager_id"`
+	// Department is empty for users who do not belong to one.
+	Department string `json:"department" db:"department"`
+	// HireDate is when the user joined; leave policies measure length of service from it.
+	HireDate  time.Time `json:"hireDate" db:"hire_date"`
 	Password  string    `json:"-" db:"password"`
 	CreatedAt time.Time `json:"createdAt" db:"created_at"`
 	UpdatedAt time.Time `json:"updatedAt" db:"updated_at"`
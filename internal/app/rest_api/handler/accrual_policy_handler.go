@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	dto "github.com/devonLoen/leave-request-service/internal/app/rest_api/model/dto"
+	"github.com/devonLoen/leave-request-service/internal/app/rest_api/pkg/util"
+	"github.com/devonLoen/leave-request-service/internal/app/rest_api/usecase"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type AccrualPolicy struct {
+	accrualUsecase usecase.AccrualUsecase
+}
+
+func NewAccrualPolicyHandler(accrualUsecase usecase.AccrualUsecase) *AccrualPolicy {
+	return &AccrualPolicy{accrualUsecase: accrualUsecase}
+}
+
+func (h *AccrualPolicy) GetAccrualPolicies(ctx *gin.Context) {
+	policies, err := h.accrualUsecase.GetPolicies(ctx.Request.Context())
+	if err != nil {
+		ctx.AbortWithStatusJSON(err.Code, err)
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, policies)
+}
+
+func (h *AccrualPolicy) UpsertAccrualPolicy(ctx *gin.Context) {
+	var upsertAccrualPolicyRequest dto.UpsertAccrualPolicyRequest
+
+	if err := util.StrictBindJSON(ctx, &upsertAccrualPolicyRequest); err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := validator.New().Struct(upsertAccrualPolicyRequest); err != nil {
+		var ve validator.ValidationErrors
+		if errors.As(err, &ve) {
+			out := make(map[string]string)
+			for _, fe := range ve {
+				out[fe.Field()] = util.MsgForTag(fe)
+			}
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"errors": out})
+			return
+		}
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	policy, upsertError := h.accrualUsecase.UpsertPolicy(ctx.Request.Context(), ctx.Param("type"), &upsertAccrualPolicyRequest)
+	if upsertError != nil {
+		ctx.AbortWithStatusJSON(upsertError.Code, upsertError)
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, policy)
+}
@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	dto "github.com/devonLoen/leave-request-service/internal/app/rest_api/model/dto"
+	"github.com/devonLoen/leave-request-service/internal/app/rest_api/pkg/util"
+	"github.com/devonLoen/leave-request-service/internal/app/rest_api/usecase"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type ApprovalChain struct {
+	approvalChainUsecase usecase.ApprovalChainUsecase
+}
+
+func NewApprovalChainHandler(approvalChainUsecase usecase.ApprovalChainUsecase) *ApprovalChain {
+	return &ApprovalChain{approvalChainUsecase: approvalChainUsecase}
+}
+
+func (h *ApprovalChain) GetApprovalChains(ctx *gin.Context) {
+	chains, err := h.approvalChainUsecase.GetApprovalChains(ctx.Request.Context())
+	if err != nil {
+		ctx.AbortWithStatusJSON(err.Code, err)
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, chains)
+}
+
+func (h *ApprovalChain) UpsertApprovalChain(ctx *gin.Context) {
+	var upsertApprovalChainRequest dto.UpsertApprovalChainRequest
+
+	if err := util.StrictBindJSON(ctx, &upsertApprovalChainRequest); err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := validator.New().Struct(upsertApprovalChainRequest); err != nil {
+		var ve validator.ValidationErrors
+		if errors.As(err, &ve) {
+			out := make(map[string]string)
+			for _, fe := range ve {
+				out[fe.Field()] = util.MsgForTag(fe)
+			}
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"errors": out})
+			return
+		}
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	chain, upsertError := h.approvalChainUsecase.UpsertApprovalChain(ctx.Request.Context(), &upsertApprovalChainRequest)
+	if upsertError != nil {
+		ctx.AbortWithStatusJSON(upsertError.Code, upsertError)
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, chain)
+}
+
+func (h *ApprovalChain) DeleteApprovalChain(ctx *gin.Context) {
+	approvalChainID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Approval Chain ID not valid"})
+
+		return
+	}
+
+	deleteError := h.approvalChainUsecase.DeleteApprovalChain(ctx.Request.Context(), approvalChainID)
+	if deleteError != nil {
+		ctx.AbortWithStatusJSON(deleteError.Code, deleteError)
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Approval Chain Deleted"})
+}
@@ -37,7 +37,7 @@ func (h *Auth) Login(ctx *gin.Context) {
 		return
 	}
 
-	loginResponse, loginError := h.AuthService.Login(&loginRequest)
+	loginResponse, loginError := h.AuthService.Login(ctx.Request.Context(), &loginRequest)
 	if loginError != nil {
 		ctx.AbortWithStatusJSON(loginError.Code, loginError)
 		return
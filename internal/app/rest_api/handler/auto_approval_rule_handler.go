@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	dto "github.com/devonLoen/leave-request-service/internal/app/rest_api/model/dto"
+	"github.com/devonLoen/leave-request-service/internal/app/rest_api/pkg/util"
+	"github.com/devonLoen/leave-request-service/internal/app/rest_api/usecase"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type AutoApprovalRule struct {
+	autoApprovalRuleUsecase usecase.AutoApprovalRuleUsecase
+}
+
+func NewAutoApprovalRuleHandler(autoApprovalRuleUsecase usecase.AutoApprovalRuleUsecase) *AutoApprovalRule {
+	return &AutoApprovalRule{autoApprovalRuleUsecase: autoApprovalRuleUsecase}
+}
+
+func (h *AutoApprovalRule) GetAutoApprovalRules(ctx *gin.Context) {
+	rules, err := h.autoApprovalRuleUsecase.GetAutoApprovalRules(ctx.Request.Context())
+	if err != nil {
+		ctx.AbortWithStatusJSON(err.Code, err)
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, rules)
+}
+
+func (h *AutoApprovalRule) CreateAutoApprovalRule(ctx *gin.Context) {
+	var autoApprovalRuleRequest dto.AutoApprovalRuleRequest
+
+	if !bindAutoApprovalRuleRequest(ctx, &autoApprovalRuleRequest) {
+		return
+	}
+
+	rule, createError := h.autoApprovalRuleUsecase.CreateAutoApprovalRule(ctx.Request.Context(), &autoApprovalRuleRequest)
+	if createError != nil {
+		ctx.AbortWithStatusJSON(createError.Code, createError)
+
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, rule)
+}
+
+func (h *AutoApprovalRule) UpdateAutoApprovalRule(ctx *gin.Context) {
+	var autoApprovalRuleRequest dto.AutoApprovalRuleRequest
+
+	autoApprovalRuleID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Auto-approval rule ID not valid"})
+
+		return
+	}
+
+	if !bindAutoApprovalRuleRequest(ctx, &autoApprovalRuleRequest) {
+		return
+	}
+
+	rule, updateError := h.autoApprovalRuleUsecase.UpdateAutoApprovalRule(ctx.Request.Context(), autoApprovalRuleID, &autoApprovalRuleRequest)
+	if updateError != nil {
+		ctx.AbortWithStatusJSON(updateError.Code, updateError)
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, rule)
+}
+
+func (h *AutoApprovalRule) DeleteAutoApprovalRule(ctx *gin.Context) {
+	autoApprovalRuleID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Auto-approval rule ID not valid"})
+
+		return
+	}
+
+	deleteError := h.autoApprovalRuleUsecase.DeleteAutoApprovalRule(ctx.Request.Context(), autoApprovalRuleID)
+	if deleteError != nil {
+		ctx.AbortWithStatusJSON(deleteError.Code, deleteError)
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Auto-Approval Rule Deleted"})
+}
+
+func bindAutoApprovalRuleRequest(ctx *gin.Context, autoApprovalRuleRequest *dto.AutoApprovalRuleRequest) bool {
+	if err := util.StrictBindJSON(ctx, autoApprovalRuleRequest); err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return false
+	}
+
+	if err := validator.New().Struct(autoApprovalRuleRequest); err != nil {
+		var ve validator.ValidationErrors
+		if errors.As(err, &ve) {
+			out := make(map[string]string)
+			for _, fe := range ve {
+				out[fe.Field()] = util.MsgForTag(fe)
+			}
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"errors": out})
+			return false
+		}
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return false
+	}
+
+	return true
+}
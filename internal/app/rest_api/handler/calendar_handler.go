@@ -0,0 +1,179 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	entity "github.com/devonLoen/leave-request-service/internal/app/rest_api/entity"
+	"github.com/devonLoen/leave-request-service/internal/app/rest_api/pkg/util"
+	"github.com/devonLoen/leave-request-service/internal/app/rest_api/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+// defaultCalendarDays is how many days the calendar shows when to is left out.
+const defaultCalendarDays = 28
+
+const iCalendarContentType = "text/calendar; charset=utf-8"
+
+type Calendar struct {
+	calendarUsecase usecase.CalendarUsecase
+}
+
+func NewCalendarHandler(calendarUsecase usecase.CalendarUsecase) *Calendar {
+	return &Calendar{calendarUsecase: calendarUsecase}
+}
+
+func (h *Calendar) GetCalendar(ctx *gin.Context) {
+	userIDRaw, _ := ctx.Get("userId")
+	userID := userIDRaw.(int)
+
+	from, errParse := queryDate(ctx, "from")
+	if errParse != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "from not valid"})
+
+		return
+	}
+	if from.IsZero() {
+		from = util.DateOnly(time.Now())
+	}
+
+	to, errParse := queryDate(ctx, "to")
+	if errParse != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "to not valid"})
+
+		return
+	}
+	if to.IsZero() {
+		to = from.AddDate(0, 0, defaultCalendarDays-1)
+	}
+
+	team, errConv := strconv.Atoi(ctx.DefaultQuery("team", strconv.Itoa(userID)))
+	if errConv != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "team not valid"})
+
+		return
+	}
+
+	includeIndirect, errConv := strconv.ParseBool(ctx.DefaultQuery("indirect", "false"))
+	if errConv != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "indirect not valid"})
+
+		return
+	}
+
+	includePending, errConv := strconv.ParseBool(ctx.DefaultQuery("pending", "false"))
+	if errConv != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "pending not valid"})
+
+		return
+	}
+
+	filter := entity.LeaveRequestFilter{
+		ManagerId:       team,
+		IncludeIndirect: includeIndirect,
+		From:            from,
+		To:              to,
+	}
+
+	calendar, err := h.calendarUsecase.GetCalendar(ctx.Request.Context(), userID, filter, includePending)
+	if err != nil {
+		ctx.AbortWithStatusJSON(err.Code, err)
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, calendar)
+}
+
+func (h *Calendar) CreateFeedToken(ctx *gin.Context) {
+	userIDRaw, _ := ctx.Get("userId")
+	userID := userIDRaw.(int)
+
+	feedToken, err := h.calendarUsecase.CreateFeedToken(ctx.Request.Context(), userID)
+	if err != nil {
+		ctx.AbortWithStatusJSON(err.Code, err)
+
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, feedToken)
+}
+
+func (h *Calendar) RevokeFeedToken(ctx *gin.Context) {
+	userIDRaw, _ := ctx.Get("userId")
+	userID := userIDRaw.(int)
+
+	err := h.calendarUsecase.RevokeFeedToken(ctx.Request.Context(), userID)
+	if err != nil {
+		ctx.AbortWithStatusJSON(err.Code, err)
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Calendar feed token revoked"})
+}
+
+// GetUserFeed and GetTeamFeed are reached without a bearer token: the feed token in the path
+// authenticates the calendar client.
+func (h *Calendar) GetUserFeed(ctx *gin.Context) {
+	feed, err := h.calendarUsecase.GetUserFeed(ctx.Request.Context(), ctx.Param("token"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(err.Code, err)
+
+		return
+	}
+
+	ctx.Data(http.StatusOK, iCalendarContentType, feed)
+}
+
+func (h *Calendar) GetTeamFeed(ctx *gin.Context) {
+	includeIndirect, errConv := strconv.ParseBool(ctx.DefaultQuery("indirect", "false"))
+	if errConv != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "indirect not valid"})
+
+		return
+	}
+
+	feed, err := h.calendarUsecase.GetTeamFeed(ctx.Request.Context(), ctx.Param("token"), includeIndirect)
+	if err != nil {
+		ctx.AbortWithStatusJSON(err.Code, err)
+
+		return
+	}
+
+	ctx.Data(http.StatusOK, iCalendarContentType, feed)
+}
+
+func (h *Calendar) DownloadLeaveRequest(ctx *gin.Context) {
+	userIDRaw, _ := ctx.Get("userId")
+	userID := userIDRaw.(int)
+
+	leaveRequestID, errConv := strconv.Atoi(ctx.Param("id"))
+	if errConv != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Leave Request ID not valid"})
+
+		return
+	}
+
+	ics, err := h.calendarUsecase.GetLeaveRequestICal(ctx.Request.Context(), leaveRequestID, userID)
+	if err != nil {
+		ctx.AbortWithStatusJSON(err.Code, err)
+
+		return
+	}
+
+	ctx.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="leave-request-%d.ics"`, leaveRequestID))
+	ctx.Data(http.StatusOK, iCalendarContentType, ics)
+}
+
+// queryDate parses the YYYY-MM-DD query parameter param, returning the zero time when it is
+// not set.
+func queryDate(ctx *gin.Context, param string) (time.Time, error) {
+	value := ctx.Query(param)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.DateOnly, value)
+}
@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	dto "github.com/devonLoen/leave-request-service/internal/app/rest_api/model/dto"
+	"github.com/devonLoen/leave-request-service/internal/app/rest_api/pkg/util"
+	"github.com/devonLoen/leave-request-service/internal/app/rest_api/usecase"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type Delegation struct {
+	delegationUsecase usecase.DelegationUsecase
+}
+
+func NewDelegationHandler(delegationUsecase usecase.DelegationUsecase) *Delegation {
+	return &Delegation{delegationUsecase: delegationUsecase}
+}
+
+func (h *Delegation) GetMyDelegations(ctx *gin.Context) {
+	userIDRaw, _ := ctx.Get("userId")
+	userID := userIDRaw.(int)
+
+	delegations, err := h.delegationUsecase.GetMyDelegations(ctx.Request.Context(), userID)
+	if err != nil {
+		ctx.AbortWithStatusJSON(err.Code, err)
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, delegations)
+}
+
+func (h *Delegation) CreateDelegation(ctx *gin.Context) {
+	var createDelegationRequest dto.CreateDelegationRequest
+	userIDRaw, _ := ctx.Get("userId")
+	userID := userIDRaw.(int)
+
+	if err := util.StrictBindJSON(ctx, &createDelegationRequest); err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := validator.New().Struct(createDelegationRequest); err != nil {
+		var ve validator.ValidationErrors
+		if errors.As(err, &ve) {
+			out := make(map[string]string)
+			for _, fe := range ve {
+				out[fe.Field()] = util.MsgForTag(fe)
+			}
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"errors": out})
+			return
+		}
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	delegation, createError := h.delegationUsecase.CreateDelegation(ctx.Request.Context(), userID, &createDelegationRequest)
+	if createError != nil {
+		ctx.AbortWithStatusJSON(createError.Code, createError)
+
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, delegation)
+}
+
+func (h *Delegation) DeleteDelegation(ctx *gin.Context) {
+	delegationID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Delegation ID not valid"})
+
+		return
+	}
+
+	userIDRaw, _ := ctx.Get("userId")
+	userID := userIDRaw.(int)
+
+	deleteError := h.delegationUsecase.DeleteDelegation(ctx.Request.Context(), delegationID, userID)
+	if deleteError != nil {
+		ctx.AbortWithStatusJSON(deleteError.Code, deleteError)
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Delegation Deleted"})
+}
@@ -0,0 +1,152 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	dto "github.com/devonLoen/leave-request-service/internal/app/rest_api/model/dto"
+	"github.com/devonLoen/leave-request-service/internal/app/rest_api/pkg/util"
+	"github.com/devonLoen/leave-request-service/internal/app/rest_api/usecase"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+const maxHolidayImportSize = 1 << 20
+
+type Holiday struct {
+	holidayUsecase usecase.HolidayUsecase
+}
+
+func NewHolidayHandler(holidayUsecase usecase.HolidayUsecase) *Holiday {
+	return &Holiday{holidayUsecase: holidayUsecase}
+}
+
+func (h *Holiday) GetHolidays(ctx *gin.Context) {
+	year, errConv := strconv.Atoi(ctx.DefaultQuery("year", strconv.Itoa(time.Now().Year())))
+	if errConv != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "year not valid"})
+
+		return
+	}
+
+	holidays, err := h.holidayUsecase.GetHolidays(ctx.Request.Context(), year)
+	if err != nil {
+		ctx.AbortWithStatusJSON(err.Code, err)
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, holidays)
+}
+
+func (h *Holiday) CreateHoliday(ctx *gin.Context) {
+	var holidayRequest dto.HolidayRequest
+
+	if !bindHolidayRequest(ctx, &holidayRequest) {
+		return
+	}
+
+	holiday, createError := h.holidayUsecase.CreateHoliday(ctx.Request.Context(), &holidayRequest)
+	if createError != nil {
+		ctx.AbortWithStatusJSON(createError.Code, createError)
+
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, holiday)
+}
+
+func (h *Holiday) UpdateHoliday(ctx *gin.Context) {
+	var holidayRequest dto.HolidayRequest
+
+	holidayID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Holiday ID not valid"})
+
+		return
+	}
+
+	if !bindHolidayRequest(ctx, &holidayRequest) {
+		return
+	}
+
+	holiday, updateError := h.holidayUsecase.UpdateHoliday(ctx.Request.Context(), holidayID, &holidayRequest)
+	if updateError != nil {
+		ctx.AbortWithStatusJSON(updateError.Code, updateError)
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, holiday)
+}
+
+func (h *Holiday) DeleteHoliday(ctx *gin.Context) {
+	holidayID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Holiday ID not valid"})
+
+		return
+	}
+
+	deleteError := h.holidayUsecase.DeleteHoliday(ctx.Request.Context(), holidayID)
+	if deleteError != nil {
+		ctx.AbortWithStatusJSON(deleteError.Code, deleteError)
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Holiday Deleted"})
+}
+
+func (h *Holiday) ImportHolidays(ctx *gin.Context) {
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxHolidayImportSize)
+
+	fileHeader, err := ctx.FormFile("file")
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "An .ics file is required in the 'file' field"})
+
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Unable to read uploaded file"})
+
+		return
+	}
+	defer file.Close()
+
+	importResponse, importError := h.holidayUsecase.ImportHolidays(ctx.Request.Context(), file)
+	if importError != nil {
+		ctx.AbortWithStatusJSON(importError.Code, importError)
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, importResponse)
+}
+
+func bindHolidayRequest(ctx *gin.Context, holidayRequest *dto.HolidayRequest) bool {
+	if err := util.StrictBindJSON(ctx, holidayRequest); err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return false
+	}
+
+	if err := validator.New().Struct(holidayRequest); err != nil {
+		var ve validator.ValidationErrors
+		if errors.As(err, &ve) {
+			out := make(map[string]string)
+			for _, fe := range ve {
+				out[fe.Field()] = util.MsgForTag(fe)
+			}
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"errors": out})
+			return false
+		}
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	dto "github.com/devonLoen/leave-request-service/internal/app/rest_api/model/dto"
+	"github.com/devonLoen/leave-request-service/internal/app/rest_api/pkg/util"
+	"github.com/devonLoen/leave-request-service/internal/app/rest_api/usecase"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type LeaveBalance struct {
+	leaveBalanceUsecase usecase.LeaveBalanceUsecase
+}
+
+func NewLeaveBalanceHandler(leaveBalanceUsecase usecase.LeaveBalanceUsecase) *LeaveBalance {
+	return &LeaveBalance{leaveBalanceUsecase: leaveBalanceUsecase}
+}
+
+func (h *LeaveBalance) GetMyBalances(ctx *gin.Context) {
+	userIDRaw, _ := ctx.Get("userId")
+	userID := userIDRaw.(int)
+
+	leaveYear, errConv := strconv.Atoi(ctx.DefaultQuery("year", strconv.Itoa(time.Now().Year())))
+	if errConv != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "year not valid"})
+
+		return
+	}
+
+	balances, err := h.leaveBalanceUsecase.GetBalances(ctx.Request.Context(), userID, leaveYear)
+	if err != nil {
+		ctx.AbortWithStatusJSON(err.Code, err)
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, balances)
+}
+
+func (h *LeaveBalance) GetUserBalances(ctx *gin.Context) {
+	userID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "User ID not valid"})
+
+		return
+	}
+
+	leaveYear, errConv := strconv.Atoi(ctx.DefaultQuery("year", strconv.Itoa(time.Now().Year())))
+	if errConv != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "year not valid"})
+
+		return
+	}
+
+	balances, balancesErr := h.leaveBalanceUsecase.GetBalances(ctx.Request.Context(), userID, leaveYear)
+	if balancesErr != nil {
+		ctx.AbortWithStatusJSON(balancesErr.Code, balancesErr)
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, balances)
+}
+
+func (h *LeaveBalance) CreateBalanceEntry(ctx *gin.Context) {
+	var createBalanceEntryRequest dto.CreateBalanceEntryRequest
+
+	userID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "User ID not valid"})
+
+		return
+	}
+
+	if err := util.StrictBindJSON(ctx, &createBalanceEntryRequest); err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := validator.New().Struct(createBalanceEntryRequest); err != nil {
+		var ve validator.ValidationErrors
+		if errors.As(err, &ve) {
+			out := make(map[string]string)
+			for _, fe := range ve {
+				out[fe.Field()] = util.MsgForTag(fe)
+			}
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"errors": out})
+			return
+		}
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	createBalanceEntryResponse, createError := h.leaveBalanceUsecase.CreateBalanceEntry(ctx.Request.Context(), userID, &createBalanceEntryRequest)
+	if createError != nil {
+		ctx.AbortWithStatusJSON(createError.Code, createError)
+
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, createBalanceEntryResponse)
+}
@@ -0,0 +1,119 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	dto "github.com/devonLoen/leave-request-service/internal/app/rest_api/model/dto"
+	"github.com/devonLoen/leave-request-service/internal/app/rest_api/pkg/util"
+	"github.com/devonLoen/leave-request-service/internal/app/rest_api/usecase"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type LeavePolicy struct {
+	leavePolicyUsecase usecase.LeavePolicyUsecase
+}
+
+func NewLeavePolicyHandler(leavePolicyUsecase usecase.LeavePolicyUsecase) *LeavePolicy {
+	return &LeavePolicy{leavePolicyUsecase: leavePolicyUsecase}
+}
+
+func (h *LeavePolicy) GetLeavePolicies(ctx *gin.Context) {
+	policies, err := h.leavePolicyUsecase.GetLeavePolicies(ctx.Request.Context())
+	if err != nil {
+		ctx.AbortWithStatusJSON(err.Code, err)
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, policies)
+}
+
+func (h *LeavePolicy) CreateLeavePolicy(ctx *gin.Context) {
+	var leavePolicyRequest dto.LeavePolicyRequest
+
+	if !bindLeavePolicyRequest(ctx, &leavePolicyRequest) {
+		return
+	}
+
+	policy, createError := h.leavePolicyUsecase.CreateLeavePolicy(ctx.Request.Context(), &leavePolicyRequest)
+	if createError != nil {
+		ctx.AbortWithStatusJSON(createError.Code, createError)
+
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, policy)
+}
+
+func (h *LeavePolicy) UpdateLeavePolicy(ctx *gin.Context) {
+	var leavePolicyRequest dto.LeavePolicyRequest
+
+	leavePolicyID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Leave policy ID not valid"})
+
+		return
+	}
+
+	if !bindLeavePolicyRequest(ctx, &leavePolicyRequest) {
+		return
+	}
+
+	policy, updateError := h.leavePolicyUsecase.UpdateLeavePolicy(ctx.Request.Context(), leavePolicyID, &leavePolicyRequest)
+	if updateError != nil {
+		ctx.AbortWithStatusJSON(updateError.Code, updateError)
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, policy)
+}
+
+func (h *LeavePolicy) DeleteLeavePolicy(ctx *gin.Context) {
+	leavePolicyID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Leave policy ID not valid"})
+
+		return
+	}
+
+	deleteError := h.leavePolicyUsecase.DeleteLeavePolicy(ctx.Request.Context(), leavePolicyID)
+	if deleteError != nil {
+		ctx.AbortWithStatusJSON(deleteError.Code, deleteError)
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Leave Policy Deleted"})
+}
+
+func bindLeavePolicyRequest(ctx *gin.Context, leavePolicyRequest *dto.LeavePolicyRequest) bool {
+	if err := util.StrictBindJSON(ctx, leavePolicyRequest); err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return false
+	}
+
+	if err := validator.New().Struct(leavePolicyRequest); err != nil {
+		var ve validator.ValidationErrors
+		if errors.As(err, &ve) {
+			out := make(map[string]string)
+			for _, fe := range ve {
+				out[fe.Field()] = util.MsgForTag(fe)
+			}
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"errors": out})
+			return false
+		}
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return false
+	}
+
+	if rangeErrors := leavePolicyRequest.ValidateRanges(); len(rangeErrors) > 0 {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"errors": rangeErrors})
+		return false
+	}
+
+	return true
+}
@@ -2,10 +2,15 @@ package handler
 
 import (
 	"errors"
+	"fmt"
+	"io"
+	"mime"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
+	constants "github.com/devonLoen/leave-request-service/internal/app/rest_api/constant"
 	"github.com/devonLoen/leave-request-service/internal/app/rest_api/entity"
 	dto "github.com/devonLoen/leave-request-service/internal/app/rest_api/model/dto"
 	"github.com/devonLoen/leave-request-service/internal/app/rest_api/pkg/util"
@@ -14,6 +19,9 @@ import (
 	"github.com/go-playground/validator/v10"
 )
 
+// maxAttachmentUploadSize leaves room for the multipart framing around the largest attachment.
+const maxAttachmentUploadSize = usecase.MaxAttachmentSize + 1<<20
+
 type LeaveRequest struct {
 	leaveRequestUsecase usecase.LeaveRequestUsecase
 }
@@ -54,7 +62,7 @@ func (h *LeaveRequest) GetAllLeaveRequests(ctx *gin.Context) {
 	}
 	offset := (page - 1) * limit
 
-	allUsers, err := h.leaveRequestUsecase.GetAllLeaveRequests(limit, offset, sortByStr, orderByStr, search, filter)
+	allUsers, err := h.leaveRequestUsecase.GetAllLeaveRequests(ctx.Request.Context(), limit, offset, sortByStr, orderByStr, search, filter)
 	if err != nil {
 		ctx.AbortWithStatusJSON(err.Code, err)
 
@@ -64,6 +72,61 @@ func (h *LeaveRequest) GetAllLeaveRequests(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, allUsers)
 }
 
+// ExportLeaveRequests streams the requests matched by the filters of GetAllLeaveRequests,
+// optionally limited to those touching from to to, as a CSV or XLSX download.
+func (h *LeaveRequest) ExportLeaveRequests(ctx *gin.Context) {
+	sortByStr := ctx.DefaultQuery("sortBy", "id")
+	orderByStr := ctx.DefaultQuery("orderBy", "asc")
+
+	format := util.ExportFormat(ctx.DefaultQuery("format", string(util.ExportCSV)))
+	if !format.IsValid() {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "format not valid"})
+
+		return
+	}
+
+	filter := entity.LeaveRequestFilter{
+		UserId: ctx.Query("userId"),
+		Status: ctx.Query("status"),
+	}
+
+	from, errParse := queryDate(ctx, "from")
+	if errParse != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "from not valid"})
+
+		return
+	}
+	filter.From = from
+
+	to, errParse := queryDate(ctx, "to")
+	if errParse != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "to not valid"})
+
+		return
+	}
+	filter.To = to
+
+	search := ctx.Query("search")
+
+	ctx.Header("Content-Type", format.ContentType())
+	ctx.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="leave-requests-%s.%s"`, time.Now().Format("20060102"), format))
+
+	err := h.leaveRequestUsecase.ExportLeaveRequests(ctx.Request.Context(), ctx.Writer, format, sortByStr, orderByStr, search, filter)
+	if err != nil {
+		if ctx.Writer.Written() {
+			// The download has started; the client sees a truncated file.
+			ctx.Abort()
+
+			return
+		}
+		ctx.Writer.Header().Del("Content-Type")
+		ctx.Writer.Header().Del("Content-Disposition")
+		ctx.AbortWithStatusJSON(err.Code, err)
+
+		return
+	}
+}
+
 func (h *LeaveRequest) GetMyLeaveRequests(ctx *gin.Context) {
 	pageStr := ctx.DefaultQuery("page", "1")
 	limitStr := ctx.DefaultQuery("limit", "10")
@@ -98,7 +161,7 @@ func (h *LeaveRequest) GetMyLeaveRequests(ctx *gin.Context) {
 	}
 	offset := (page - 1) * limit
 
-	allUsers, err := h.leaveRequestUsecase.GetAllLeaveRequests(limit, offset, sortByStr, orderByStr, search, filter)
+	allUsers, err := h.leaveRequestUsecase.GetAllLeaveRequests(ctx.Request.Context(), limit, offset, sortByStr, orderByStr, search, filter)
 	if err != nil {
 		ctx.AbortWithStatusJSON(err.Code, err)
 
@@ -108,6 +171,59 @@ func (h *LeaveRequest) GetMyLeaveRequests(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, allUsers)
 }
 
+func (h *LeaveRequest) GetTeamLeaveRequests(ctx *gin.Context) {
+	pageStr := ctx.DefaultQuery("page", "1")
+	limitStr := ctx.DefaultQuery("limit", "10")
+	sortByStr := ctx.DefaultQuery("sortBy", "id")
+	orderByStr := ctx.DefaultQuery("orderBy", "asc")
+	userIDRaw, _ := ctx.Get("userId")
+	userID := userIDRaw.(int)
+
+	includeIndirect, errConv := strconv.ParseBool(ctx.DefaultQuery("indirect", "false"))
+	if errConv != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "indirect not valid "})
+
+		return
+	}
+
+	filter := entity.LeaveRequestFilter{
+		UserId:          ctx.Query("userId"),
+		Status:          ctx.Query("status"),
+		ManagerId:       userID,
+		IncludeIndirect: includeIndirect,
+	}
+
+	search := ctx.Query("search")
+
+	page, errConv := strconv.Atoi(pageStr)
+	if errConv != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Page not valid "})
+
+		return
+	}
+
+	limit, errConv := strconv.Atoi(limitStr)
+	if errConv != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "limit not valid "})
+
+		return
+	}
+
+	if page < 1 {
+		page = 1
+	}
+	offset := (page - 1) * limit
+
+	teamLeaveRequests, err := h.leaveRequestUsecase.GetAllLeaveRequests(ctx.Request.Context(), limit, offset, sortByStr, orderByStr, search, filter)
+	if err != nil {
+		ctx.AbortWithStatusJSON(err.Code, err)
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, teamLeaveRequests)
+}
+
 func (h *LeaveRequest) GetLeaveRequest(ctx *gin.Context) {
 	leaveRequestID, err := strconv.Atoi(ctx.Param("id"))
 
@@ -117,27 +233,169 @@ func (h *LeaveRequest) GetLeaveRequest(ctx *gin.Context) {
 		return
 	}
 
-	leaveRequest, leaveRequestErr := h.leaveRequestUsecase.GetLeaveRequest(leaveRequestID)
+	leaveRequest, leaveRequestErr := h.leaveRequestUsecase.GetLeaveRequest(ctx.Request.Context(), leaveRequestID)
 	if leaveRequestErr != nil {
 		ctx.AbortWithStatusJSON(leaveRequestErr.Code, leaveRequestErr)
 
 		return
 	}
 
+	ctx.Header(constants.Headers.ETag, leaveRequestETag(leaveRequest.Version))
 	ctx.JSON(http.StatusOK, leaveRequest)
 }
 
+func (h *LeaveRequest) GetLeaveRequestHistory(ctx *gin.Context) {
+	leaveRequestID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Leave Request ID not valid"})
+
+		return
+	}
+
+	userIDRaw, _ := ctx.Get("userId")
+	userID := userIDRaw.(int)
+
+	history, historyErr := h.leaveRequestUsecase.GetLeaveRequestHistory(ctx.Request.Context(), leaveRequestID, userID)
+	if historyErr != nil {
+		ctx.AbortWithStatusJSON(historyErr.Code, historyErr)
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, history)
+}
+
+func (h *LeaveRequest) GetLeaveRequestActions(ctx *gin.Context) {
+	leaveRequestID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Leave Request ID not valid"})
+
+		return
+	}
+
+	userIDRaw, _ := ctx.Get("userId")
+	userID := userIDRaw.(int)
+
+	actions, actionsErr := h.leaveRequestUsecase.GetLeaveRequestActions(ctx.Request.Context(), leaveRequestID, userID)
+	if actionsErr != nil {
+		ctx.AbortWithStatusJSON(actionsErr.Code, actionsErr)
+
+		return
+	}
+
+	ctx.Header(constants.Headers.ETag, leaveRequestETag(actions.Version))
+	ctx.JSON(http.StatusOK, actions)
+}
+
 func (h *LeaveRequest) CreateLeaveRequest(ctx *gin.Context) {
 	var createLeaveRequestRequest dto.CreateLeaveRequestRequest
 	userIDRaw, _ := ctx.Get("userId")
 	userID := userIDRaw.(int)
 
-	if err := util.StrictBindJSON(ctx, &createLeaveRequestRequest); err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if !bindLeaveRequest(ctx, &createLeaveRequestRequest) {
+		return
+	}
+
+	createLeaveRequestResponse, signupError := h.leaveRequestUsecase.CreateLeaveRequest(ctx.Request.Context(), &createLeaveRequestRequest, userID)
+	if signupError != nil {
+		ctx.AbortWithStatusJSON(signupError.Code, signupError)
+
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, createLeaveRequestResponse)
+}
+
+func (h *LeaveRequest) UpdateLeaveRequest(ctx *gin.Context) {
+	var updateLeaveRequestRequest dto.CreateLeaveRequestRequest
+
+	leaveRequestID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Leave Request ID not valid"})
+
+		return
+	}
+
+	version, ok := ifMatchVersion(ctx)
+	if !ok {
+		return
+	}
+
+	userIDRaw, _ := ctx.Get("userId")
+	userID := userIDRaw.(int)
+
+	if !bindLeaveRequest(ctx, &updateLeaveRequestRequest) {
+		return
+	}
+
+	leaveRequest, updateError := h.leaveRequestUsecase.UpdateLeaveRequest(ctx.Request.Context(), leaveRequestID, &updateLeaveRequestRequest, userID, version)
+	if updateError != nil {
+		ctx.AbortWithStatusJSON(updateError.Code, updateError)
+
+		return
+	}
+
+	ctx.Header(constants.Headers.ETag, leaveRequestETag(leaveRequest.Version))
+	ctx.JSON(http.StatusOK, leaveRequest)
+}
+
+func (h *LeaveRequest) DeleteLeaveRequest(ctx *gin.Context) {
+	leaveRequestID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Leave Request ID not valid"})
+
+		return
+	}
+
+	version, ok := ifMatchVersion(ctx)
+	if !ok {
+		return
+	}
+
+	userIDRaw, _ := ctx.Get("userId")
+	userID := userIDRaw.(int)
+
+	deleteError := h.leaveRequestUsecase.DeleteLeaveRequest(ctx.Request.Context(), leaveRequestID, userID, version)
+	if deleteError != nil {
+		ctx.AbortWithStatusJSON(deleteError.Code, deleteError)
 		return
 	}
 
-	if err := validator.New().Struct(createLeaveRequestRequest); err != nil {
+	ctx.JSON(http.StatusOK, gin.H{"message": "Leave Request Deleted"})
+}
+
+// leaveRequestETag is the entity tag of a leave request: its version.
+func leaveRequestETag(version int) string {
+	return strconv.Quote(strconv.Itoa(version))
+}
+
+// ifMatchVersion reads the version a client expects from the If-Match header, as sent back
+// from an ETag. It returns 0 when the header is missing or "*". It writes the error response
+// itself and reports whether the handler can go on.
+func ifMatchVersion(ctx *gin.Context) (int, bool) {
+	ifMatch := strings.TrimSpace(ctx.GetHeader(constants.Headers.IfMatch))
+	if ifMatch == "" || ifMatch == "*" {
+		return 0, true
+	}
+
+	version, err := strconv.Atoi(strings.Trim(strings.TrimPrefix(ifMatch, "W/"), `"`))
+	if err != nil || version < 1 {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "If-Match header not valid"})
+		return 0, false
+	}
+
+	return version, true
+}
+
+// bindLeaveRequest binds and validates the body shared by creating and updating a leave
+// request. It writes the error response itself and reports whether the handler can go on.
+func bindLeaveRequest(ctx *gin.Context, leaveRequestRequest *dto.CreateLeaveRequestRequest) bool {
+	if err := util.StrictBindJSON(ctx, leaveRequestRequest); err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return false
+	}
+
+	if err := validator.New().Struct(leaveRequestRequest); err != nil {
 		var ve validator.ValidationErrors
 		if errors.As(err, &ve) {
 			out := make(map[string]string)
@@ -145,22 +403,27 @@ func (h *LeaveRequest) CreateLeaveRequest(ctx *gin.Context) {
 				out[fe.Field()] = util.MsgForTag(fe)
 			}
 			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"errors": out})
-			return
+			return false
 		}
 		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
+		return false
 	}
 
-	if createLeaveRequestRequest.StartDate.After(createLeaveRequestRequest.EndDate) {
+	if leaveRequestRequest.StartDate.After(leaveRequestRequest.EndDate) {
 		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
 			"errors": map[string]string{"startDate": "startDate cannot be after endDate"},
 		})
-		return
+		return false
+	}
+
+	if durationErrors := leaveRequestRequest.ValidateDuration(); len(durationErrors) > 0 {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"errors": durationErrors})
+		return false
 	}
 
 	loc, _ := time.LoadLocation("Asia/Jakarta")
 	now := time.Now().In(loc)
-	start := createLeaveRequestRequest.StartDate
+	start := leaveRequestRequest.StartDate
 
 	nowDate := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
 	startDate := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, loc)
@@ -169,17 +432,36 @@ func (h *LeaveRequest) CreateLeaveRequest(ctx *gin.Context) {
 		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
 			"errors": map[string]string{"startDate": "Leave request cannot be in the past"},
 		})
-		return
+		return false
 	}
 
-	createLeaveRequestResponse, signupError := h.leaveRequestUsecase.CreateLeaveRequest(&createLeaveRequestRequest, userID)
-	if signupError != nil {
-		ctx.AbortWithStatusJSON(signupError.Code, signupError)
+	return true
+}
 
-		return
+// bindDecision binds and validates the body of an approve or reject call. An optional body
+// may be left out entirely. It writes the error response itself and reports whether the
+// handler can go on.
+func bindDecision(ctx *gin.Context, decisionRequest interface{}, optional bool) bool {
+	if err := util.StrictBindJSON(ctx, decisionRequest); err != nil && !(optional && errors.Is(err, io.EOF)) {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return false
 	}
 
-	ctx.JSON(http.StatusCreated, createLeaveRequestResponse)
+	if err := validator.New().Struct(decisionRequest); err != nil {
+		var ve validator.ValidationErrors
+		if errors.As(err, &ve) {
+			out := make(map[string]string)
+			for _, fe := range ve {
+				out[fe.Field()] = util.MsgForTag(fe)
+			}
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"errors": out})
+			return false
+		}
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return false
+	}
+
+	return true
 }
 
 func (h *LeaveRequest) Approve(ctx *gin.Context) {
@@ -190,13 +472,31 @@ func (h *LeaveRequest) Approve(ctx *gin.Context) {
 		return
 	}
 
-	approveError := h.leaveRequestUsecase.Approve(leaveRequestID)
+	version, ok := ifMatchVersion(ctx)
+	if !ok {
+		return
+	}
+
+	var approveLeaveRequestRequest dto.ApproveLeaveRequestRequest
+	if !bindDecision(ctx, &approveLeaveRequestRequest, true) {
+		return
+	}
+
+	userIDRaw, _ := ctx.Get("userId")
+	userID := userIDRaw.(int)
+
+	status, approveError := h.leaveRequestUsecase.Approve(ctx.Request.Context(), leaveRequestID, userID, version, approveLeaveRequestRequest.Comment)
 	if approveError != nil {
 		ctx.AbortWithStatusJSON(approveError.Code, approveError)
 		return
 	}
 
-	ctx.JSON(http.StatusOK, gin.H{"message": "Leave Request Approved"})
+	if status == entity.WaitingApproval {
+		ctx.JSON(http.StatusOK, gin.H{"message": "Approval Step Recorded", "status": status})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Leave Request Approved", "status": status})
 }
 
 func (h *LeaveRequest) Reject(ctx *gin.Context) {
@@ -207,7 +507,20 @@ func (h *LeaveRequest) Reject(ctx *gin.Context) {
 		return
 	}
 
-	rejectError := h.leaveRequestUsecase.Reject(leaveRequestID)
+	version, ok := ifMatchVersion(ctx)
+	if !ok {
+		return
+	}
+
+	var rejectLeaveRequestRequest dto.RejectLeaveRequestRequest
+	if !bindDecision(ctx, &rejectLeaveRequestRequest, false) {
+		return
+	}
+
+	userIDRaw, _ := ctx.Get("userId")
+	userID := userIDRaw.(int)
+
+	rejectError := h.leaveRequestUsecase.Reject(ctx.Request.Context(), leaveRequestID, userID, version, rejectLeaveRequestRequest.Reason)
 	if rejectError != nil {
 		ctx.AbortWithStatusJSON(rejectError.Code, rejectError)
 		return
@@ -224,14 +537,247 @@ func (h *LeaveRequest) Submit(ctx *gin.Context) {
 		return
 	}
 
+	version, ok := ifMatchVersion(ctx)
+	if !ok {
+		return
+	}
+
 	userIDRaw, _ := ctx.Get("userId")
 	userID := userIDRaw.(int)
 
-	submitError := h.leaveRequestUsecase.Submit(leaveRequestID, userID)
+	status, submitError := h.leaveRequestUsecase.Submit(ctx.Request.Context(), leaveRequestID, userID, version)
 	if submitError != nil {
 		ctx.AbortWithStatusJSON(submitError.Code, submitError)
 		return
 	}
 
-	ctx.JSON(http.StatusOK, gin.H{"message": "Leave Request Submitted"})
+	if status == entity.Approved {
+		ctx.JSON(http.StatusOK, gin.H{"message": "Leave Request Approved Automatically", "status": status})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Leave Request Submitted", "status": status})
+}
+
+func (h *LeaveRequest) Cancel(ctx *gin.Context) {
+	leaveRequestID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Leave Request ID not valid"})
+
+		return
+	}
+
+	version, ok := ifMatchVersion(ctx)
+	if !ok {
+		return
+	}
+
+	userIDRaw, _ := ctx.Get("userId")
+	userID := userIDRaw.(int)
+
+	status, cancelError := h.leaveRequestUsecase.Cancel(ctx.Request.Context(), leaveRequestID, userID, version)
+	if cancelError != nil {
+		ctx.AbortWithStatusJSON(cancelError.Code, cancelError)
+		return
+	}
+
+	if status == entity.CancellationRequested {
+		ctx.JSON(http.StatusAccepted, gin.H{"message": "Leave Request Cancellation Requested", "status": status})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Leave Request Cancelled", "status": status})
+}
+
+func (h *LeaveRequest) BulkDecide(ctx *gin.Context) {
+	var bulkDecisionRequest dto.BulkDecisionRequest
+	if !bindDecision(ctx, &bulkDecisionRequest, false) {
+		return
+	}
+
+	userIDRaw, _ := ctx.Get("userId")
+	userID := userIDRaw.(int)
+
+	bulkDecisionResponse, decideError := h.leaveRequestUsecase.BulkDecide(ctx.Request.Context(), userID, &bulkDecisionRequest)
+	if decideError != nil {
+		ctx.AbortWithStatusJSON(decideError.Code, decideError)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, bulkDecisionResponse)
+}
+
+func (h *LeaveRequest) ApproveCancellation(ctx *gin.Context) {
+	leaveRequestID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Leave Request ID not valid"})
+
+		return
+	}
+
+	version, ok := ifMatchVersion(ctx)
+	if !ok {
+		return
+	}
+
+	userIDRaw, _ := ctx.Get("userId")
+	userID := userIDRaw.(int)
+
+	approveError := h.leaveRequestUsecase.ApproveCancellation(ctx.Request.Context(), leaveRequestID, userID, version)
+	if approveError != nil {
+		ctx.AbortWithStatusJSON(approveError.Code, approveError)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Leave Request Cancellation Approved"})
+}
+
+func (h *LeaveRequest) RejectCancellation(ctx *gin.Context) {
+	leaveRequestID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Leave Request ID not valid"})
+
+		return
+	}
+
+	version, ok := ifMatchVersion(ctx)
+	if !ok {
+		return
+	}
+
+	userIDRaw, _ := ctx.Get("userId")
+	userID := userIDRaw.(int)
+
+	rejectError := h.leaveRequestUsecase.RejectCancellation(ctx.Request.Context(), leaveRequestID, userID, version)
+	if rejectError != nil {
+		ctx.AbortWithStatusJSON(rejectError.Code, rejectError)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Leave Request Cancellation Rejected"})
+}
+
+func (h *LeaveRequest) UploadAttachment(ctx *gin.Context) {
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxAttachmentUploadSize)
+
+	leaveRequestID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Leave Request ID not valid"})
+
+		return
+	}
+
+	fileHeader, err := ctx.FormFile("file")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			ctx.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Attachment is too large"})
+
+			return
+		}
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "A file is required in the 'file' field"})
+
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Unable to read uploaded file"})
+
+		return
+	}
+	defer file.Close()
+
+	userIDRaw, _ := ctx.Get("userId")
+	userID := userIDRaw.(int)
+
+	attachment, uploadError := h.leaveRequestUsecase.UploadAttachment(ctx.Request.Context(), leaveRequestID, userID, fileHeader.Filename, fileHeader.Size, file)
+	if uploadError != nil {
+		ctx.AbortWithStatusJSON(uploadError.Code, uploadError)
+
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, attachment)
+}
+
+func (h *LeaveRequest) GetAttachments(ctx *gin.Context) {
+	leaveRequestID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Leave Request ID not valid"})
+
+		return
+	}
+
+	userIDRaw, _ := ctx.Get("userId")
+	userID := userIDRaw.(int)
+
+	attachments, getError := h.leaveRequestUsecase.GetAttachments(ctx.Request.Context(), leaveRequestID, userID)
+	if getError != nil {
+		ctx.AbortWithStatusJSON(getError.Code, getError)
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, attachments)
+}
+
+func (h *LeaveRequest) DownloadAttachment(ctx *gin.Context) {
+	leaveRequestID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Leave Request ID not valid"})
+
+		return
+	}
+
+	attachmentID, err := strconv.Atoi(ctx.Param("attachmentId"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Attachment ID not valid"})
+
+		return
+	}
+
+	userIDRaw, _ := ctx.Get("userId")
+	userID := userIDRaw.(int)
+
+	attachment, content, downloadError := h.leaveRequestUsecase.DownloadAttachment(ctx.Request.Context(), leaveRequestID, attachmentID, userID)
+	if downloadError != nil {
+		ctx.AbortWithStatusJSON(downloadError.Code, downloadError)
+
+		return
+	}
+	defer content.Close()
+
+	ctx.DataFromReader(http.StatusOK, attachment.Size, attachment.ContentType, content, map[string]string{
+		"Content-Disposition":    mime.FormatMediaType("attachment", map[string]string{"filename": attachment.FileName}),
+		"X-Content-Type-Options": "nosniff",
+	})
+}
+
+func (h *LeaveRequest) DeleteAttachment(ctx *gin.Context) {
+	leaveRequestID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Leave Request ID not valid"})
+
+		return
+	}
+
+	attachmentID, err := strconv.Atoi(ctx.Param("attachmentId"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Attachment ID not valid"})
+
+		return
+	}
+
+	userIDRaw, _ := ctx.Get("userId")
+	userID := userIDRaw.(int)
+
+	deleteError := h.leaveRequestUsecase.DeleteAttachment(ctx.Request.Context(), leaveRequestID, attachmentID, userID)
+	if deleteError != nil {
+		ctx.AbortWithStatusJSON(deleteError.Code, deleteError)
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Attachment Deleted"})
 }
@@ -2,7 +2,9 @@ package handler_test
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -16,13 +18,14 @@ import (
 	handler "github.com/devonLoen/leave-request-service/internal/app/rest_api/handler"
 	models "github.com/devonLoen/leave-request-service/internal/app/rest_api/model"
 	dto "github.com/devonLoen/leave-request-service/internal/app/rest_api/model/dto"
+	"github.com/devonLoen/leave-request-service/internal/app/rest_api/pkg/util"
 )
 
 type MockLeaveRequestUsecase struct {
 	mock.Mock
 }
 
-func (m *MockLeaveRequestUsecase) CreateLeaveRequest(req *dto.CreateLeaveRequestRequest, userID int) (*dto.CreateLeaveRequestResponse, *models.ErrorResponse) {
+func (m *MockLeaveRequestUsecase) CreateLeaveRequest(ctx context.Context, req *dto.CreateLeaveRequestRequest, userID int) (*dto.CreateLeaveRequestResponse, *models.ErrorResponse) {
 	args := m.Called(req, userID)
 	if args.Get(0) == nil {
 		return nil, args.Get(1).(*models.ErrorResponse)
@@ -31,6 +34,7 @@ func (m *MockLeaveRequestUsecase) CreateLeaveRequest(req *dto.CreateLeaveRequest
 }
 
 func (m *MockLeaveRequestUsecase) GetAllLeaveRequests(
+	ctx context.Context,
 	limit, offset int,
 	sortBy, orderBy, search string,
 	filter entity.LeaveRequestFilter,
@@ -42,13 +46,77 @@ func (m *MockLeaveRequestUsecase) GetAllLeaveRequests(
 	return args.Get(0).(*dto.GetAllLeaveRequestsResponse), nil
 }
 
-func (m *MockLeaveRequestUsecase) GetLeaveRequest(id int) (*dto.LeaveRequestResponse, *models.ErrorResponse) {
+func (m *MockLeaveRequestUsecase) ExportLeaveRequests(ctx context.Context, w io.Writer, format util.ExportFormat, sortBy, orderBy, search string, filter entity.LeaveRequestFilter) *models.ErrorResponse {
+	args := m.Called(format, sortBy, orderBy, search, filter)
+	if body := args.String(1); body != "" {
+		_, _ = io.WriteString(w, body)
+	}
+	if args.Get(0) == nil {
+		return nil
+	}
+	return args.Get(0).(*models.ErrorResponse)
+}
+
+func (m *MockLeaveRequestUsecase) GetLeaveRequestHistory(ctx context.Context, id, userID int) (*dto.GetLeaveRequestHistoryResponse, *models.ErrorResponse) {
+	return &dto.GetLeaveRequestHistoryResponse{}, nil
+}
+
+func (m *MockLeaveRequestUsecase) GetLeaveRequestActions(ctx context.Context, id, userID int) (*dto.LeaveRequestActionsResponse, *models.ErrorResponse) {
+	return &dto.LeaveRequestActionsResponse{}, nil
+}
+
+func (m *MockLeaveRequestUsecase) GetLeaveRequest(ctx context.Context, id int) (*dto.LeaveRequestResponse, *models.ErrorResponse) {
 	return nil, nil
 }
 
-func (m *MockLeaveRequestUsecase) Approve(id int) *models.ErrorResponse        { return nil }
-func (m *MockLeaveRequestUsecase) Reject(id int) *models.ErrorResponse         { return nil }
-func (m *MockLeaveRequestUsecase) Submit(id, userID int) *models.ErrorResponse { return nil }
+func (m *MockLeaveRequestUsecase) UpdateLeaveRequest(ctx context.Context, id int, req *dto.CreateLeaveRequestRequest, userID, version int) (*dto.LeaveRequestResponse, *models.ErrorResponse) {
+	args := m.Called(id, req, userID)
+	if args.Get(0) == nil {
+		return nil, args.Get(1).(*models.ErrorResponse)
+	}
+	return args.Get(0).(*dto.LeaveRequestResponse), nil
+}
+
+func (m *MockLeaveRequestUsecase) DeleteLeaveRequest(ctx context.Context, id, userID, version int) *models.ErrorResponse {
+	return nil
+}
+func (m *MockLeaveRequestUsecase) Approve(ctx context.Context, id, approverID, version int, comment string) (entity.LeaveRequestStatus, *models.ErrorResponse) {
+	return entity.Approved, nil
+}
+func (m *MockLeaveRequestUsecase) Reject(ctx context.Context, id, approverID, version int, reason string) *models.ErrorResponse {
+	return nil
+}
+func (m *MockLeaveRequestUsecase) BulkDecide(ctx context.Context, approverID int, req *dto.BulkDecisionRequest) (*dto.BulkDecisionResponse, *models.ErrorResponse) {
+	return &dto.BulkDecisionResponse{}, nil
+}
+func (m *MockLeaveRequestUsecase) UploadAttachment(ctx context.Context, id, userID int, fileName string, size int64, file io.Reader) (*dto.AttachmentResponse, *models.ErrorResponse) {
+	return &dto.AttachmentResponse{}, nil
+}
+func (m *MockLeaveRequestUsecase) GetAttachments(ctx context.Context, id, userID int) (*dto.GetAttachmentsResponse, *models.ErrorResponse) {
+	return &dto.GetAttachmentsResponse{}, nil
+}
+func (m *MockLeaveRequestUsecase) DownloadAttachment(ctx context.Context, id, attachmentID, userID int) (*entity.LeaveRequestAttachment, io.ReadCloser, *models.ErrorResponse) {
+	return nil, nil, nil
+}
+func (m *MockLeaveRequestUsecase) DeleteAttachment(ctx context.Context, id, attachmentID, userID int) *models.ErrorResponse {
+	return nil
+}
+func (m *MockLeaveRequestUsecase) Submit(ctx context.Context, id, userID, version int) (entity.LeaveRequestStatus, *models.ErrorResponse) {
+	args := m.Called(id, userID, version)
+	if args.Get(1) != nil {
+		return "", args.Get(1).(*models.ErrorResponse)
+	}
+	return args.Get(0).(entity.LeaveRequestStatus), nil
+}
+func (m *MockLeaveRequestUsecase) Cancel(ctx context.Context, id, userID, version int) (entity.LeaveRequestStatus, *models.ErrorResponse) {
+	return entity.Cancelled, nil
+}
+func (m *MockLeaveRequestUsecase) ApproveCancellation(ctx context.Context, id, approverID, version int) *models.ErrorResponse {
+	return nil
+}
+func (m *MockLeaveRequestUsecase) RejectCancellation(ctx context.Context, id, approverID, version int) *models.ErrorResponse {
+	return nil
+}
 
 func TestCreateLeaveRequestHandler(t *testing.T) {
 	gin.SetMode(gin.TestMode)
@@ -79,7 +147,7 @@ func TestCreateLeaveRequestHandler(t *testing.T) {
 			name: "Start date in the past",
 			input: dto.CreateLeaveRequestRequest{
 				StartDate: today.Add(-24 * time.Hour),
-				EndDate:   today.Add(24 * time.Hour),
+				EndDate:   today.Add(-24 * time.Hour),
 				Reason:    "Annual leave",
 				Type:      "annual",
 				Status:    "waiting_approval",
@@ -88,11 +156,72 @@ func TestCreateLeaveRequestHandler(t *testing.T) {
 			expectedCode:   http.StatusBadRequest,
 			expectedErrMsg: "Leave request cannot be in the past",
 		},
+		{
+			name: "Hourly leave spanning two days",
+			input: dto.CreateLeaveRequestRequest{
+				StartDate:    today.Add(24 * time.Hour),
+				EndDate:      today.Add(48 * time.Hour),
+				DurationUnit: "hour",
+				StartTime:    "09:00",
+				EndTime:      "11:00",
+				Reason:       "Dentist appointment",
+				Type:         "annual",
+				Status:       "waiting_approval",
+			},
+			mockSetup:      func(m *MockLeaveRequestUsecase) {},
+			expectedCode:   http.StatusBadRequest,
+			expectedErrMsg: "Hourly leave must start and end on the same day",
+		},
+		{
+			name: "Hourly leave spanning the whole day",
+			input: dto.CreateLeaveRequestRequest{
+				StartDate:    today.Add(24 * time.Hour),
+				EndDate:      today.Add(24 * time.Hour),
+				DurationUnit: "hour",
+				StartTime:    "00:00",
+				EndTime:      "23:59",
+				Reason:       "Moving to a new flat",
+				Type:         "annual",
+				Status:       "waiting_approval",
+			},
+			mockSetup:      func(m *MockLeaveRequestUsecase) {},
+			expectedCode:   http.StatusBadRequest,
+			expectedErrMsg: "Hourly leave cannot be longer than 8 hours, request a full day instead",
+		},
+		{
+			name: "Hourly leave longer than a working day",
+			input: dto.CreateLeaveRequestRequest{
+				StartDate:    today.Add(24 * time.Hour),
+				EndDate:      today.Add(24 * time.Hour),
+				DurationUnit: "hour",
+				StartTime:    "08:00",
+				EndTime:      "20:00",
+				Reason:       "Moving to a new flat",
+				Type:         "annual",
+				Status:       "waiting_approval",
+			},
+			mockSetup:      func(m *MockLeaveRequestUsecase) {},
+			expectedCode:   http.StatusBadRequest,
+			expectedErrMsg: "Hourly leave cannot be longer than 8 hours, request a full day instead",
+		},
+		{
+			name: "Leave spanning two years",
+			input: dto.CreateLeaveRequestRequest{
+				StartDate: time.Date(today.Year()+1, time.December, 29, 0, 0, 0, 0, time.UTC),
+				EndDate:   time.Date(today.Year()+2, time.January, 3, 0, 0, 0, 0, time.UTC),
+				Reason:    "Year-end holidays",
+				Type:      "annual",
+				Status:    "waiting_approval",
+			},
+			mockSetup:      func(m *MockLeaveRequestUsecase) {},
+			expectedCode:   http.StatusBadRequest,
+			expectedErrMsg: "Leave cannot span two leave years, request each year separately",
+		},
 		{
 			name: "Usecase error",
 			input: dto.CreateLeaveRequestRequest{
 				StartDate: today.Add(24 * time.Hour),
-				EndDate:   today.Add(48 * time.Hour),
+				EndDate:   today.Add(24 * time.Hour),
 				Reason:    "Annual leave",
 				Type:      "annual",
 				Status:    "waiting_approval",
@@ -111,7 +240,7 @@ func TestCreateLeaveRequestHandler(t *testing.T) {
 			name: "Success",
 			input: dto.CreateLeaveRequestRequest{
 				StartDate: today.Add(24 * time.Hour),
-				EndDate:   today.Add(48 * time.Hour),
+				EndDate:   today.Add(24 * time.Hour),
 				Reason:    "Annual leave",
 				Type:      "annual",
 				Status:    "waiting_approval",
@@ -153,3 +282,154 @@ func TestCreateLeaveRequestHandler(t *testing.T) {
 		})
 	}
 }
+
+func TestSubmitReadsIfMatch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name         string
+		ifMatch      string
+		mockSetup    func(m *MockLeaveRequestUsecase)
+		expectedCode int
+	}{
+		{
+			name: "No header skips the check",
+			mockSetup: func(m *MockLeaveRequestUsecase) {
+				m.On("Submit", 7, 1, 0).Return(entity.WaitingApproval, nil).Once()
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:    "Strong ETag",
+			ifMatch: `"3"`,
+			mockSetup: func(m *MockLeaveRequestUsecase) {
+				m.On("Submit", 7, 1, 3).Return(entity.WaitingApproval, nil).Once()
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:    "Weak ETag",
+			ifMatch: `W/"3"`,
+			mockSetup: func(m *MockLeaveRequestUsecase) {
+				m.On("Submit", 7, 1, 3).Return(entity.WaitingApproval, nil).Once()
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:    "Stale version",
+			ifMatch: `"2"`,
+			mockSetup: func(m *MockLeaveRequestUsecase) {
+				m.On("Submit", 7, 1, 2).Return(entity.LeaveRequestStatus(""), &models.ErrorResponse{
+					Code:    http.StatusPreconditionFailed,
+					Message: "Leave Request has changed since it was last read",
+				}).Once()
+			},
+			expectedCode: http.StatusPreconditionFailed,
+		},
+		{
+			name:         "Malformed header",
+			ifMatch:      "latest",
+			mockSetup:    func(m *MockLeaveRequestUsecase) {},
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUC := new(MockLeaveRequestUsecase)
+			tt.mockSetup(mockUC)
+
+			r := gin.New()
+			h := handler.NewLeaveRequestHandler(mockUC)
+			r.PATCH("/leave/:id/submit", func(c *gin.Context) {
+				c.Set("userId", 1)
+				h.Submit(c)
+			})
+
+			req, _ := http.NewRequest(http.MethodPatch, "/leave/7/submit", nil)
+			if tt.ifMatch != "" {
+				req.Header.Set("If-Match", tt.ifMatch)
+			}
+			w := httptest.NewRecorder()
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			mockUC.AssertExpectations(t)
+		})
+	}
+}
+
+func TestExportLeaveRequests(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name                string
+		query               string
+		mockSetup           func(m *MockLeaveRequestUsecase)
+		expectedCode        int
+		expectedContentType string
+		expectedBody        string
+	}{
+		{
+			name:  "CSV download",
+			query: "?status=approved&from=2026-03-01&to=2026-03-31&sortBy=startDate",
+			mockSetup: func(m *MockLeaveRequestUsecase) {
+				filter := entity.LeaveRequestFilter{
+					Status: "approved",
+					From:   time.Date(2026, time.March, 1, 0, 0, 0, 0, time.UTC),
+					To:     time.Date(2026, time.March, 31, 0, 0, 0, 0, time.UTC),
+				}
+				m.On("ExportLeaveRequests", util.ExportCSV, "startDate", "asc", "", filter).Return(nil, "ID,Employee\n").Once()
+			},
+			expectedCode:        http.StatusOK,
+			expectedContentType: "text/csv; charset=utf-8",
+			expectedBody:        "ID,Employee\n",
+		},
+		{
+			name:  "Invalid sort is reported as JSON",
+			query: "?format=xlsx&sortBy=salary",
+			mockSetup: func(m *MockLeaveRequestUsecase) {
+				m.On("ExportLeaveRequests", util.ExportXLSX, "salary", "asc", "", entity.LeaveRequestFilter{}).Return(&models.ErrorResponse{
+					Code:    http.StatusBadRequest,
+					Message: "Invalid sort parameter",
+				}, "").Once()
+			},
+			expectedCode:        http.StatusBadRequest,
+			expectedContentType: "application/json; charset=utf-8",
+		},
+		{
+			name:                "Unknown format",
+			query:               "?format=pdf",
+			mockSetup:           func(m *MockLeaveRequestUsecase) {},
+			expectedCode:        http.StatusBadRequest,
+			expectedContentType: "application/json; charset=utf-8",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUC := new(MockLeaveRequestUsecase)
+			tt.mockSetup(mockUC)
+
+			r := gin.New()
+			h := handler.NewLeaveRequestHandler(mockUC)
+			r.GET("/leave-requests/export", h.ExportLeaveRequests)
+
+			req, _ := http.NewRequest(http.MethodGet, "/leave-requests/export"+tt.query, nil)
+			w := httptest.NewRecorder()
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			assert.Equal(t, tt.expectedContentType, w.Header().Get("Content-Type"))
+			if tt.expectedBody != "" {
+				assert.Equal(t, tt.expectedBody, w.Body.String())
+				assert.Contains(t, w.Header().Get("Content-Disposition"), ".csv")
+			} else {
+				assert.Empty(t, w.Header().Get("Content-Disposition"))
+			}
+			mockUC.AssertExpectations(t)
+		})
+	}
+}
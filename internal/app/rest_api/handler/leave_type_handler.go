@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	dto "github.com/devonLoen/leave-request-service/internal/app/rest_api/model/dto"
+	"github.com/devonLoen/leave-request-service/internal/app/rest_api/pkg/util"
+	"github.com/devonLoen/leave-request-service/internal/app/rest_api/usecase"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type LeaveType struct {
+	leaveTypeUsecase usecase.LeaveTypeUsecase
+}
+
+func NewLeaveTypeHandler(leaveTypeUsecase usecase.LeaveTypeUsecase) *LeaveType {
+	return &LeaveType{leaveTypeUsecase: leaveTypeUsecase}
+}
+
+func (h *LeaveType) GetLeaveTypes(ctx *gin.Context) {
+	leaveTypes, err := h.leaveTypeUsecase.GetLeaveTypes(ctx.Request.Context())
+	if err != nil {
+		ctx.AbortWithStatusJSON(err.Code, err)
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, leaveTypes)
+}
+
+func (h *LeaveType) UpsertLeaveType(ctx *gin.Context) {
+	var upsertLeaveTypeRequest dto.UpsertLeaveTypeRequest
+
+	if err := util.StrictBindJSON(ctx, &upsertLeaveTypeRequest); err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := validator.New().Struct(upsertLeaveTypeRequest); err != nil {
+		var ve validator.ValidationErrors
+		if errors.As(err, &ve) {
+			out := make(map[string]string)
+			for _, fe := range ve {
+				out[fe.Field()] = util.MsgForTag(fe)
+			}
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"errors": out})
+			return
+		}
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	leaveType, upsertError := h.leaveTypeUsecase.UpsertLeaveType(ctx.Request.Context(), ctx.Param("code"), &upsertLeaveTypeRequest)
+	if upsertError != nil {
+		ctx.AbortWithStatusJSON(upsertError.Code, upsertError)
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, leaveType)
+}
@@ -13,6 +13,8 @@ import (
 	"github.com/go-playground/validator/v10"
 )
 
+const maxUserImportSize = 1 << 20
+
 type User struct {
 	userUsecase *usecase.User
 }
@@ -52,7 +54,7 @@ func (h *User) GetAllUsers(ctx *gin.Context) {
 	}
 	offset := (page - 1) * limit
 
-	allUsers, err := h.userUsecase.GetAllUsers(limit, offset, sortByStr, orderByStr, search, filter)
+	allUsers, err := h.userUsecase.GetAllUsers(ctx.Request.Context(), limit, offset, sortByStr, orderByStr, search, filter)
 	if err != nil {
 		ctx.AbortWithStatusJSON(err.Code, err)
 
@@ -71,7 +73,7 @@ func (h *User) GetUser(ctx *gin.Context) {
 		return
 	}
 
-	user, userErr := h.userUsecase.GetUser(userID)
+	user, userErr := h.userUsecase.GetUser(ctx.Request.Context(), userID)
 	if userErr != nil {
 		ctx.AbortWithStatusJSON(userErr.Code, userErr)
 
@@ -103,7 +105,7 @@ func (h *User) CreateUser(ctx *gin.Context) {
 		return
 	}
 
-	createUserResponse, signupError := h.userUsecase.CreateUser(&createUserRequest)
+	createUserResponse, signupError := h.userUsecase.CreateUser(ctx.Request.Context(), &createUserRequest)
 	if signupError != nil {
 		ctx.AbortWithStatusJSON(signupError.Code, signupError)
 
@@ -112,3 +114,82 @@ func (h *User) CreateUser(ctx *gin.Context) {
 
 	ctx.JSON(http.StatusCreated, createUserResponse)
 }
+
+func (h *User) SetManager(ctx *gin.Context) {
+	var setManagerRequest dto.SetManagerRequest
+
+	userID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "User ID not valid"})
+
+		return
+	}
+
+	if err := util.StrictBindJSON(ctx, &setManagerRequest); err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := validator.New().Struct(setManagerRequest); err != nil {
+		var ve validator.ValidationErrors
+		if errors.As(err, &ve) {
+			out := make(map[string]string)
+			for _, fe := range ve {
+				out[fe.Field()] = util.MsgForTag(fe)
+			}
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"errors": out})
+			return
+		}
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, setError := h.userUsecase.SetManager(ctx.Request.Context(), userID, &setManagerRequest)
+	if setError != nil {
+		ctx.AbortWithStatusJSON(setError.Code, setError)
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, user)
+}
+
+func (h *User) ImportUsers(ctx *gin.Context) {
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxUserImportSize)
+
+	dryRun, errConv := strconv.ParseBool(ctx.DefaultQuery("dryRun", "false"))
+	if errConv != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "dryRun not valid"})
+
+		return
+	}
+
+	fileHeader, err := ctx.FormFile("file")
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "A .csv file is required in the 'file' field"})
+
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Unable to read uploaded file"})
+
+		return
+	}
+	defer file.Close()
+
+	importResponse, importError := h.userUsecase.ImportUsers(ctx.Request.Context(), file, dryRun)
+	if importError != nil {
+		ctx.AbortWithStatusJSON(importError.Code, importError)
+
+		return
+	}
+
+	status := http.StatusCreated
+	if dryRun || importResponse.Created == 0 {
+		status = http.StatusOK
+	}
+
+	ctx.JSON(status, importResponse)
+}
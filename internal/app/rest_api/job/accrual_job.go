@@ -0,0 +1,58 @@
+package job
+
+import (
+	"context"
+	"time"
+
+	"github.com/devonLoen/leave-request-service/internal/app/rest_api/usecase"
+	"github.com/rs/zerolog"
+)
+
+type AccrualJob struct {
+	l              zerolog.Logger
+	accrualUsecase usecase.AccrualUsecase
+	interval       time.Duration
+}
+
+func NewAccrualJob(l zerolog.Logger, accrualUsecase usecase.AccrualUsecase, interval time.Duration) *AccrualJob {
+	return &AccrualJob{l: l, accrualUsecase: accrualUsecase, interval: interval}
+}
+
+// Start runs the accrual engine for the current year up to today, then again on every
+// tick until ctx is cancelled. Runs are idempotent, so missed ticks catch up on the next one.
+func (j *AccrualJob) Start(ctx context.Context) {
+	if j.interval <= 0 {
+		j.l.Info().Msg("Accrual job disabled")
+		return
+	}
+
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		j.run(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (j *AccrualJob) run(ctx context.Context) {
+	now := time.Now().UTC()
+	from := time.Date(now.Year(), time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	result, err := j.accrualUsecase.Run(ctx, from, now)
+	if err != nil {
+		j.l.Error().Err(err).Msg("Accrual run failed")
+		return
+	}
+
+	j.l.Info().
+		Int("accrued", result.Accrued).
+		Int("carriedOver", result.CarriedOver).
+		Int("expired", result.Expired).
+		Msg("Accrual run finished")
+}
@@ -0,0 +1,53 @@
+package dto
+
+import (
+	entity "github.com/devonLoen/leave-request-service/internal/app/rest_api/entity"
+)
+
+type AccrualPolicyResponse struct {
+	ID                    int     `json:"id"`
+	Type                  string  `json:"type"`
+	DaysPerMonth          float64 `json:"daysPerMonth"`
+	CarryOverCap          float64 `json:"carryOverCap"`
+	CarryOverExpiryMonths int     `json:"carryOverExpiryMonths"`
+	Active                bool    `json:"active"`
+}
+
+type GetAccrualPoliciesResponse struct {
+	Policies []*AccrualPolicyResponse `json:"policies"`
+}
+
+type UpsertAccrualPolicyRequest struct {
+	DaysPerMonth          float64 `json:"daysPerMonth" validate:"min=0,max=31"`
+	CarryOverCap          float64 `json:"carryOverCap" validate:"min=0,max=366"`
+	CarryOverExpiryMonths int     `json:"carryOverExpiryMonths" validate:"min=0,max=12"`
+	Active                bool    `json:"active"`
+}
+
+func (r *GetAccrualPoliciesResponse) MapAccrualPoliciesResponse(policies []*entity.AccrualPolicy) {
+	r.Policies = []*AccrualPolicyResponse{}
+	for _, policy := range policies {
+		policyResponse := &AccrualPolicyResponse{}
+		policyResponse.MapAccrualPolicyResponse(policy)
+		r.Policies = append(r.Policies, policyResponse)
+	}
+}
+
+func (r *AccrualPolicyResponse) MapAccrualPolicyResponse(policy *entity.AccrualPolicy) {
+	r.ID = policy.ID
+	r.Type = string(policy.Type)
+	r.DaysPerMonth = policy.DaysPerMonth
+	r.CarryOverCap = policy.CarryOverCap
+	r.CarryOverExpiryMonths = policy.CarryOverExpiryMonths
+	r.Active = policy.Active
+}
+
+func (r *UpsertAccrualPolicyRequest) ToAccrualPolicy(leaveType entity.LeaveRequestType) *entity.AccrualPolicy {
+	return &entity.AccrualPolicy{
+		Type:                  leaveType,
+		DaysPerMonth:          r.DaysPerMonth,
+		CarryOverCap:          r.CarryOverCap,
+		CarryOverExpiryMonths: r.CarryOverExpiryMonths,
+		Active:                r.Active,
+	}
+}
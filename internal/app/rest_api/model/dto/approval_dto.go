@@ -0,0 +1,107 @@
+package dto
+
+import (
+	"fmt"
+	"time"
+
+	entity "github.com/devonLoen/leave-request-service/internal/app/rest_api/entity"
+)
+
+type ApprovalChainResponse struct {
+	ID             int      `json:"id"`
+	Type           string   `json:"type"`
+	MinWorkingDays float64  `json:"minWorkingDays"`
+	Steps          []string `json:"steps"`
+}
+
+type GetApprovalChainsResponse struct {
+	ApprovalChains []*ApprovalChainResponse `json:"approvalChains"`
+}
+
+type UpsertApprovalChainRequest struct {
+	Type           string   `json:"type" validate:"required,max=30"`
+	MinWorkingDays float64  `json:"minWorkingDays" validate:"min=0,max=366"`
+	Steps          []string `json:"steps" validate:"required,min=1,max=5,dive,oneof=manager admin"`
+}
+
+type ApprovalStepResponse struct {
+	StepOrder          int        `json:"stepOrder"`
+	ApproverKind       string     `json:"approverKind"`
+	Status             string     `json:"status"`
+	ApproverId         *int       `json:"approverId"`
+	ApproverName       string     `json:"approverName,omitempty"`
+	OnBehalfOfId       *int       `json:"onBehalfOfId,omitempty"`
+	OnBehalfOfName     string     `json:"onBehalfOfName,omitempty"`
+	AutoApprovalRuleId *int       `json:"autoApprovalRuleId,omitempty"`
+	Decision           string     `json:"decision,omitempty"`
+	Comment            string     `json:"comment,omitempty"`
+	DecidedAt          *time.Time `json:"decidedAt"`
+}
+
+func (r *GetApprovalChainsResponse) MapApprovalChainsResponse(chains []*entity.ApprovalChain) {
+	r.ApprovalChains = []*ApprovalChainResponse{}
+	for _, chain := range chains {
+		chainResponse := &ApprovalChainResponse{}
+		chainResponse.MapApprovalChainResponse(chain)
+		r.ApprovalChains = append(r.ApprovalChains, chainResponse)
+	}
+}
+
+func (r *ApprovalChainResponse) MapApprovalChainResponse(chain *entity.ApprovalChain) {
+	r.ID = chain.ID
+	r.Type = string(chain.Type)
+	r.MinWorkingDays = chain.MinWorkingDays
+	r.Steps = make([]string, 0, len(chain.Steps))
+	for _, step := range chain.Steps {
+		r.Steps = append(r.Steps, string(step))
+	}
+}
+
+func (r *UpsertApprovalChainRequest) ToApprovalChain() *entity.ApprovalChain {
+	steps := make([]entity.ApproverKind, 0, len(r.Steps))
+	for _, step := range r.Steps {
+		steps = append(steps, entity.ApproverKind(step))
+	}
+
+	return &entity.ApprovalChain{
+		Type:           entity.LeaveRequestType(r.Type),
+		MinWorkingDays: r.MinWorkingDays,
+		Steps:          steps,
+	}
+}
+
+func (r *LeaveRequestResponse) MapApprovalStepsResponse(steps []*entity.ApprovalStep) {
+	for _, step := range steps {
+		r.Approvals = append(r.Approvals, &ApprovalStepResponse{
+			StepOrder:          step.StepOrder,
+			ApproverKind:       string(step.ApproverKind),
+			Status:             string(step.Status),
+			ApproverId:         step.ApproverId,
+			ApproverName:       step.ApproverName,
+			OnBehalfOfId:       step.OnBehalfOfId,
+			OnBehalfOfName:     step.OnBehalfOfName,
+			AutoApprovalRuleId: step.AutoApprovalRuleId,
+			Decision:           describeDecision(step),
+			Comment:            step.Comment,
+			DecidedAt:          step.DecidedAt,
+		})
+	}
+}
+
+// describeDecision summarises a decided step, e.g. "approved by Jane Doe on behalf of John Roe"
+// or "approved automatically by Single sick day".
+func describeDecision(step *entity.ApprovalStep) string {
+	if step.AutoApprovalRuleId != nil && step.Status == entity.StepApproved {
+		return fmt.Sprintf("approved automatically by %s", step.ApproverName)
+	}
+	if step.ApproverId == nil || (step.Status != entity.StepApproved && step.Status != entity.StepRejected) {
+		return ""
+	}
+
+	decision := fmt.Sprintf("%s by %s", step.Status, step.ApproverName)
+	if step.OnBehalfOfId != nil {
+		decision += fmt.Sprintf(" on behalf of %s", step.OnBehalfOfName)
+	}
+
+	return decision
+}
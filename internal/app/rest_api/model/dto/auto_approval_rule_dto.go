@@ -0,0 +1,89 @@
+package dto
+
+import (
+	entity "github.com/devonLoen/leave-request-service/internal/app/rest_api/entity"
+)
+
+type AutoApprovalRuleResponse struct {
+	ID              int      `json:"id"`
+	Name            string   `json:"name"`
+	LeaveType       *string  `json:"leaveType"`
+	Role            *string  `json:"role"`
+	Department      string   `json:"department"`
+	MaxWorkingDays  *float64 `json:"maxWorkingDays"`
+	MinNoticeDays   *int     `json:"minNoticeDays"`
+	MinBalanceAfter *float64 `json:"minBalanceAfter"`
+	MaxTeamAbsent   *int     `json:"maxTeamAbsent"`
+	Active          bool     `json:"active"`
+}
+
+type GetAutoApprovalRulesResponse struct {
+	Rules []*AutoApprovalRuleResponse `json:"rules"`
+}
+
+// AutoApprovalRuleRequest creates or replaces an auto-approval rule. Leaving a condition out
+// does not check it.
+type AutoApprovalRuleRequest struct {
+	Name            string   `json:"name" validate:"required,max=100"`
+	LeaveType       string   `json:"leaveType" validate:"omitempty,max=30"`
+	Role            string   `json:"role" validate:"omitempty,oneof=superadmin admin employee"`
+	Department      string   `json:"department" validate:"omitempty,max=100"`
+	MaxWorkingDays  *float64 `json:"maxWorkingDays" validate:"omitempty,gt=0,max=366"`
+	MinNoticeDays   *int     `json:"minNoticeDays" validate:"omitempty,min=0,max=365"`
+	MinBalanceAfter *float64 `json:"minBalanceAfter" validate:"omitempty,min=-366,max=366"`
+	MaxTeamAbsent   *int     `json:"maxTeamAbsent" validate:"omitempty,min=0,max=1000"`
+	Active          *bool    `json:"active" validate:"required"`
+}
+
+func (r *GetAutoApprovalRulesResponse) MapAutoApprovalRulesResponse(rules []*entity.AutoApprovalRule) {
+	r.Rules = []*AutoApprovalRuleResponse{}
+	for _, rule := range rules {
+		ruleResponse := &AutoApprovalRuleResponse{}
+		ruleResponse.MapAutoApprovalRuleResponse(rule)
+		r.Rules = append(r.Rules, ruleResponse)
+	}
+}
+
+func (r *AutoApprovalRuleResponse) MapAutoApprovalRuleResponse(rule *entity.AutoApprovalRule) {
+	r.ID = rule.ID
+	r.Name = rule.Name
+	r.LeaveType = nil
+	if rule.LeaveType != nil {
+		leaveType := string(*rule.LeaveType)
+		r.LeaveType = &leaveType
+	}
+	r.Role = nil
+	if rule.Role != nil {
+		role := string(*rule.Role)
+		r.Role = &role
+	}
+	r.Department = rule.Department
+	r.MaxWorkingDays = rule.MaxWorkingDays
+	r.MinNoticeDays = rule.MinNoticeDays
+	r.MinBalanceAfter = rule.MinBalanceAfter
+	r.MaxTeamAbsent = rule.MaxTeamAbsent
+	r.Active = rule.Active
+}
+
+func (r *AutoApprovalRuleRequest) ToAutoApprovalRule() *entity.AutoApprovalRule {
+	rule := &entity.AutoApprovalRule{
+		Name:            r.Name,
+		Department:      r.Department,
+		MaxWorkingDays:  r.MaxWorkingDays,
+		MinNoticeDays:   r.MinNoticeDays,
+		MinBalanceAfter: r.MinBalanceAfter,
+		MaxTeamAbsent:   r.MaxTeamAbsent,
+		Active:          *r.Active,
+	}
+
+	if r.LeaveType != "" {
+		leaveType := entity.LeaveRequestType(r.LeaveType)
+		rule.LeaveType = &leaveType
+	}
+	if r.Role != "" {
+		role := entity.UserRole(r.Role)
+		rule.Role = &role
+	}
+
+	return rule
+}
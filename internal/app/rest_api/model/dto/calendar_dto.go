@@ -0,0 +1,69 @@
+package dto
+
+import (
+	"time"
+
+	entity "github.com/devonLoen/leave-request-service/internal/app/rest_api/entity"
+)
+
+// CalendarAbsenceResponse is one request taking time off on a calendar day. StartTime and
+// EndTime are only set for hourly leave.
+type CalendarAbsenceResponse struct {
+	LeaveRequestID int    `json:"leaveRequestId"`
+	UserID         int    `json:"userId"`
+	FullName       string `json:"fullName"`
+	Type           string `json:"type"`
+	Status         string `json:"status"`
+	Portion        string `json:"portion"`
+	StartTime      string `json:"startTime,omitempty"`
+	EndTime        string `json:"endTime,omitempty"`
+}
+
+// CalendarDayResponse counts every user with at least one absence on the day once.
+type CalendarDayResponse struct {
+	Date        string                     `json:"date"`
+	WorkingDay  bool                       `json:"workingDay"`
+	AbsentCount int                        `json:"absentCount"`
+	Absences    []*CalendarAbsenceResponse `json:"absences"`
+}
+
+type GetCalendarResponse struct {
+	From string                 `json:"from"`
+	To   string                 `json:"to"`
+	Days []*CalendarDayResponse `json:"days"`
+}
+
+func (r *CalendarDayResponse) MapCalendarDayResponse(day time.Time, workingDay bool) {
+	r.Date = day.Format(time.DateOnly)
+	r.WorkingDay = workingDay
+	r.Absences = []*CalendarAbsenceResponse{}
+}
+
+func (r *CalendarAbsenceResponse) MapCalendarAbsenceResponse(absence *entity.Absence, portion entity.AbsencePortion) {
+	r.LeaveRequestID = absence.ID
+	r.UserID = absence.UserId
+	r.FullName = absence.FullName
+	r.Type = string(absence.Type)
+	r.Status = string(absence.Status)
+	r.Portion = string(portion)
+	if portion == entity.PortionHours {
+		r.StartTime = absence.PeriodStart.Format("15:04")
+		r.EndTime = absence.PeriodEnd.Format("15:04")
+	}
+}
+
+// CalendarFeedTokenResponse is the only time a feed token is shown; it is stored hashed.
+// The feed URLs are paths on this API that calendar clients can subscribe to.
+type CalendarFeedTokenResponse struct {
+	Token       string `json:"token"`
+	UserFeedURL string `json:"userFeedUrl"`
+	TeamFeedURL string `json:"teamFeedUrl"`
+	Message     string `json:"message"`
+}
+
+func (r *CalendarFeedTokenResponse) MapCalendarFeedTokenResponse(token string) {
+	r.Token = token
+	r.UserFeedURL = "/api/v1/calendar-feeds/" + token + "/me.ics"
+	r.TeamFeedURL = "/api/v1/calendar-feeds/" + token + "/team.ics"
+	r.Message = "Calendar feed token created. Any previous token no longer works."
+}
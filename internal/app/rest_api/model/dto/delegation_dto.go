@@ -0,0 +1,49 @@
+package dto
+
+import (
+	"time"
+
+	entity "github.com/devonLoen/leave-request-service/internal/app/rest_api/entity"
+)
+
+type DelegationResponse struct {
+	ID         int       `json:"id"`
+	DelegateId int       `json:"delegateId"`
+	StartDate  time.Time `json:"startDate"`
+	EndDate    time.Time `json:"endDate"`
+}
+
+type GetDelegationsResponse struct {
+	Delegations []*DelegationResponse `json:"delegations"`
+}
+
+type CreateDelegationRequest struct {
+	DelegateId int       `json:"delegateId" validate:"required,min=1"`
+	StartDate  time.Time `json:"startDate" validate:"required"`
+	EndDate    time.Time `json:"endDate" validate:"required"`
+}
+
+func (r *GetDelegationsResponse) MapDelegationsResponse(delegations []*entity.ApprovalDelegation) {
+	r.Delegations = []*DelegationResponse{}
+	for _, delegation := range delegations {
+		delegationResponse := &DelegationResponse{}
+		delegationResponse.MapDelegationResponse(delegation)
+		r.Delegations = append(r.Delegations, delegationResponse)
+	}
+}
+
+func (r *DelegationResponse) MapDelegationResponse(delegation *entity.ApprovalDelegation) {
+	r.ID = delegation.ID
+	r.DelegateId = delegation.DelegateId
+	r.StartDate = delegation.StartDate
+	r.EndDate = delegation.EndDate
+}
+
+func (r *CreateDelegationRequest) ToDelegation(delegatorId int) *entity.ApprovalDelegation {
+	return &entity.ApprovalDelegation{
+		DelegatorId: delegatorId,
+		DelegateId:  r.DelegateId,
+		StartDate:   time.Date(r.StartDate.Year(), r.StartDate.Month(), r.StartDate.Day(), 0, 0, 0, 0, time.UTC),
+		EndDate:     time.Date(r.EndDate.Year(), r.EndDate.Month(), r.EndDate.Day(), 0, 0, 0, 0, time.UTC),
+	}
+}
@@ -0,0 +1,52 @@
+package dto
+
+import (
+	"time"
+
+	entity "github.com/devonLoen/leave-request-service/internal/app/rest_api/entity"
+)
+
+type HolidayResponse struct {
+	ID   int    `json:"id"`
+	Date string `json:"date"`
+	Name string `json:"name"`
+}
+
+type GetHolidaysResponse struct {
+	Holidays []*HolidayResponse `json:"holidays"`
+}
+
+type HolidayRequest struct {
+	Date string `json:"date" validate:"required,datetime=2006-01-02"`
+	Name string `json:"name" validate:"required,min=2,max=150"`
+}
+
+type ImportHolidaysResponse struct {
+	Imported int    `json:"imported"`
+	Skipped  int    `json:"skipped"`
+	Message  string `json:"message"`
+}
+
+func (r *GetHolidaysResponse) MapHolidaysResponse(holidays []*entity.Holiday) {
+	r.Holidays = []*HolidayResponse{}
+	for _, holiday := range holidays {
+		holidayResponse := &HolidayResponse{}
+		holidayResponse.MapHolidayResponse(holiday)
+		r.Holidays = append(r.Holidays, holidayResponse)
+	}
+}
+
+func (r *HolidayResponse) MapHolidayResponse(holiday *entity.Holiday) {
+	r.ID = holiday.ID
+	r.Date = holiday.Date.Format(time.DateOnly)
+	r.Name = holiday.Name
+}
+
+func (r *HolidayRequest) ToHoliday() *entity.Holiday {
+	date, _ := time.Parse(time.DateOnly, r.Date)
+
+	return &entity.Holiday{
+		Date: date,
+		Name: r.Name,
+	}
+}
@@ -0,0 +1,75 @@
+package dto
+
+import (
+	entity "github.com/devonLoen/leave-request-service/internal/app/rest_api/entity"
+)
+
+type LeaveBalanceResponse struct {
+	LeaveYear int     `json:"leaveYear"`
+	Type      string  `json:"type"`
+	Entitled  float64 `json:"entitled"`
+	Used      float64 `json:"used"`
+	Reserved  float64 `json:"reserved"`
+	Available float64 `json:"available"`
+}
+
+type GetBalancesResponse struct {
+	Balances []*LeaveBalanceResponse `json:"balances"`
+}
+
+type CreateBalanceEntryRequest struct {
+	LeaveYear int     `json:"leaveYear" validate:"required,min=2000,max=2100"`
+	Type      string  `json:"type" validate:"required,max=30"`
+	EntryType string  `json:"entryType" validate:"required,oneof=credit debit"`
+	Days      float64 `json:"days" validate:"required,gt=0,max=366"`
+	Note      string  `json:"note" validate:"max=255"`
+}
+
+type CreateBalanceEntryResponse struct {
+	ID        int     `json:"id"`
+	UserId    int     `json:"userId"`
+	LeaveYear int     `json:"leaveYear"`
+	Type      string  `json:"type"`
+	EntryType string  `json:"entryType"`
+	Days      float64 `json:"days"`
+	Note      string  `json:"note"`
+	Message   string  `json:"message"`
+}
+
+func (r *GetBalancesResponse) MapBalancesResponse(balances []*entity.LeaveBalance) {
+	r.Balances = []*LeaveBalanceResponse{}
+	for _, balance := range balances {
+		r.Balances = append(r.Balances, &LeaveBalanceResponse{
+			LeaveYear: balance.LeaveYear,
+			Type:      string(balance.Type),
+			Entitled:  balance.Entitled,
+			Used:      balance.Used,
+			Reserved:  balance.Reserved,
+			Available: balance.Available(),
+		})
+	}
+}
+
+func (r *CreateBalanceEntryRequest) ToLeaveBalanceEntry(userId int) *entity.LeaveBalanceEntry {
+	return &entity.LeaveBalanceEntry{
+		UserId:    userId,
+		LeaveYear: r.LeaveYear,
+		Type:      entity.LeaveRequestType(r.Type),
+		EntryType: entity.BalanceEntryType(r.EntryType),
+		Days:      r.Days,
+		Note:      r.Note,
+	}
+}
+
+func (r *CreateBalanceEntryResponse) FromLeaveBalanceEntry(entry *entity.LeaveBalanceEntry) *CreateBalanceEntryResponse {
+	return &CreateBalanceEntryResponse{
+		ID:        entry.ID,
+		UserId:    entry.UserId,
+		LeaveYear: entry.LeaveYear,
+		Type:      string(entry.Type),
+		EntryType: string(entry.EntryType),
+		Days:      entry.Days,
+		Note:      entry.Note,
+		Message:   "Balance entry created successfully.",
+	}
+}
@@ -0,0 +1,118 @@
+package dto
+
+import (
+	"time"
+
+	entity "github.com/devonLoen/leave-request-service/internal/app/rest_api/entity"
+)
+
+type LeavePolicyResponse struct {
+	ID                 int      `json:"id"`
+	Name               string   `json:"name"`
+	LeaveType          string   `json:"leaveType"`
+	Role               *string  `json:"role"`
+	Department         string   `json:"department"`
+	MinTenureMonths    int      `json:"minTenureMonths"`
+	MaxTenureMonths    *int     `json:"maxTenureMonths"`
+	EffectiveFrom      string   `json:"effectiveFrom"`
+	EffectiveTo        *string  `json:"effectiveTo"`
+	Entitled           bool     `json:"entitled"`
+	DaysPerYear        *float64 `json:"daysPerYear"`
+	MaxConsecutiveDays *float64 `json:"maxConsecutiveDays"`
+	MinNoticeDays      *int     `json:"minNoticeDays"`
+}
+
+type GetLeavePoliciesResponse struct {
+	Policies []*LeavePolicyResponse `json:"policies"`
+}
+
+// LeavePolicyRequest creates or replaces a leave policy. Leaving role or department out
+// matches every user; leaving a rule out keeps the one of the leave type.
+type LeavePolicyRequest struct {
+	Name               string   `json:"name" validate:"required,max=100"`
+	LeaveType          string   `json:"leaveType" validate:"required,max=30"`
+	Role               string   `json:"role" validate:"omitempty,oneof=superadmin admin employee"`
+	Department         string   `json:"department" validate:"omitempty,max=100"`
+	MinTenureMonths    int      `json:"minTenureMonths" validate:"min=0,max=600"`
+	MaxTenureMonths    *int     `json:"maxTenureMonths" validate:"omitempty,min=1,max=600"`
+	EffectiveFrom      string   `json:"effectiveFrom" validate:"required,datetime=2006-01-02"`
+	EffectiveTo        string   `json:"effectiveTo" validate:"omitempty,datetime=2006-01-02"`
+	Entitled           *bool    `json:"entitled" validate:"required"`
+	DaysPerYear        *float64 `json:"daysPerYear" validate:"omitempty,min=0,max=366"`
+	MaxConsecutiveDays *float64 `json:"maxConsecutiveDays" validate:"omitempty,gt=0,max=366"`
+	MinNoticeDays      *int     `json:"minNoticeDays" validate:"omitempty,min=0,max=365"`
+}
+
+func (r *GetLeavePoliciesResponse) MapLeavePoliciesResponse(policies []*entity.LeavePolicy) {
+	r.Policies = []*LeavePolicyResponse{}
+	for _, policy := range policies {
+		policyResponse := &LeavePolicyResponse{}
+		policyResponse.MapLeavePolicyResponse(policy)
+		r.Policies = append(r.Policies, policyResponse)
+	}
+}
+
+func (r *LeavePolicyResponse) MapLeavePolicyResponse(policy *entity.LeavePolicy) {
+	r.ID = policy.ID
+	r.Name = policy.Name
+	r.LeaveType = string(policy.LeaveType)
+	r.Role = nil
+	if policy.Role != nil {
+		role := string(*policy.Role)
+		r.Role = &role
+	}
+	r.Department = policy.Department
+	r.MinTenureMonths = policy.MinTenureMonths
+	r.MaxTenureMonths = policy.MaxTenureMonths
+	r.EffectiveFrom = policy.EffectiveFrom.Format(time.DateOnly)
+	r.EffectiveTo = nil
+	if policy.EffectiveTo != nil {
+		effectiveTo := policy.EffectiveTo.Format(time.DateOnly)
+		r.EffectiveTo = &effectiveTo
+	}
+	r.Entitled = policy.Entitled
+	r.DaysPerYear = policy.DaysPerYear
+	r.MaxConsecutiveDays = policy.MaxConsecutiveDays
+	r.MinNoticeDays = policy.MinNoticeDays
+}
+
+// ValidateRanges checks the bounds the struct tags cannot compare. It returns the offending
+// fields with their messages.
+func (r *LeavePolicyRequest) ValidateRanges() map[string]string {
+	out := make(map[string]string)
+
+	if r.MaxTenureMonths != nil && *r.MaxTenureMonths <= r.MinTenureMonths {
+		out["maxTenureMonths"] = "maxTenureMonths must be greater than minTenureMonths"
+	}
+	if r.EffectiveTo != "" && r.EffectiveTo < r.EffectiveFrom {
+		out["effectiveTo"] = "effectiveTo must not be before effectiveFrom"
+	}
+
+	return out
+}
+
+func (r *LeavePolicyRequest) ToLeavePolicy() *entity.LeavePolicy {
+	policy := &entity.LeavePolicy{
+		Name:               r.Name,
+		LeaveType:          entity.LeaveRequestType(r.LeaveType),
+		Department:         r.Department,
+		MinTenureMonths:    r.MinTenureMonths,
+		MaxTenureMonths:    r.MaxTenureMonths,
+		Entitled:           *r.Entitled,
+		DaysPerYear:        r.DaysPerYear,
+		MaxConsecutiveDays: r.MaxConsecutiveDays,
+		MinNoticeDays:      r.MinNoticeDays,
+	}
+
+	if r.Role != "" {
+		role := entity.UserRole(r.Role)
+		policy.Role = &role
+	}
+	policy.EffectiveFrom, _ = time.Parse(time.DateOnly, r.EffectiveFrom)
+	if r.EffectiveTo != "" {
+		effectiveTo, _ := time.Parse(time.DateOnly, r.EffectiveTo)
+		policy.EffectiveTo = &effectiveTo
+	}
+
+	return policy
+}
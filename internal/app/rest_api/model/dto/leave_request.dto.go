@@ -1,18 +1,33 @@
 package dto
 
 import (
+	"fmt"
 	"time"
 
 	entity "github.com/devonLoen/leave-request-service/internal/app/rest_api/entity"
 )
 
 type LeaveRequestResponse struct {
-	ID        int       `json:"id"`
-	StartDate time.Time `json:"startDate"`
-	EndDate   time.Time `json:"endDate"`
-	Type      string    `json:"type"`
-	Status    string    `json:"status"`
-	Reason    string    `json:"reason"`
+	ID           int       `json:"id"`
+	StartDate    time.Time `json:"startDate"`
+	EndDate      time.Time `json:"endDate"`
+	DurationUnit string    `json:"durationUnit"`
+	StartSession string    `json:"startSession,omitempty"`
+	EndSession   string    `json:"endSession,omitempty"`
+	StartTime    string    `json:"startTime,omitempty"`
+	EndTime      string    `json:"endTime,omitempty"`
+	WorkingDays  float64   `json:"workingDays"`
+	Type         string    `json:"type"`
+	Status       string    `json:"status"`
+	Reason       string    `json:"reason"`
+
+	DecidedBy       *int       `json:"decidedBy,omitempty"`
+	DecidedAt       *time.Time `json:"decidedAt,omitempty"`
+	RejectionReason string     `json:"rejectionReason,omitempty"`
+	ApprovalComment string     `json:"approvalComment,omitempty"`
+	Version         int        `json:"version"`
+
+	Approvals []*ApprovalStepResponse `json:"approvals,omitempty"`
 }
 
 type GetAllLeaveRequestsResponse struct {
@@ -20,32 +35,80 @@ type GetAllLeaveRequestsResponse struct {
 }
 
 type CreateLeaveRequestRequest struct {
-	StartDate time.Time `json:"startDate" validate:"required"`
-	EndDate   time.Time `json:"endDate" validate:"required"`
-	Type      string    `json:"type" validate:"required,oneof=annual sick unpaid"`
-	Reason    string    `json:"reason" validate:"required,min=10,max=500"`
-	Status    string    `json:"status" validate:"required,oneof=draft waiting_approval"`
+	StartDate    time.Time `json:"startDate" validate:"required"`
+	EndDate      time.Time `json:"endDate" validate:"required"`
+	DurationUnit string    `json:"durationUnit" validate:"omitempty,oneof=day hour"`
+	StartSession string    `json:"startSession" validate:"omitempty,oneof=am pm"`
+	EndSession   string    `json:"endSession" validate:"omitempty,oneof=am pm"`
+	StartTime    string    `json:"startTime" validate:"omitempty,datetime=15:04"`
+	EndTime      string    `json:"endTime" validate:"omitempty,datetime=15:04"`
+	Type         string    `json:"type" validate:"required,max=30"`
+	Reason       string    `json:"reason" validate:"required,min=10,max=500"`
+	Status       string    `json:"status" validate:"required,oneof=draft waiting_approval"`
+}
+
+type ApproveLeaveRequestRequest struct {
+	Comment string `json:"comment" validate:"max=500"`
+}
+
+type RejectLeaveRequestRequest struct {
+	Reason string `json:"reason" validate:"required,max=500"`
+}
+
+// BulkDecisionRequest approves or rejects several leave requests at once. The comment is
+// shared by all of them and, when rejecting, is the required reason.
+type BulkDecisionRequest struct {
+	Ids      []int  `json:"ids" validate:"required,min=1,max=100,dive,min=1"`
+	Decision string `json:"decision" validate:"required,oneof=approve reject"`
+	Comment  string `json:"comment" validate:"max=500"`
+}
+
+// The results of one leave request of a bulk decision.
+const (
+	BulkDecisionOK        = "ok"
+	BulkDecisionNotFound  = "not_found"
+	BulkDecisionForbidden = "forbidden"
+	BulkDecisionConflict  = "conflict"
+	BulkDecisionOverlap   = "overlap"
+	BulkDecisionInvalid   = "invalid"
+	BulkDecisionError     = "error"
+)
+
+// BulkDecisionResult is the outcome for one id. Status is the status the request ended up in
+// when the decision succeeded, Message the reason when it did not.
+type BulkDecisionResult struct {
+	ID      int    `json:"id"`
+	Result  string `json:"result"`
+	Status  string `json:"status,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+type BulkDecisionResponse struct {
+	Succeeded int                   `json:"succeeded"`
+	Failed    int                   `json:"failed"`
+	Results   []*BulkDecisionResult `json:"results"`
 }
 
 type CreateLeaveRequestResponse struct {
-	StartDate time.Time `json:"startDate" validate:"required"`
-	EndDate   time.Time `json:"endDate" validate:"required"`
-	Type      string    `json:"type" validate:"required,oneof=annual sick unpaid"`
-	Reason    string    `json:"reason" validate:"required,min=10,max=500"`
-	Status    string    `json:"status" validate:"required,oneof=draft waiting_approval"`
-	Message   string    `json:"message" binding:"required"`
+	ID           int       `json:"id"`
+	StartDate    time.Time `json:"startDate" validate:"required"`
+	EndDate      time.Time `json:"endDate" validate:"required"`
+	DurationUnit string    `json:"durationUnit"`
+	StartSession string    `json:"startSession,omitempty"`
+	EndSession   string    `json:"endSession,omitempty"`
+	StartTime    string    `json:"startTime,omitempty"`
+	EndTime      string    `json:"endTime,omitempty"`
+	WorkingDays  float64   `json:"workingDays"`
+	Type         string    `json:"type" validate:"required,max=30"`
+	Reason       string    `json:"reason" validate:"required,min=10,max=500"`
+	Status       string    `json:"status" validate:"required,oneof=draft waiting_approval"`
+	Message      string    `json:"message" binding:"required"`
 }
 
 func (r *GetAllLeaveRequestsResponse) MapLeaveRequestsResponse(leaveRequests []*entity.LeaveRequest) {
 	for _, leaveRequests := range leaveRequests {
-		leaveRequest := &LeaveRequestResponse{
-			ID:        leaveRequests.ID,
-			StartDate: leaveRequests.StartDate,
-			EndDate:   leaveRequests.EndDate,
-			Type:      string(leaveRequests.Type),
-			Status:    string(leaveRequests.Status),
-			Reason:    leaveRequests.Reason,
-		}
+		leaveRequest := &LeaveRequestResponse{}
+		leaveRequest.MapLeaveRequestResponse(leaveRequests)
 		r.LeaveRequests = append(r.LeaveRequests, leaveRequest)
 	}
 }
@@ -54,13 +117,56 @@ func (r *LeaveRequestResponse) MapLeaveRequestResponse(leaveRequest *entity.Leav
 	r.ID = leaveRequest.ID
 	r.StartDate = leaveRequest.StartDate
 	r.EndDate = leaveRequest.EndDate
+	r.DurationUnit = string(leaveRequest.DurationUnit)
+	r.StartSession, r.EndSession, r.StartTime, r.EndTime = durationFields(leaveRequest)
+	r.WorkingDays = leaveRequest.WorkingDays
 	r.Type = string(leaveRequest.Type)
 	r.Status = string(leaveRequest.Status)
 	r.Reason = leaveRequest.Reason
+	r.DecidedBy = leaveRequest.DecidedBy
+	r.DecidedAt = leaveRequest.DecidedAt
+	r.RejectionReason = leaveRequest.RejectionReason
+	r.ApprovalComment = leaveRequest.ApprovalComment
+	r.Version = leaveRequest.Version
+}
+
+// ValidateDuration checks the rules between the date, session and time fields that the
+// struct tags cannot express. It returns the offending fields with their messages.
+func (ur *CreateLeaveRequestRequest) ValidateDuration() map[string]string {
+	out := make(map[string]string)
+
+	if entity.LeaveDurationUnit(ur.DurationUnit) == entity.DurationHour {
+		if ur.StartTime == "" || ur.EndTime == "" {
+			out["startTime"] = "startTime and endTime are required for hourly leave"
+		} else if ur.StartTime >= ur.EndTime {
+			out["startTime"] = "startTime must be before endTime"
+		} else if clockOffset(ur.EndTime)-clockOffset(ur.StartTime) > entity.WorkingHoursPerDay*time.Hour {
+			out["endTime"] = fmt.Sprintf("Hourly leave cannot be longer than %d hours, request a full day instead", entity.WorkingHoursPerDay)
+		}
+		if !sameDay(ur.StartDate, ur.EndDate) {
+			out["endDate"] = "Hourly leave must start and end on the same day"
+		}
+		if ur.StartSession != "" || ur.EndSession != "" {
+			out["startSession"] = "Sessions cannot be combined with hourly leave"
+		}
+		return out
+	}
+
+	if ur.StartTime != "" || ur.EndTime != "" {
+		out["startTime"] = "startTime and endTime are only allowed for hourly leave"
+	}
+	if ur.StartDate.Year() != ur.EndDate.Year() {
+		out["endDate"] = "Leave cannot span two leave years, request each year separately"
+	}
+	if sameDay(ur.StartDate, ur.EndDate) && ur.StartSession == string(entity.SessionPM) && ur.EndSession == string(entity.SessionAM) {
+		out["endSession"] = "A single day cannot start in the afternoon and end in the morning"
+	}
+
+	return out
 }
 
 func (ur *CreateLeaveRequestRequest) ToLeaveRequest(userId int) *entity.LeaveRequest {
-	return &entity.LeaveRequest{
+	leaveRequest := &entity.LeaveRequest{
 		UserId:    userId,
 		StartDate: ur.StartDate,
 		EndDate:   ur.EndDate,
@@ -68,15 +174,74 @@ func (ur *CreateLeaveRequestRequest) ToLeaveRequest(userId int) *entity.LeaveReq
 		Status:    entity.LeaveRequestStatus(ur.Status),
 		Reason:    ur.Reason,
 	}
+
+	if entity.LeaveDurationUnit(ur.DurationUnit) == entity.DurationHour {
+		leaveRequest.SetHourPeriod(clockOffset(ur.StartTime), clockOffset(ur.EndTime))
+		return leaveRequest
+	}
+
+	startSession, endSession := entity.SessionAM, entity.SessionPM
+	if ur.StartSession != "" {
+		startSession = entity.DaySession(ur.StartSession)
+	}
+	if ur.EndSession != "" {
+		endSession = entity.DaySession(ur.EndSession)
+	}
+	leaveRequest.SetDayPeriod(startSession, endSession)
+
+	return leaveRequest
+}
+
+func sameDay(a, b time.Time) bool {
+	return a.Year() == b.Year() && a.YearDay() == b.YearDay()
+}
+
+// clockOffset converts an already validated "15:04" clock time into an offset from midnight.
+func clockOffset(clock string) time.Duration {
+	t, _ := time.Parse("15:04", clock)
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+}
+
+func durationFields(leaveRequest *entity.LeaveRequest) (startSession, endSession, startTime, endTime string) {
+	if leaveRequest.DurationUnit == entity.DurationHour {
+		return "", "", leaveRequest.PeriodStart.Format("15:04"), leaveRequest.PeriodEnd.Format("15:04")
+	}
+	return string(leaveRequest.StartSession), string(leaveRequest.EndSession), "", ""
 }
 
 func (ur *CreateLeaveRequestResponse) FromLeaveRequest(leaveRequest *entity.LeaveRequest) *CreateLeaveRequestResponse {
+	startSession, endSession, startTime, endTime := durationFields(leaveRequest)
+
 	return &CreateLeaveRequestResponse{
-		StartDate: leaveRequest.StartDate,
-		EndDate:   leaveRequest.EndDate,
-		Type:      string(leaveRequest.Type),
-		Status:    string(leaveRequest.Status),
-		Reason:    leaveRequest.Reason,
-		Message:   "Leave Request created successfully.",
+		ID:           leaveRequest.ID,
+		StartDate:    leaveRequest.StartDate,
+		EndDate:      leaveRequest.EndDate,
+		DurationUnit: string(leaveRequest.DurationUnit),
+		StartSession: startSession,
+		EndSession:   endSession,
+		StartTime:    startTime,
+		EndTime:      endTime,
+		WorkingDays:  leaveRequest.WorkingDays,
+		Type:         string(leaveRequest.Type),
+		Status:       string(leaveRequest.Status),
+		Reason:       leaveRequest.Reason,
+		Message:      "Leave Request created successfully.",
+	}
+}
+
+// LeaveRequestActionsResponse lists what the caller may do with a request in its current
+// status and version.
+type LeaveRequestActionsResponse struct {
+	Status  string   `json:"status"`
+	Version int      `json:"version"`
+	Actions []string `json:"actions"`
+}
+
+func (r *LeaveRequestActionsResponse) MapLeaveRequestActionsResponse(leaveRequest *entity.LeaveRequest, transitions []entity.LeaveRequestTransition) {
+	r.Status = string(leaveRequest.Status)
+	r.Version = leaveRequest.Version
+	r.Actions = []string{}
+	for _, transition := range transitions {
+		r.Actions = append(r.Actions, string(transition))
 	}
 }
@@ -0,0 +1,38 @@
+package dto
+
+import (
+	"time"
+
+	entity "github.com/devonLoen/leave-request-service/internal/app/rest_api/entity"
+)
+
+type AttachmentResponse struct {
+	ID          int       `json:"id"`
+	FileName    string    `json:"fileName"`
+	ContentType string    `json:"contentType"`
+	Size        int64     `json:"size"`
+	UploadedBy  int       `json:"uploadedBy"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+type GetAttachmentsResponse struct {
+	Attachments []*AttachmentResponse `json:"attachments"`
+}
+
+func (r *AttachmentResponse) MapAttachmentResponse(attachment *entity.LeaveRequestAttachment) {
+	r.ID = attachment.ID
+	r.FileName = attachment.FileName
+	r.ContentType = attachment.ContentType
+	r.Size = attachment.Size
+	r.UploadedBy = attachment.UploadedBy
+	r.CreatedAt = attachment.CreatedAt
+}
+
+func (r *GetAttachmentsResponse) MapAttachmentsResponse(attachments []*entity.LeaveRequestAttachment) {
+	r.Attachments = []*AttachmentResponse{}
+	for _, attachment := range attachments {
+		attachmentResponse := &AttachmentResponse{}
+		attachmentResponse.MapAttachmentResponse(attachment)
+		r.Attachments = append(r.Attachments, attachmentResponse)
+	}
+}
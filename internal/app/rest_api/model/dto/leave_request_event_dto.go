@@ -0,0 +1,40 @@
+package dto
+
+import (
+	"time"
+
+	entity "github.com/devonLoen/leave-request-service/internal/app/rest_api/entity"
+)
+
+type LeaveRequestEventResponse struct {
+	ID                 int            `json:"id"`
+	Action             string         `json:"action"`
+	ActorId            *int           `json:"actorId"`
+	AutoApprovalRuleId *int           `json:"autoApprovalRuleId,omitempty"`
+	ActorName          string         `json:"actorName,omitempty"`
+	PreviousStatus     string         `json:"previousStatus,omitempty"`
+	NewStatus          string         `json:"newStatus,omitempty"`
+	Payload            map[string]any `json:"payload,omitempty"`
+	CreatedAt          time.Time      `json:"createdAt"`
+}
+
+type GetLeaveRequestHistoryResponse struct {
+	Events []*LeaveRequestEventResponse `json:"events"`
+}
+
+func (r *GetLeaveRequestHistoryResponse) MapLeaveRequestEventsResponse(events []*entity.LeaveRequestEvent) {
+	r.Events = []*LeaveRequestEventResponse{}
+	for _, event := range events {
+		r.Events = append(r.Events, &LeaveRequestEventResponse{
+			ID:                 event.ID,
+			Action:             string(event.Action),
+			ActorId:            event.ActorId,
+			AutoApprovalRuleId: event.AutoApprovalRuleId,
+			ActorName:          event.ActorName,
+			PreviousStatus:     string(event.PreviousStatus),
+			NewStatus:          string(event.NewStatus),
+			Payload:            event.Payload,
+			CreatedAt:          event.CreatedAt,
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package dto
+
+import (
+	entity "github.com/devonLoen/leave-request-service/internal/app/rest_api/entity"
+)
+
+type LeaveTypeResponse struct {
+	Code                string   `json:"code"`
+	Name                string   `json:"name"`
+	Paid                bool     `json:"paid"`
+	DeductsBalance      bool     `json:"deductsBalance"`
+	RequiresAttachment  bool     `json:"requiresAttachment"`
+	AttachmentAfterDays float64  `json:"attachmentAfterDays"`
+	MaxConsecutiveDays  *float64 `json:"maxConsecutiveDays"`
+	MinNoticeDays       int      `json:"minNoticeDays"`
+	AllowedRoles        []string `json:"allowedRoles"`
+	Active              bool     `json:"active"`
+}
+
+type GetLeaveTypesResponse struct {
+	LeaveTypes []*LeaveTypeResponse `json:"leaveTypes"`
+}
+
+// UpsertLeaveTypeRequest sets every attribute of a leave type. An empty allowedRoles lets
+// every role request it, and a missing maxConsecutiveDays removes the cap.
+type UpsertLeaveTypeRequest struct {
+	Name                string   `json:"name" validate:"required,max=100"`
+	Paid                bool     `json:"paid"`
+	DeductsBalance      bool     `json:"deductsBalance"`
+	RequiresAttachment  bool     `json:"requiresAttachment"`
+	AttachmentAfterDays float64  `json:"attachmentAfterDays" validate:"min=0,max=366"`
+	MaxConsecutiveDays  *float64 `json:"maxConsecutiveDays" validate:"omitempty,gt=0,max=366"`
+	MinNoticeDays       int      `json:"minNoticeDays" validate:"min=0,max=365"`
+	AllowedRoles        []string `json:"allowedRoles" validate:"max=3,dive,oneof=superadmin admin employee"`
+	Active              bool     `json:"active"`
+}
+
+func (r *GetLeaveTypesResponse) MapLeaveTypesResponse(leaveTypes []*entity.LeaveType) {
+	r.LeaveTypes = []*LeaveTypeResponse{}
+	for _, leaveType := range leaveTypes {
+		leaveTypeResponse := &LeaveTypeResponse{}
+		leaveTypeResponse.MapLeaveTypeResponse(leaveType)
+		r.LeaveTypes = append(r.LeaveTypes, leaveTypeResponse)
+	}
+}
+
+func (r *LeaveTypeResponse) MapLeaveTypeResponse(leaveType *entity.LeaveType) {
+	r.Code = string(leaveType.Code)
+	r.Name = leaveType.Name
+	r.Paid = leaveType.Paid
+	r.DeductsBalance = leaveType.DeductsBalance
+	r.RequiresAttachment = leaveType.RequiresAttachment
+	r.AttachmentAfterDays = leaveType.AttachmentAfterDays
+	r.MaxConsecutiveDays = leaveType.MaxConsecutiveDays
+	r.MinNoticeDays = leaveType.MinNoticeDays
+	r.AllowedRoles = make([]string, 0, len(leaveType.AllowedRoles))
+	for _, role := range leaveType.AllowedRoles {
+		r.AllowedRoles = append(r.AllowedRoles, string(role))
+	}
+	r.Active = leaveType.Active
+}
+
+func (r *UpsertLeaveTypeRequest) ToLeaveType(code entity.LeaveRequestType) *entity.LeaveType {
+	roles := make([]entity.UserRole, 0, len(r.AllowedRoles))
+	for _, role := range r.AllowedRoles {
+		roles = append(roles, entity.UserRole(role))
+	}
+
+	return &entity.LeaveType{
+		Code:                code,
+		Name:                r.Name,
+		Paid:                r.Paid,
+		DeductsBalance:      r.DeductsBalance,
+		RequiresAttachment:  r.RequiresAttachment,
+		AttachmentAfterDays: r.AttachmentAfterDays,
+		MaxConsecutiveDays:  r.MaxConsecutiveDays,
+		MinNoticeDays:       r.MinNoticeDays,
+		AllowedRoles:        roles,
+		Active:              r.Active,
+	}
+}
@@ -1,12 +1,20 @@
 package dto
 
-import entity "github.com/devonLoen/leave-request-service/internal/app/rest_api/entity"
+import (
+	"time"
+
+	entity "github.com/devonLoen/leave-request-service/internal/app/rest_api/entity"
+	"github.com/devonLoen/leave-request-service/internal/app/rest_api/pkg/util"
+)
 
 type UserResponse struct {
-	ID       int    `json:"id"`
-	FullName string `json:"fullName"`
-	Email    string `json:"email"`
-	Role     string `json:"role"`
+	ID         int    `json:"id"`
+	FullName   string `json:"fullName"`
+	Email      string `json:"email"`
+	Role       string `json:"role"`
+	ManagerId  *int   `json:"managerId"`
+	Department string `json:"department"`
+	HireDate   string `json:"hireDate"`
 }
 
 type GetAllUsersResponse struct {
@@ -14,9 +22,16 @@ type GetAllUsersResponse struct {
 }
 
 type CreateUserRequest struct {
-	FullName string `json:"fullName" binding:"required,min=3,max=50"`
-	Email    string `json:"email" binding:"required,email,max=254"`
-	Role     string `json:"role" binding:"required,oneof=superadmin admin employee"`
+	FullName   string `json:"fullName" validate:"required,min=3,max=50"`
+	Email      string `json:"email" validate:"required,email,max=254"`
+	Role       string `json:"role" validate:"required,oneof=superadmin admin employee"`
+	Department string `json:"department" validate:"omitempty,max=100"`
+	// HireDate defaults to the day the user is created.
+	HireDate string `json:"hireDate" validate:"omitempty,datetime=2006-01-02"`
+}
+
+type SetManagerRequest struct {
+	ManagerId *int `json:"managerId" validate:"omitempty,min=1"`
 }
 
 type CreateUserResponse struct {
@@ -26,13 +41,34 @@ type CreateUserResponse struct {
 	Message  string `json:"message" binding:"required"`
 }
 
+// ImportUserRowResponse reports one data row of an import; Row is its line in the file.
+// Status is "created", "valid" on a dry run, or "invalid" with the reasons in Errors.
+type ImportUserRowResponse struct {
+	Row    int               `json:"row"`
+	Email  string            `json:"email"`
+	Status string            `json:"status"`
+	Errors map[string]string `json:"errors,omitempty"`
+}
+
+type ImportUsersResponse struct {
+	DryRun  bool                     `json:"dryRun"`
+	Created int                      `json:"created"`
+	Valid   int                      `json:"valid"`
+	Invalid int                      `json:"invalid"`
+	Rows    []*ImportUserRowResponse `json:"rows"`
+	Message string                   `json:"message"`
+}
+
 func (r *GetAllUsersResponse) MapUsersResponse(users []*entity.User) {
 	for _, users := range users {
 		user := &UserResponse{
-			ID:       users.ID,
-			FullName: users.FullName,
-			Email:    users.Email,
-			Role:     string(users.Role),
+			ID:         users.ID,
+			FullName:   users.FullName,
+			Email:      users.Email,
+			Role:       string(users.Role),
+			ManagerId:  users.ManagerId,
+			Department: users.Department,
+			HireDate:   users.HireDate.Format(time.DateOnly),
 		}
 		r.Users = append(r.Users, user)
 	}
@@ -43,13 +79,23 @@ func (r *UserResponse) MapUserResponse(user *entity.User) {
 	r.FullName = user.FullName
 	r.Email = user.Email
 	r.Role = string(user.Role)
+	r.ManagerId = user.ManagerId
+	r.Department = user.Department
+	r.HireDate = user.HireDate.Format(time.DateOnly)
 }
 
 func (ur *CreateUserRequest) ToUser() *entity.User {
+	hireDate := util.DateOnly(time.Now())
+	if ur.HireDate != "" {
+		hireDate, _ = time.Parse(time.DateOnly, ur.HireDate)
+	}
+
 	return &entity.User{
-		FullName: ur.FullName,
-		Email:    ur.Email,
-		Role:     entity.UserRole(ur.Role),
+		FullName:   ur.FullName,
+		Email:      ur.Email,
+		Role:       entity.UserRole(ur.Role),
+		Department: ur.Department,
+		HireDate:   hireDate,
 	}
 }
 
@@ -3,4 +3,7 @@ package model
 type ErrorResponse struct {
 	Code    int    `json:"code"`
 	Message string `json:"message"`
+	// Reason tells apart errors that share a status code, so callers do not have to match
+	// on the message. It is not sent to clients.
+	Reason string `json:"-"`
 }
@@ -0,0 +1,34 @@
+package storage_test
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/devonLoen/leave-request-service/internal/app/rest_api/pkg/storage"
+)
+
+func TestLocalStorage(t *testing.T) {
+	ctx := context.Background()
+	local, err := storage.NewLocalStorage(t.TempDir())
+	assert.NoError(t, err)
+
+	err = local.Put(ctx, "leave-requests/7/abc", strings.NewReader("certificate"), 11, "application/pdf")
+	assert.NoError(t, err)
+
+	content, err := local.Open(ctx, "leave-requests/7/abc")
+	assert.NoError(t, err)
+	body, _ := io.ReadAll(content)
+	content.Close()
+	assert.Equal(t, "certificate", string(body))
+
+	assert.NoError(t, local.Delete(ctx, "leave-requests/7/abc"))
+	_, err = local.Open(ctx, "leave-requests/7/abc")
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+
+	err = local.Put(ctx, "../outside", strings.NewReader("x"), 1, "text/plain")
+	assert.Error(t, err)
+}
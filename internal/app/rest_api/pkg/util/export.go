@@ -0,0 +1,84 @@
+package util
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ExportFormat is a spreadsheet format reports can be downloaded in.
+type ExportFormat string
+
+const (
+	ExportCSV  ExportFormat = "csv"
+	ExportXLSX ExportFormat = "xlsx"
+)
+
+func (f ExportFormat) IsValid() bool {
+	switch f {
+	case ExportCSV, ExportXLSX:
+		return true
+	}
+	return false
+}
+
+func (f ExportFormat) ContentType() string {
+	if f == ExportXLSX {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// RowWriter writes a spreadsheet one row at a time, so it can be streamed. Cells are strings
+// or numbers; Close must be called to complete the file.
+type RowWriter interface {
+	WriteRow(cells ...any) error
+	Close() error
+}
+
+// NewRowWriter returns a RowWriter of the given format. sheet names the worksheet of XLSX files.
+func NewRowWriter(w io.Writer, format ExportFormat, sheet string) (RowWriter, error) {
+	switch format {
+	case ExportCSV:
+		return &csvRowWriter{w: csv.NewWriter(w)}, nil
+	case ExportXLSX:
+		return newXLSXRowWriter(w, sheet)
+	}
+	return nil, fmt.Errorf("unsupported export format %q", format)
+}
+
+type csvRowWriter struct {
+	w *csv.Writer
+}
+
+func (c *csvRowWriter) WriteRow(cells ...any) error {
+	record := make([]string, len(cells))
+	for i, cell := range cells {
+		record[i] = csvCell(cell)
+	}
+	return c.w.Write(record)
+}
+
+func (c *csvRowWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// csvCell formats a cell. Text that a spreadsheet would read as a formula is prefixed with a
+// quote, so a leave reason cannot inject one into the payroll sheet.
+func csvCell(cell any) string {
+	switch v := cell.(type) {
+	case string:
+		if v != "" && strings.ContainsRune("=+-@\t\r", rune(v[0])) {
+			return "'" + v
+		}
+		return v
+	case int:
+		return strconv.Itoa(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return fmt.Sprint(cell)
+}
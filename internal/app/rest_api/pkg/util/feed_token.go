@@ -0,0 +1,27 @@
+package util
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+)
+
+const feedTokenBytes = 32
+
+// GenerateFeedToken returns a random, URL-safe token for calendar feed URLs.
+func GenerateFeedToken() (string, error) {
+	token := make([]byte, feedTokenBytes)
+	if _, err := rand.Read(token); err != nil {
+		return "", errors.New("secure token generation failed")
+	}
+	return base64.RawURLEncoding.EncodeToString(token), nil
+}
+
+// HashFeedToken returns the hex SHA-256 hash a feed token is stored and looked up by. The
+// tokens are random, so a slow password hash is not needed.
+func HashFeedToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,228 @@
+package util
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+type ICalEvent struct {
+	UID     string
+	Summary string
+	Start   time.Time
+	// End is exclusive, as in RFC 5545. For all-day events without DTEND it is the day after Start.
+	End    time.Time
+	AllDay bool
+	// Status and Sequence are only written, never parsed. Status is CONFIRMED, TENTATIVE or
+	// CANCELLED; Sequence grows with every revision of the event.
+	Status   string
+	Sequence int
+}
+
+// ICalendar is an iCalendar stream to write. Stamp is the DTSTAMP of every event.
+type ICalendar struct {
+	Name   string
+	Stamp  time.Time
+	Events []ICalEvent
+}
+
+var icalTextUnescaper = strings.NewReplacer(`\n`, "\n", `\N`, "\n", `\,`, ",", `\;`, ";", `\\`, `\`)
+
+var icalTextEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+// icalMaxLineOctets is the longest content line RFC 5545 allows before it must be folded.
+const icalMaxLineOctets = 75
+
+// ParseICalEvents reads the VEVENT components of an iCalendar (RFC 5545) stream.
+// Only the properties needed to import holidays are interpreted.
+func ParseICalEvents(r io.Reader) ([]ICalEvent, error) {
+	lines, err := unfoldICalLines(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var events []ICalEvent
+	var current *ICalEvent
+
+	for i, line := range lines {
+		name, params, value, ok := splitICalProperty(line)
+		if !ok {
+			continue
+		}
+
+		switch {
+		case name == "BEGIN" && strings.EqualFold(value, "VEVENT"):
+			current = &ICalEvent{}
+		case name == "END" && strings.EqualFold(value, "VEVENT"):
+			if current == nil {
+				return nil, fmt.Errorf("line %d: END:VEVENT without BEGIN:VEVENT", i+1)
+			}
+			if current.Start.IsZero() {
+				return nil, fmt.Errorf("line %d: event %q has no DTSTART", i+1, current.Summary)
+			}
+			if current.End.IsZero() {
+				current.End = current.Start.AddDate(0, 0, 1)
+			}
+			events = append(events, *current)
+			current = nil
+		case current == nil:
+			continue
+		case name == "UID":
+			current.UID = value
+		case name == "SUMMARY":
+			current.Summary = icalTextUnescaper.Replace(value)
+		case name == "DTSTART", name == "DTEND":
+			t, allDay, err := parseICalTime(params, value)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", i+1, err)
+			}
+			if name == "DTSTART" {
+				current.Start, current.AllDay = t, allDay
+			} else {
+				current.End = t
+			}
+		}
+	}
+
+	if current != nil {
+		return nil, errors.New("unterminated VEVENT")
+	}
+
+	return events, nil
+}
+
+func unfoldICalLines(r io.Reader) ([]string, error) {
+	var lines []string
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+
+	return lines, scanner.Err()
+}
+
+func splitICalProperty(line string) (name string, params map[string]string, value string, ok bool) {
+	colon := strings.Index(line, ":")
+	if colon < 0 {
+		return "", nil, "", false
+	}
+
+	parts := strings.Split(line[:colon], ";")
+	params = map[string]string{}
+	for _, param := range parts[1:] {
+		if key, val, found := strings.Cut(param, "="); found {
+			params[strings.ToUpper(key)] = strings.Trim(val, `"`)
+		}
+	}
+
+	return strings.ToUpper(parts[0]), params, line[colon+1:], true
+}
+
+func parseICalTime(params map[string]string, value string) (time.Time, bool, error) {
+	if params["VALUE"] == "DATE" || len(value) == len("20060102") {
+		t, err := time.Parse("20060102", value)
+		return t, true, err
+	}
+
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse("20060102T150405Z", value)
+		return t, false, err
+	}
+
+	loc := time.UTC
+	if tzid := params["TZID"]; tzid != "" {
+		if l, err := time.LoadLocation(tzid); err == nil {
+			loc = l
+		}
+	}
+
+	t, err := time.ParseInLocation("20060102T150405", value, loc)
+	return t, false, err
+}
+
+// WriteICalendar writes cal as an iCalendar (RFC 5545) stream. Events that are not all day
+// are written in floating time, to be shown at the same wall-clock time in every time zone.
+func WriteICalendar(w io.Writer, cal ICalendar) error {
+	lines := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//Leave Request Service//Leave Calendar//EN",
+		"CALSCALE:GREGORIAN",
+		"METHOD:PUBLISH",
+	}
+	if cal.Name != "" {
+		lines = append(lines, "X-WR-CALNAME:"+icalTextEscaper.Replace(cal.Name))
+	}
+
+	for _, event := range cal.Events {
+		lines = append(lines,
+			"BEGIN:VEVENT",
+			"UID:"+event.UID,
+			"DTSTAMP:"+cal.Stamp.UTC().Format("20060102T150405Z"),
+		)
+		if event.AllDay {
+			lines = append(lines,
+				"DTSTART;VALUE=DATE:"+event.Start.Format("20060102"),
+				"DTEND;VALUE=DATE:"+event.End.Format("20060102"),
+			)
+		} else {
+			lines = append(lines,
+				"DTSTART:"+event.Start.Format("20060102T150405"),
+				"DTEND:"+event.End.Format("20060102T150405"),
+			)
+		}
+		lines = append(lines, "SUMMARY:"+icalTextEscaper.Replace(event.Summary))
+		if event.Status != "" {
+			lines = append(lines, "STATUS:"+event.Status)
+		}
+		lines = append(lines,
+			fmt.Sprintf("SEQUENCE:%d", event.Sequence),
+			"TRANSP:OPAQUE",
+			"END:VEVENT",
+		)
+	}
+	lines = append(lines, "END:VCALENDAR")
+
+	bw := bufio.NewWriter(w)
+	for _, line := range lines {
+		if _, err := bw.WriteString(foldICalLine(line) + "\r\n"); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// foldICalLine splits a content line into lines of at most icalMaxLineOctets octets, each
+// continuation starting with a space. It never splits a UTF-8 sequence.
+func foldICalLine(line string) string {
+	if len(line) <= icalMaxLineOctets {
+		return line
+	}
+
+	var folded strings.Builder
+	limit := icalMaxLineOctets
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		folded.WriteString(line[:cut])
+		folded.WriteString("\r\n ")
+		line = line[cut:]
+		// The leading space of a continuation line counts towards its length.
+		limit = icalMaxLineOctets - 1
+	}
+	folded.WriteString(line)
+
+	return folded.String()
+}
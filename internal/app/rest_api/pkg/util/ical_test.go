@@ -0,0 +1,69 @@
+package util
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseICalEvents(t *testing.T) {
+	ics := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"BEGIN:VEVENT",
+		"UID:newyear-2026",
+		"DTSTART;VALUE=DATE:20260101",
+		"SUMMARY:New Year\\, Day",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"DTSTART;VALUE=DATE:20260330",
+		"DTEND;VALUE=DATE:20260401",
+		"SUMMARY:Eid al-Fitr ",
+		" Holiday",
+		"END:VEVENT",
+		"END:VCALENDAR",
+	}, "\r\n")
+
+	events, err := ParseICalEvents(strings.NewReader(ics))
+
+	assert.NoError(t, err)
+	assert.Len(t, events, 2)
+	assert.Equal(t, "New Year, Day", events[0].Summary)
+	assert.Equal(t, date(2026, time.January, 1), events[0].Start)
+	assert.Equal(t, date(2026, time.January, 2), events[0].End)
+	assert.True(t, events[0].AllDay)
+	assert.Equal(t, "Eid al-Fitr Holiday", events[1].Summary)
+	assert.Equal(t, date(2026, time.April, 1), events[1].End)
+}
+
+func TestWriteICalendar(t *testing.T) {
+	var buf strings.Builder
+	err := WriteICalendar(&buf, ICalendar{
+		Name:  "Team leave",
+		Stamp: time.Date(2026, time.March, 1, 8, 30, 0, 0, time.UTC),
+		Events: []ICalEvent{
+			{UID: "a", Summary: "Zoë, Ångström; " + strings.Repeat("é", 40), Start: date(2026, time.March, 2), End: date(2026, time.March, 4), AllDay: true, Status: "CONFIRMED"},
+			{UID: "b", Summary: "Dentist", Start: date(2026, time.March, 5).Add(9 * time.Hour), End: date(2026, time.March, 5).Add(11 * time.Hour), Sequence: 2},
+		},
+	})
+
+	assert.NoError(t, err)
+	for _, line := range strings.Split(strings.TrimSuffix(buf.String(), "\r\n"), "\r\n") {
+		assert.LessOrEqual(t, len(line), 75)
+		assert.True(t, utf8.ValidString(line))
+	}
+	assert.Contains(t, buf.String(), "DTSTAMP:20260301T083000Z\r\n")
+	assert.Contains(t, buf.String(), "DTSTART:20260305T090000\r\n")
+
+	events, err := ParseICalEvents(strings.NewReader(buf.String()))
+
+	assert.NoError(t, err)
+	assert.Len(t, events, 2)
+	assert.Equal(t, "Zoë, Ångström; "+strings.Repeat("é", 40), events[0].Summary)
+	assert.Equal(t, date(2026, time.March, 4), events[0].End)
+	assert.True(t, events[0].AllDay)
+	assert.Equal(t, date(2026, time.March, 5).Add(11*time.Hour), events[1].End)
+}
@@ -12,6 +12,12 @@ func MsgForTag(fe validator.FieldError) string {
 		return "This field is required"
 	case "min":
 		return fmt.Sprintf("Minimum length is %s", fe.Param())
+	case "max":
+		return fmt.Sprintf("Maximum length is %s", fe.Param())
+	case "oneof":
+		return fmt.Sprintf("Must be one of: %s", fe.Param())
+	case "datetime":
+		return fmt.Sprintf("Must be in the format %s", fe.Param())
 	case "email":
 		return "Invalid email format"
 	case "custom_password":
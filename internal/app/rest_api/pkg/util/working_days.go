@@ -0,0 +1,44 @@
+package util
+
+import (
+	"time"
+)
+
+type HolidaySet map[string]struct{}
+
+func NewHolidaySet(dates ...time.Time) HolidaySet {
+	set := HolidaySet{}
+	for _, date := range dates {
+		set[date.Format(time.DateOnly)] = struct{}{}
+	}
+	return set
+}
+
+func (s HolidaySet) Contains(day time.Time) bool {
+	_, ok := s[day.Format(time.DateOnly)]
+	return ok
+}
+
+// DateOnly drops the time of day, keeping the calendar date as written in t's location.
+func DateOnly(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func IsWorkingDay(day time.Time, holidays HolidaySet) bool {
+	if day.Weekday() == time.Saturday || day.Weekday() == time.Sunday {
+		return false
+	}
+	return !holidays.Contains(day)
+}
+
+// WorkingDays counts the days from start to end, both inclusive, that fall neither
+// on a weekend nor on a holiday.
+func WorkingDays(start, end time.Time, holidays HolidaySet) float64 {
+	var days float64
+	for day := DateOnly(start); !day.After(DateOnly(end)); day = day.AddDate(0, 0, 1) {
+		if IsWorkingDay(day, holidays) {
+			days++
+		}
+	}
+	return days
+}
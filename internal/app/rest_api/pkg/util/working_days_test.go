@@ -0,0 +1,60 @@
+package util
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestWorkingDays(t *testing.T) {
+	tests := []struct {
+		name     string
+		start    time.Time
+		end      time.Time
+		holidays HolidaySet
+		expected float64
+	}{
+		{
+			name:     "Single weekday",
+			start:    date(2025, time.December, 1),
+			end:      date(2025, time.December, 1),
+			expected: 1,
+		},
+		{
+			name:     "Weekend only",
+			start:    date(2025, time.December, 6),
+			end:      date(2025, time.December, 7),
+			expected: 0,
+		},
+		{
+			name:     "Range spanning a weekend",
+			start:    date(2025, time.December, 4),
+			end:      date(2025, time.December, 9),
+			expected: 4,
+		},
+		{
+			name:     "Holiday is excluded",
+			start:    date(2025, time.December, 22),
+			end:      date(2025, time.December, 26),
+			holidays: NewHolidaySet(date(2025, time.December, 25)),
+			expected: 4,
+		},
+		{
+			name:     "End before start",
+			start:    date(2025, time.December, 5),
+			end:      date(2025, time.December, 4),
+			expected: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, WorkingDays(tt.start, tt.end, tt.holidays))
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package util
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestXLSXRowWriter(t *testing.T) {
+	var buf bytes.Buffer
+	rows, err := NewRowWriter(&buf, ExportXLSX, "Leave & Co")
+	assert.NoError(t, err)
+	assert.NoError(t, rows.WriteRow("ID", "Reason"))
+	assert.NoError(t, rows.WriteRow(7, "<Dentist>", 0.5))
+	assert.NoError(t, rows.Close())
+
+	archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	assert.NoError(t, err)
+
+	parts := map[string]string{}
+	for _, file := range archive.File {
+		rc, err := file.Open()
+		assert.NoError(t, err)
+		content, err := io.ReadAll(rc)
+		assert.NoError(t, err)
+		rc.Close()
+		parts[file.Name] = string(content)
+	}
+
+	assert.Contains(t, parts, "[Content_Types].xml")
+	assert.Contains(t, parts["xl/workbook.xml"], `name="Leave &amp; Co"`)
+	sheet := parts["xl/worksheets/sheet1.xml"]
+	assert.Contains(t, sheet, `<c r="A2"><v>7</v></c>`)
+	assert.Contains(t, sheet, `<c r="B2" t="inlineStr"><is><t xml:space="preserve">&lt;Dentist&gt;</t></is></c>`)
+	assert.Contains(t, sheet, `<c r="C2"><v>0.5</v></c>`)
+	assert.True(t, strings.HasSuffix(sheet, "</sheetData></worksheet>"))
+	assert.NoError(t, xml.Unmarshal([]byte(sheet), new(struct{})))
+
+	assert.Equal(t, "Z", xlsxColumn(25))
+	assert.Equal(t, "AA", xlsxColumn(26))
+	assert.Equal(t, "AZ", xlsxColumn(51))
+}
@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/devonLoen/leave-request-service/internal/app/rest_api/database"
+	entity "github.com/devonLoen/leave-request-service/internal/app/rest_api/entity"
+)
+
+type AccrualPolicyRepository interface {
+	GetAllPolicies(ctx context.Context) ([]*entity.AccrualPolicy, error)
+	GetActivePolicies(ctx context.Context) ([]*entity.AccrualPolicy, error)
+	Upsert(ctx context.Context, policy *entity.AccrualPolicy) error
+}
+
+type AccrualPolicy struct {
+	database.BaseSQLRepository[entity.AccrualPolicy]
+}
+
+func NewAccrualPolicyRepository(db database.Querier) *AccrualPolicy {
+	return &AccrualPolicy{
+		BaseSQLRepository: database.BaseSQLRepository[entity.AccrualPolicy]{DB: db},
+	}
+}
+
+func mapAccrualPolicies(rows *sql.Rows, p *entity.AccrualPolicy) error {
+	return rows.Scan(&p.ID, &p.Type, &p.DaysPerMonth, &p.CarryOverCap, &p.CarryOverExpiryMonths, &p.Active)
+}
+
+func (r *AccrualPolicy) GetAllPolicies(ctx context.Context) ([]*entity.AccrualPolicy, error) {
+	return r.SelectMultiple(ctx,
+		mapAccrualPolicies,
+		"SELECT ap.id, ap.type, ap.days_per_month, ap.carry_over_cap, ap.carry_over_expiry_months, ap.active FROM accrual_policies ap ORDER BY ap.type",
+	)
+}
+
+func (r *AccrualPolicy) GetActivePolicies(ctx context.Context) ([]*entity.AccrualPolicy, error) {
+	return r.SelectMultiple(ctx,
+		mapAccrualPolicies,
+		"SELECT ap.id, ap.type, ap.days_per_month, ap.carry_over_cap, ap.carry_over_expiry_months, ap.active FROM accrual_policies ap WHERE ap.active ORDER BY ap.type",
+	)
+}
+
+func (r *AccrualPolicy) Upsert(ctx context.Context, policy *entity.AccrualPolicy) error {
+	id, err := r.Insert(ctx,
+		`INSERT INTO accrual_policies (type, days_per_month, carry_over_cap, carry_over_expiry_months, active) VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (type) DO UPDATE SET days_per_month = EXCLUDED.days_per_month, carry_over_cap = EXCLUDED.carry_over_cap,
+		carry_over_expiry_months = EXCLUDED.carry_over_expiry_months, active = EXCLUDED.active, updated_at = CURRENT_TIMESTAMP`,
+		policy.Type, policy.DaysPerMonth, policy.CarryOverCap, policy.CarryOverExpiryMonths, policy.Active,
+	)
+	if err != nil {
+		return err
+	}
+
+	policy.ID = id
+	return nil
+}
@@ -0,0 +1,195 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/devonLoen/leave-request-service/internal/app/rest_api/database"
+	entity "github.com/devonLoen/leave-request-service/internal/app/rest_api/entity"
+	"github.com/lib/pq"
+)
+
+type ApprovalRepository interface {
+	GetAllChains(ctx context.Context) ([]*entity.ApprovalChain, error)
+	FindChainFor(ctx context.Context, leaveType entity.LeaveRequestType, workingDays float64) (*entity.ApprovalChain, error)
+	UpsertChain(ctx context.Context, chain *entity.ApprovalChain) error
+	DeleteChain(ctx context.Context, id int) (bool, error)
+	CreateSteps(ctx context.Context, leaveRequestId int, steps []entity.ApproverKind) error
+	GetSteps(ctx context.Context, leaveRequestId int) ([]*entity.ApprovalStep, error)
+	DecideStep(ctx context.Context, stepId, approverId int, onBehalfOfId *int, status entity.ApprovalStepStatus, comment string) (bool, error)
+	ApprovePendingSteps(ctx context.Context, leaveRequestId, ruleId int, comment string) error
+	SkipPendingSteps(ctx context.Context, leaveRequestId int) error
+	DeleteSteps(ctx context.Context, leaveRequestId int) error
+}
+
+type Approval struct {
+	database.BaseSQLRepository[entity.ApprovalChain]
+	steps database.BaseSQLRepository[entity.ApprovalStep]
+}
+
+func NewApprovalRepository(db database.Querier) *Approval {
+	return &Approval{
+		BaseSQLRepository: database.BaseSQLRepository[entity.ApprovalChain]{DB: db},
+		steps:             database.BaseSQLRepository[entity.ApprovalStep]{DB: db},
+	}
+}
+
+const approvalChainSelectColumns = "ac.id, ac.type, ac.min_working_days, ac.steps"
+
+func mapApprovalChain(row *sql.Row, c *entity.ApprovalChain) error {
+	var steps pq.StringArray
+	if err := row.Scan(&c.ID, &c.Type, &c.MinWorkingDays, &steps); err != nil {
+		return err
+	}
+	c.Steps = toApproverKinds(steps)
+	return nil
+}
+
+func mapApprovalChains(rows *sql.Rows, c *entity.ApprovalChain) error {
+	var steps pq.StringArray
+	if err := rows.Scan(&c.ID, &c.Type, &c.MinWorkingDays, &steps); err != nil {
+		return err
+	}
+	c.Steps = toApproverKinds(steps)
+	return nil
+}
+
+func mapApprovalSteps(rows *sql.Rows, s *entity.ApprovalStep) error {
+	return rows.Scan(&s.ID, &s.LeaveRequestId, &s.StepOrder, &s.ApproverKind, &s.Status, &s.ApproverId, &s.ApproverName, &s.OnBehalfOfId, &s.OnBehalfOfName, &s.AutoApprovalRuleId, &s.Comment, &s.DecidedAt, &s.CreatedAt)
+}
+
+func toApproverKinds(steps []string) []entity.ApproverKind {
+	kinds := make([]entity.ApproverKind, 0, len(steps))
+	for _, step := range steps {
+		kinds = append(kinds, entity.ApproverKind(step))
+	}
+	return kinds
+}
+
+func fromApproverKinds(kinds []entity.ApproverKind) pq.StringArray {
+	steps := make(pq.StringArray, 0, len(kinds))
+	for _, kind := range kinds {
+		steps = append(steps, string(kind))
+	}
+	return steps
+}
+
+func (r *Approval) GetAllChains(ctx context.Context) ([]*entity.ApprovalChain, error) {
+	return r.SelectMultiple(ctx,
+		mapApprovalChains,
+		"SELECT "+approvalChainSelectColumns+" FROM approval_chains ac ORDER BY ac.type, ac.min_working_days",
+	)
+}
+
+// FindChainFor returns the chain with the highest threshold that workingDays reaches, or
+// sql.ErrNoRows when the leave type has none.
+func (r *Approval) FindChainFor(ctx context.Context, leaveType entity.LeaveRequestType, workingDays float64) (*entity.ApprovalChain, error) {
+	return r.SelectSingle(ctx,
+		mapApprovalChain,
+		"SELECT "+approvalChainSelectColumns+` FROM approval_chains ac
+		WHERE ac.type = $1 AND ac.min_working_days <= $2
+		ORDER BY ac.min_working_days DESC LIMIT 1`,
+		leaveType, workingDays,
+	)
+}
+
+func (r *Approval) UpsertChain(ctx context.Context, chain *entity.ApprovalChain) error {
+	id, err := r.Insert(ctx,
+		`INSERT INTO approval_chains (type, min_working_days, steps) VALUES ($1, $2, $3::approver_kind_enum[])
+		ON CONFLICT (type, min_working_days) DO UPDATE SET steps = EXCLUDED.steps, updated_at = CURRENT_TIMESTAMP`,
+		chain.Type, chain.MinWorkingDays, fromApproverKinds(chain.Steps),
+	)
+	if err != nil {
+		return err
+	}
+
+	chain.ID = id
+	return nil
+}
+
+func (r *Approval) DeleteChain(ctx context.Context, id int) (bool, error) {
+	result, err := r.ExecuteQuery(ctx, "DELETE FROM approval_chains WHERE id = $1", id)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
+
+// CreateSteps records the pending decisions of a request in chain order.
+func (r *Approval) CreateSteps(ctx context.Context, leaveRequestId int, steps []entity.ApproverKind) error {
+	_, err := r.ExecuteQuery(ctx,
+		`INSERT INTO leave_request_approvals (leave_request_id, step_order, approver_kind)
+		SELECT $1, s.step_order, s.approver_kind
+		FROM unnest($2::approver_kind_enum[]) WITH ORDINALITY AS s(approver_kind, step_order)`,
+		leaveRequestId, fromApproverKinds(steps),
+	)
+	return err
+}
+
+func (r *Approval) GetSteps(ctx context.Context, leaveRequestId int) ([]*entity.ApprovalStep, error) {
+	return r.steps.SelectMultiple(ctx,
+		mapApprovalSteps,
+		`SELECT a.id, a.leave_request_id, a.step_order, a.approver_kind, a.status, a.approver_id, COALESCE(approver.full_name, rule.name, ''),
+		a.on_behalf_of_id, COALESCE(principal.full_name, ''), a.auto_approval_rule_id, COALESCE(a.comment, ''), a.decided_at, a.created_at
+		FROM leave_request_approvals a
+		LEFT JOIN users approver ON approver.id = a.approver_id
+		LEFT JOIN users principal ON principal.id = a.on_behalf_of_id
+		LEFT JOIN auto_approval_rules rule ON rule.id = a.auto_approval_rule_id
+		WHERE a.leave_request_id = $1 ORDER BY a.step_order`,
+		leaveRequestId,
+	)
+}
+
+// DecideStep records a decision on a pending step, taken by approverId either in their own
+// right or as the delegate of onBehalfOfId, with an optional comment. It reports false when
+// the step was decided by someone else in the meantime.
+func (r *Approval) DecideStep(ctx context.Context, stepId, approverId int, onBehalfOfId *int, status entity.ApprovalStepStatus, comment string) (bool, error) {
+	result, err := r.ExecuteQuery(ctx,
+		`UPDATE leave_request_approvals SET status = $4, approver_id = $2, on_behalf_of_id = $3, comment = NULLIF($5, ''), decided_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND status = 'pending'`,
+		stepId, approverId, onBehalfOfId, status, comment,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
+
+// ApprovePendingSteps records the approval of every step still pending by an auto-approval
+// rule.
+func (r *Approval) ApprovePendingSteps(ctx context.Context, leaveRequestId, ruleId int, comment string) error {
+	_, err := r.ExecuteQuery(ctx,
+		`UPDATE leave_request_approvals SET status = 'approved', auto_approval_rule_id = $2, comment = NULLIF($3, ''), decided_at = CURRENT_TIMESTAMP
+		WHERE leave_request_id = $1 AND status = 'pending'`,
+		leaveRequestId, ruleId, comment,
+	)
+	return err
+}
+
+func (r *Approval) SkipPendingSteps(ctx context.Context, leaveRequestId int) error {
+	_, err := r.ExecuteQuery(ctx,
+		"UPDATE leave_request_approvals SET status = 'skipped' WHERE leave_request_id = $1 AND status = 'pending'",
+		leaveRequestId,
+	)
+	return err
+}
+
+func (r *Approval) DeleteSteps(ctx context.Context, leaveRequestId int) error {
+	_, err := r.ExecuteQuery(ctx,
+		"DELETE FROM leave_request_approvals WHERE leave_request_id = $1",
+		leaveRequestId,
+	)
+	return err
+}
@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/devonLoen/leave-request-service/internal/app/rest_api/database"
+	entity "github.com/devonLoen/leave-request-service/internal/app/rest_api/entity"
+)
+
+type AutoApprovalRuleRepository interface {
+	GetAll(ctx context.Context) ([]*entity.AutoApprovalRule, error)
+	GetActive(ctx context.Context) ([]*entity.AutoApprovalRule, error)
+	FindById(ctx context.Context, id int) (*entity.AutoApprovalRule, error)
+	Create(ctx context.Context, rule *entity.AutoApprovalRule) error
+	Update(ctx context.Context, rule *entity.AutoApprovalRule) error
+	Delete(ctx context.Context, id int) (bool, error)
+}
+
+type AutoApprovalRule struct {
+	database.BaseSQLRepository[entity.AutoApprovalRule]
+}
+
+func NewAutoApprovalRuleRepository(db database.Querier) *AutoApprovalRule {
+	return &AutoApprovalRule{
+		BaseSQLRepository: database.BaseSQLRepository[entity.AutoApprovalRule]{DB: db},
+	}
+}
+
+const autoApprovalRuleSelectColumns = `ar.id, ar.name, ar.leave_type, ar.role, COALESCE(ar.department, ''), ar.max_working_days, ar.min_notice_days,
+	ar.min_balance_after, ar.max_team_absent, ar.active`
+
+func mapAutoApprovalRule(row *sql.Row, r *entity.AutoApprovalRule) error {
+	return row.Scan(&r.ID, &r.Name, &r.LeaveType, &r.Role, &r.Department, &r.MaxWorkingDays, &r.MinNoticeDays,
+		&r.MinBalanceAfter, &r.MaxTeamAbsent, &r.Active)
+}
+
+func mapAutoApprovalRules(rows *sql.Rows, r *entity.AutoApprovalRule) error {
+	return rows.Scan(&r.ID, &r.Name, &r.LeaveType, &r.Role, &r.Department, &r.MaxWorkingDays, &r.MinNoticeDays,
+		&r.MinBalanceAfter, &r.MaxTeamAbsent, &r.Active)
+}
+
+func (r *AutoApprovalRule) GetAll(ctx context.Context) ([]*entity.AutoApprovalRule, error) {
+	return r.SelectMultiple(ctx,
+		mapAutoApprovalRules,
+		"SELECT "+autoApprovalRuleSelectColumns+" FROM auto_approval_rules ar ORDER BY ar.id",
+	)
+}
+
+// GetActive returns the rules submitted requests are checked against, in the order they are
+// tried.
+func (r *AutoApprovalRule) GetActive(ctx context.Context) ([]*entity.AutoApprovalRule, error) {
+	return r.SelectMultiple(ctx,
+		mapAutoApprovalRules,
+		"SELECT "+autoApprovalRuleSelectColumns+" FROM auto_approval_rules ar WHERE ar.active ORDER BY ar.id",
+	)
+}
+
+func (r *AutoApprovalRule) FindById(ctx context.Context, id int) (*entity.AutoApprovalRule, error) {
+	return r.SelectSingle(ctx,
+		mapAutoApprovalRule,
+		"SELECT "+autoApprovalRuleSelectColumns+" FROM auto_approval_rules ar WHERE ar.id = $1",
+		id,
+	)
+}
+
+func (r *AutoApprovalRule) Create(ctx context.Context, rule *entity.AutoApprovalRule) error {
+	id, err := r.Insert(ctx,
+		`INSERT INTO auto_approval_rules (name, leave_type, role, department, max_working_days, min_notice_days, min_balance_after, max_team_absent, active)
+		VALUES ($1, $2, $3, NULLIF($4, ''), $5, $6, $7, $8, $9)`,
+		rule.Name, rule.LeaveType, rule.Role, rule.Department, rule.MaxWorkingDays, rule.MinNoticeDays, rule.MinBalanceAfter, rule.MaxTeamAbsent, rule.Active,
+	)
+	if err != nil {
+		return err
+	}
+
+	rule.ID = id
+	return nil
+}
+
+func (r *AutoApprovalRule) Update(ctx context.Context, rule *entity.AutoApprovalRule) error {
+	_, err := r.ExecuteQuery(ctx,
+		`UPDATE auto_approval_rules SET name = $1, leave_type = $2, role = $3, department = NULLIF($4, ''), max_working_days = $5,
+		min_notice_days = $6, min_balance_after = $7, max_team_absent = $8, active = $9, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $10`,
+		rule.Name, rule.LeaveType, rule.Role, rule.Department, rule.MaxWorkingDays, rule.MinNoticeDays, rule.MinBalanceAfter, rule.MaxTeamAbsent, rule.Active, rule.ID,
+	)
+	return err
+}
+
+func (r *AutoApprovalRule) Delete(ctx context.Context, id int) (bool, error) {
+	result, err := r.ExecuteQuery(ctx, "DELETE FROM auto_approval_rules WHERE id = $1", id)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
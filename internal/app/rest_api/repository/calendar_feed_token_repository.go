@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/devonLoen/leave-request-service/internal/app/rest_api/database"
+	entity "github.com/devonLoen/leave-request-service/internal/app/rest_api/entity"
+)
+
+// CalendarFeedTokenRepository keeps at most one feed token per user.
+type CalendarFeedTokenRepository interface {
+	Replace(ctx context.Context, token *entity.CalendarFeedToken) error
+	Delete(ctx context.Context, userId int) (bool, error)
+	FindByTokenHash(ctx context.Context, tokenHash string) (*entity.CalendarFeedToken, error)
+}
+
+type CalendarFeedToken struct {
+	database.BaseSQLRepository[entity.CalendarFeedToken]
+}
+
+func NewCalendarFeedTokenRepository(db database.Querier) *CalendarFeedToken {
+	return &CalendarFeedToken{
+		BaseSQLRepository: database.BaseSQLRepository[entity.CalendarFeedToken]{DB: db},
+	}
+}
+
+func mapCalendarFeedToken(row *sql.Row, t *entity.CalendarFeedToken) error {
+	return row.Scan(&t.UserId, &t.TokenHash, &t.CreatedAt)
+}
+
+// Replace stores the token, revoking the one the user had before.
+func (r *CalendarFeedToken) Replace(ctx context.Context, token *entity.CalendarFeedToken) error {
+	_, err := r.ExecuteQuery(ctx,
+		`INSERT INTO calendar_feed_tokens (user_id, token_hash) VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE SET token_hash = EXCLUDED.token_hash, created_at = CURRENT_TIMESTAMP`,
+		token.UserId, token.TokenHash,
+	)
+	return err
+}
+
+func (r *CalendarFeedToken) Delete(ctx context.Context, userId int) (bool, error) {
+	result, err := r.ExecuteQuery(ctx, "DELETE FROM calendar_feed_tokens WHERE user_id = $1", userId)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
+
+func (r *CalendarFeedToken) FindByTokenHash(ctx context.Context, tokenHash string) (*entity.CalendarFeedToken, error) {
+	return r.SelectSingle(ctx,
+		mapCalendarFeedToken,
+		"SELECT t.user_id, t.token_hash, t.created_at FROM calendar_feed_tokens t WHERE t.token_hash = $1",
+		tokenHash,
+	)
+}
@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/devonLoen/leave-request-service/internal/app/rest_api/database"
+	entity "github.com/devonLoen/leave-request-service/internal/app/rest_api/entity"
+)
+
+type DelegationRepository interface {
+	Create(ctx context.Context, delegation *entity.ApprovalDelegation) error
+	Delete(ctx context.Context, id, delegatorId int) (bool, error)
+	GetByDelegator(ctx context.Context, delegatorId int) ([]*entity.ApprovalDelegation, error)
+	GetActiveForDelegate(ctx context.Context, delegateId int, on time.Time) ([]*entity.ApprovalDelegation, error)
+	OverlapExists(ctx context.Context, delegatorId int, startDate, endDate time.Time) (bool, error)
+}
+
+type Delegation struct {
+	database.BaseSQLRepository[entity.ApprovalDelegation]
+}
+
+func NewDelegationRepository(db database.Querier) *Delegation {
+	return &Delegation{
+		BaseSQLRepository: database.BaseSQLRepository[entity.ApprovalDelegation]{DB: db},
+	}
+}
+
+func mapDelegations(rows *sql.Rows, d *entity.ApprovalDelegation) error {
+	return rows.Scan(&d.ID, &d.DelegatorId, &d.DelegateId, &d.StartDate, &d.EndDate, &d.CreatedAt)
+}
+
+func (r *Delegation) Create(ctx context.Context, delegation *entity.ApprovalDelegation) error {
+	id, err := r.Insert(ctx,
+		"INSERT INTO approval_delegations (delegator_id, delegate_id, start_date, end_date) VALUES ($1, $2, $3, $4)",
+		delegation.DelegatorId, delegation.DelegateId, delegation.StartDate, delegation.EndDate,
+	)
+	if err != nil {
+		return err
+	}
+
+	delegation.ID = id
+	return nil
+}
+
+func (r *Delegation) Delete(ctx context.Context, id, delegatorId int) (bool, error) {
+	result, err := r.ExecuteQuery(ctx, "DELETE FROM approval_delegations WHERE id = $1 AND delegator_id = $2", id, delegatorId)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
+
+func (r *Delegation) GetByDelegator(ctx context.Context, delegatorId int) ([]*entity.ApprovalDelegation, error) {
+	return r.SelectMultiple(ctx,
+		mapDelegations,
+		`SELECT d.id, d.delegator_id, d.delegate_id, d.start_date, d.end_date, d.created_at
+		FROM approval_delegations d WHERE d.delegator_id = $1 ORDER BY d.start_date`,
+		delegatorId,
+	)
+}
+
+// GetActiveForDelegate returns the delegations that let delegateId decide for someone else on the given day.
+func (r *Delegation) GetActiveForDelegate(ctx context.Context, delegateId int, on time.Time) ([]*entity.ApprovalDelegation, error) {
+	return r.SelectMultiple(ctx,
+		mapDelegations,
+		`SELECT d.id, d.delegator_id, d.delegate_id, d.start_date, d.end_date, d.created_at
+		FROM approval_delegations d WHERE d.delegate_id = $1 AND $2::date BETWEEN d.start_date AND d.end_date
+		ORDER BY d.id`,
+		delegateId, on,
+	)
+}
+
+func (r *Delegation) OverlapExists(ctx context.Context, delegatorId int, startDate, endDate time.Time) (bool, error) {
+	var exists bool
+	err := r.SelectScalar(ctx,
+		&exists,
+		`SELECT EXISTS (
+			SELECT 1 FROM approval_delegations d
+			WHERE d.delegator_id = $1 AND d.start_date <= $3::date AND d.end_date >= $2::date
+		)`,
+		delegatorId, startDate, endDate,
+	)
+	return exists, err
+}
@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/devonLoen/leave-request-service/internal/app/rest_api/database"
+	entity "github.com/devonLoen/leave-request-service/internal/app/rest_api/entity"
+)
+
+type HolidayRepository interface {
+	Create(ctx context.Context, holiday *entity.Holiday) error
+	Update(ctx context.Context, holiday *entity.Holiday) error
+	Upsert(ctx context.Context, holiday *entity.Holiday) error
+	Delete(ctx context.Context, id int) (bool, error)
+	FindById(ctx context.Context, id int) (*entity.Holiday, error)
+	GetHolidaysBetween(ctx context.Context, start, end time.Time) ([]*entity.Holiday, error)
+}
+
+type Holiday struct {
+	database.BaseSQLRepository[entity.Holiday]
+}
+
+func NewHolidayRepository(db database.Querier) *Holiday {
+	return &Holiday{
+		BaseSQLRepository: database.BaseSQLRepository[entity.Holiday]{DB: db},
+	}
+}
+
+func mapHoliday(row *sql.Row, h *entity.Holiday) error {
+	return row.Scan(&h.ID, &h.Date, &h.Name)
+}
+
+func mapHolidays(rows *sql.Rows, h *entity.Holiday) error {
+	return rows.Scan(&h.ID, &h.Date, &h.Name)
+}
+
+func (r *Holiday) FindById(ctx context.Context, id int) (*entity.Holiday, error) {
+	return r.SelectSingle(ctx,
+		mapHoliday,
+		"SELECT ph.id, ph.holiday_date, ph.name FROM public_holidays ph WHERE ph.id = $1",
+		id,
+	)
+}
+
+func (r *Holiday) GetHolidaysBetween(ctx context.Context, start, end time.Time) ([]*entity.Holiday, error) {
+	return r.SelectMultiple(ctx,
+		mapHolidays,
+		"SELECT ph.id, ph.holiday_date, ph.name FROM public_holidays ph WHERE ph.holiday_date BETWEEN $1 AND $2 ORDER BY ph.holiday_date",
+		start, end,
+	)
+}
+
+func (r *Holiday) Create(ctx context.Context, holiday *entity.Holiday) error {
+	id, err := r.Insert(ctx,
+		"INSERT INTO public_holidays (holiday_date, name) VALUES ($1, $2)",
+		holiday.Date, holiday.Name,
+	)
+	if err != nil {
+		return err
+	}
+
+	holiday.ID = id
+	return nil
+}
+
+func (r *Holiday) Upsert(ctx context.Context, holiday *entity.Holiday) error {
+	id, err := r.Insert(ctx,
+		`INSERT INTO public_holidays (holiday_date, name) VALUES ($1, $2)
+		ON CONFLICT (holiday_date) DO UPDATE SET name = EXCLUDED.name, updated_at = CURRENT_TIMESTAMP`,
+		holiday.Date, holiday.Name,
+	)
+	if err != nil {
+		return err
+	}
+
+	holiday.ID = id
+	return nil
+}
+
+func (r *Holiday) Update(ctx context.Context, holiday *entity.Holiday) error {
+	_, err := r.ExecuteQuery(ctx,
+		"UPDATE public_holidays SET holiday_date = $1, name = $2, updated_at = CURRENT_TIMESTAMP WHERE id = $3",
+		holiday.Date, holiday.Name, holiday.ID,
+	)
+	return err
+}
+
+func (r *Holiday) Delete(ctx context.Context, id int) (bool, error) {
+	result, err := r.ExecuteQuery(ctx, "DELETE FROM public_holidays WHERE id = $1", id)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
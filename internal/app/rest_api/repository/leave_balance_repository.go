@@ -0,0 +1,164 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/devonLoen/leave-request-service/internal/app/rest_api/database"
+	entity "github.com/devonLoen/leave-request-service/internal/app/rest_api/entity"
+)
+
+type LeaveBalanceRepository interface {
+	AddEntry(ctx context.Context, entry *entity.LeaveBalanceEntry) error
+	AddPeriodEntry(ctx context.Context, entry *entity.LeaveBalanceEntry) (bool, error)
+	SumEntries(ctx context.Context, userId, leaveYear int, leaveType entity.LeaveRequestType, entryType entity.BalanceEntryType) (float64, error)
+	GetUsedBefore(ctx context.Context, userId, leaveYear int, leaveType entity.LeaveRequestType, before time.Time) (float64, error)
+	GetBalance(ctx context.Context, userId, leaveYear int, leaveType entity.LeaveRequestType, excludeLeaveRequestId int) (*entity.LeaveBalance, error)
+	GetBalances(ctx context.Context, userId, leaveYear int) ([]*entity.LeaveBalance, error)
+	ReleaseReservation(ctx context.Context, leaveRequestId int) error
+	ReleaseDebit(ctx context.Context, leaveRequestId int) error
+	LockUser(ctx context.Context, userId int) error
+}
+
+type LeaveBalance struct {
+	database.BaseSQLRepository[entity.LeaveBalance]
+}
+
+func NewLeaveBalanceRepository(db database.Querier) *LeaveBalance {
+	return &LeaveBalance{
+		BaseSQLRepository: database.BaseSQLRepository[entity.LeaveBalance]{DB: db},
+	}
+}
+
+func leaveBalanceAggregates(excludeArgId int) string {
+	return fmt.Sprintf(`
+		COALESCE(SUM(CASE
+			WHEN lbe.entry_type IN ('credit', 'accrual', 'carry_over') THEN lbe.days
+			WHEN lbe.entry_type = 'expiry' THEN -lbe.days
+		END), 0),
+		COALESCE(SUM(lbe.days) FILTER (WHERE lbe.entry_type = 'debit'), 0),
+		COALESCE(SUM(lbe.days) FILTER (WHERE lbe.entry_type = 'reservation' AND lbe.leave_request_id IS DISTINCT FROM $%d), 0)`,
+		excludeArgId,
+	)
+}
+
+func mapLeaveBalance(row *sql.Row, b *entity.LeaveBalance) error {
+	return row.Scan(&b.UserId, &b.LeaveYear, &b.Type, &b.Entitled, &b.Used, &b.Reserved)
+}
+
+func mapLeaveBalances(rows *sql.Rows, b *entity.LeaveBalance) error {
+	return rows.Scan(&b.UserId, &b.LeaveYear, &b.Type, &b.Entitled, &b.Used, &b.Reserved)
+}
+
+func (r *LeaveBalance) AddEntry(ctx context.Context, entry *entity.LeaveBalanceEntry) error {
+	id, err := r.Insert(ctx,
+		"INSERT INTO leave_balance_entries (user_id, leave_year, type, entry_type, days, leave_request_id, note) VALUES ($1, $2, $3, $4, $5, $6, $7)",
+		entry.UserId, entry.LeaveYear, entry.Type, entry.EntryType, entry.Days, entry.LeaveRequestId, entry.Note,
+	)
+	if err != nil {
+		return err
+	}
+
+	entry.ID = id
+	return nil
+}
+
+// AddPeriodEntry posts an accrual, carry-over or expiry entry at most once per
+// user, leave type, entry type and accrual period. It reports whether a row was written.
+func (r *LeaveBalance) AddPeriodEntry(ctx context.Context, entry *entity.LeaveBalanceEntry) (bool, error) {
+	result, err := r.ExecuteQuery(ctx,
+		`INSERT INTO leave_balance_entries (user_id, leave_year, type, entry_type, days, note, accrual_period)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (user_id, type, entry_type, accrual_period) WHERE accrual_period IS NOT NULL DO NOTHING`,
+		entry.UserId, entry.LeaveYear, entry.Type, entry.EntryType, entry.Days, entry.Note, entry.AccrualPeriod,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
+
+func (r *LeaveBalance) SumEntries(ctx context.Context, userId, leaveYear int, leaveType entity.LeaveRequestType, entryType entity.BalanceEntryType) (float64, error) {
+	var total float64
+
+	err := r.SelectScalar(ctx,
+		&total,
+		`SELECT COALESCE(SUM(lbe.days), 0) FROM leave_balance_entries lbe
+		WHERE lbe.user_id = $1 AND lbe.leave_year = $2 AND lbe.type = $3 AND lbe.entry_type = $4`,
+		userId, leaveYear, leaveType, entryType,
+	)
+
+	return total, err
+}
+
+// GetUsedBefore sums the debits of a leave year for leave starting before the given date.
+// Manual debits without a leave request count from the moment they were posted.
+func (r *LeaveBalance) GetUsedBefore(ctx context.Context, userId, leaveYear int, leaveType entity.LeaveRequestType, before time.Time) (float64, error) {
+	var total float64
+
+	err := r.SelectScalar(ctx,
+		&total,
+		`SELECT COALESCE(SUM(lbe.days), 0) FROM leave_balance_entries lbe
+		LEFT JOIN leave_requests lr ON lr.id = lbe.leave_request_id
+		WHERE lbe.user_id = $1 AND lbe.leave_year = $2 AND lbe.type = $3 AND lbe.entry_type = 'debit'
+		AND COALESCE(lr.start_date, lbe.created_at) < $4`,
+		userId, leaveYear, leaveType, before,
+	)
+
+	return total, err
+}
+
+// GetBalance aggregates the ledger for one leave type. Reservations held by
+// excludeLeaveRequestId are left out so a request can be checked against its own hold.
+func (r *LeaveBalance) GetBalance(ctx context.Context, userId, leaveYear int, leaveType entity.LeaveRequestType, excludeLeaveRequestId int) (*entity.LeaveBalance, error) {
+	return r.SelectSingle(ctx,
+		mapLeaveBalance,
+		"SELECT $1::int, $2::int, $3::varchar,"+leaveBalanceAggregates(4)+`
+		FROM leave_balance_entries lbe
+		WHERE lbe.user_id = $1 AND lbe.leave_year = $2 AND lbe.type = $3`,
+		userId, leaveYear, leaveType, excludeLeaveRequestId,
+	)
+}
+
+func (r *LeaveBalance) GetBalances(ctx context.Context, userId, leaveYear int) ([]*entity.LeaveBalance, error) {
+	return r.SelectMultiple(ctx,
+		mapLeaveBalances,
+		"SELECT lbe.user_id, lbe.leave_year, lbe.type,"+leaveBalanceAggregates(3)+`
+		FROM leave_balance_entries lbe
+		WHERE lbe.user_id = $1 AND lbe.leave_year = $2
+		GROUP BY lbe.user_id, lbe.leave_year, lbe.type
+		ORDER BY lbe.type`,
+		userId, leaveYear, 0,
+	)
+}
+
+func (r *LeaveBalance) ReleaseReservation(ctx context.Context, leaveRequestId int) error {
+	_, err := r.ExecuteQuery(ctx,
+		"DELETE FROM leave_balance_entries WHERE leave_request_id = $1 AND entry_type = 'reservation'",
+		leaveRequestId,
+	)
+	return err
+}
+
+func (r *LeaveBalance) ReleaseDebit(ctx context.Context, leaveRequestId int) error {
+	_, err := r.ExecuteQuery(ctx,
+		"DELETE FROM leave_balance_entries WHERE leave_request_id = $1 AND entry_type = 'debit'",
+		leaveRequestId,
+	)
+	return err
+}
+
+// LockUser holds a lock on the user's ledger until the surrounding transaction ends, so a
+// balance check and the reservation or debit it allows cannot interleave with another one.
+func (r *LeaveBalance) LockUser(ctx context.Context, userId int) error {
+	_, err := r.ExecuteQuery(ctx, "SELECT pg_advisory_xact_lock(hashtext('leave_balance_entries'), $1)", userId)
+	return err
+}
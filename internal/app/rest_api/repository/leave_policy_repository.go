@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/devonLoen/leave-request-service/internal/app/rest_api/database"
+	entity "github.com/devonLoen/leave-request-service/internal/app/rest_api/entity"
+)
+
+type LeavePolicyRepository interface {
+	GetAll(ctx context.Context) ([]*entity.LeavePolicy, error)
+	FindById(ctx context.Context, id int) (*entity.LeavePolicy, error)
+	FindApplicable(ctx context.Context, userId int, leaveType entity.LeaveRequestType, on time.Time) (*entity.LeavePolicy, error)
+	Create(ctx context.Context, policy *entity.LeavePolicy) error
+	Update(ctx context.Context, policy *entity.LeavePolicy) error
+	Delete(ctx context.Context, id int) (bool, error)
+}
+
+type LeavePolicy struct {
+	database.BaseSQLRepository[entity.LeavePolicy]
+}
+
+func NewLeavePolicyRepository(db database.Querier) *LeavePolicy {
+	return &LeavePolicy{
+		BaseSQLRepository: database.BaseSQLRepository[entity.LeavePolicy]{DB: db},
+	}
+}
+
+const leavePolicySelectColumns = `lp.id, lp.name, lp.leave_type, lp.role, COALESCE(lp.department, ''), lp.min_tenure_months, lp.max_tenure_months,
+	lp.effective_from, lp.effective_to, lp.entitled, lp.days_per_year, lp.max_consecutive_days, lp.min_notice_days`
+
+func mapLeavePolicy(row *sql.Row, p *entity.LeavePolicy) error {
+	return row.Scan(&p.ID, &p.Name, &p.LeaveType, &p.Role, &p.Department, &p.MinTenureMonths, &p.MaxTenureMonths,
+		&p.EffectiveFrom, &p.EffectiveTo, &p.Entitled, &p.DaysPerYear, &p.MaxConsecutiveDays, &p.MinNoticeDays)
+}
+
+func mapLeavePolicies(rows *sql.Rows, p *entity.LeavePolicy) error {
+	return rows.Scan(&p.ID, &p.Name, &p.LeaveType, &p.Role, &p.Department, &p.MinTenureMonths, &p.MaxTenureMonths,
+		&p.EffectiveFrom, &p.EffectiveTo, &p.Entitled, &p.DaysPerYear, &p.MaxConsecutiveDays, &p.MinNoticeDays)
+}
+
+func (r *LeavePolicy) GetAll(ctx context.Context) ([]*entity.LeavePolicy, error) {
+	return r.SelectMultiple(ctx,
+		mapLeavePolicies,
+		"SELECT "+leavePolicySelectColumns+" FROM leave_policies lp ORDER BY lp.leave_type, lp.effective_from, lp.id",
+	)
+}
+
+func (r *LeavePolicy) FindById(ctx context.Context, id int) (*entity.LeavePolicy, error) {
+	return r.SelectSingle(ctx,
+		mapLeavePolicy,
+		"SELECT "+leavePolicySelectColumns+" FROM leave_policies lp WHERE lp.id = $1",
+		id,
+	)
+}
+
+// FindApplicable returns the policy of leaveType that applies to the user on the given date,
+// following the precedence described on entity.LeavePolicy. It returns sql.ErrNoRows when
+// none does.
+func (r *LeavePolicy) FindApplicable(ctx context.Context, userId int, leaveType entity.LeaveRequestType, on time.Time) (*entity.LeavePolicy, error) {
+	return r.SelectSingle(ctx,
+		mapLeavePolicy,
+		`WITH member AS (
+			SELECT u.role, u.department,
+				(EXTRACT(YEAR FROM age($3::date, u.hire_date)) * 12 + EXTRACT(MONTH FROM age($3::date, u.hire_date)))::int AS tenure_months
+			FROM users u WHERE u.id = $1
+		)
+		SELECT `+leavePolicySelectColumns+`
+		FROM leave_policies lp, member m
+		WHERE lp.leave_type = $2
+		AND lp.effective_from <= $3 AND (lp.effective_to IS NULL OR lp.effective_to >= $3)
+		AND (lp.role IS NULL OR lp.role = m.role)
+		AND (lp.department IS NULL OR lower(lp.department) = lower(m.department))
+		AND m.tenure_months >= lp.min_tenure_months
+		AND (lp.max_tenure_months IS NULL OR m.tenure_months < lp.max_tenure_months)
+		ORDER BY (lp.role IS NOT NULL)::int + (lp.department IS NOT NULL)::int DESC,
+			lp.min_tenure_months DESC, lp.effective_from DESC, lp.id DESC
+		LIMIT 1`,
+		userId, leaveType, on,
+	)
+}
+
+func (r *LeavePolicy) Create(ctx context.Context, policy *entity.LeavePolicy) error {
+	id, err := r.Insert(ctx,
+		`INSERT INTO leave_policies (name, leave_type, role, department, min_tenure_months, max_tenure_months, effective_from, effective_to,
+		entitled, days_per_year, max_consecutive_days, min_notice_days)
+		VALUES ($1, $2, $3, NULLIF($4, ''), $5, $6, $7, $8, $9, $10, $11, $12)`,
+		policy.Name, policy.LeaveType, policy.Role, policy.Department, policy.MinTenureMonths, policy.MaxTenureMonths, policy.EffectiveFrom, policy.EffectiveTo,
+		policy.Entitled, policy.DaysPerYear, policy.MaxConsecutiveDays, policy.MinNoticeDays,
+	)
+	if err != nil {
+		return err
+	}
+
+	policy.ID = id
+	return nil
+}
+
+func (r *LeavePolicy) Update(ctx context.Context, policy *entity.LeavePolicy) error {
+	_, err := r.ExecuteQuery(ctx,
+		`UPDATE leave_policies SET name = $1, leave_type = $2, role = $3, department = NULLIF($4, ''), min_tenure_months = $5,
+		max_tenure_months = $6, effective_from = $7, effective_to = $8, entitled = $9, days_per_year = $10,
+		max_consecutive_days = $11, min_notice_days = $12, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $13`,
+		policy.Name, policy.LeaveType, policy.Role, policy.Department, policy.MinTenureMonths, policy.MaxTenureMonths, policy.EffectiveFrom, policy.EffectiveTo,
+		policy.Entitled, policy.DaysPerYear, policy.MaxConsecutiveDays, policy.MinNoticeDays, policy.ID,
+	)
+	return err
+}
+
+func (r *LeavePolicy) Delete(ctx context.Context, id int) (bool, error) {
+	result, err := r.ExecuteQuery(ctx, "DELETE FROM leave_policies WHERE id = $1", id)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/devonLoen/leave-request-service/internal/app/rest_api/database"
+	entity "github.com/devonLoen/leave-request-service/internal/app/rest_api/entity"
+)
+
+type AttachmentRepository interface {
+	Create(ctx context.Context, attachment *entity.LeaveRequestAttachment) error
+	FindById(ctx context.Context, leaveRequestId, id int) (*entity.LeaveRequestAttachment, error)
+	GetByLeaveRequest(ctx context.Context, leaveRequestId int) ([]*entity.LeaveRequestAttachment, error)
+	CountByLeaveRequest(ctx context.Context, leaveRequestId int) (int, error)
+	Delete(ctx context.Context, id int) (bool, error)
+}
+
+type Attachment struct {
+	database.BaseSQLRepository[entity.LeaveRequestAttachment]
+}
+
+func NewAttachmentRepository(db database.Querier) *Attachment {
+	return &Attachment{
+		BaseSQLRepository: database.BaseSQLRepository[entity.LeaveRequestAttachment]{DB: db},
+	}
+}
+
+const attachmentSelectColumns = "a.id, a.leave_request_id, a.uploaded_by, a.file_name, a.content_type, a.size_bytes, a.storage_key, a.created_at"
+
+func mapAttachment(row *sql.Row, a *entity.LeaveRequestAttachment) error {
+	return row.Scan(&a.ID, &a.LeaveRequestId, &a.UploadedBy, &a.FileName, &a.ContentType, &a.Size, &a.StorageKey, &a.CreatedAt)
+}
+
+func mapAttachments(rows *sql.Rows, a *entity.LeaveRequestAttachment) error {
+	return rows.Scan(&a.ID, &a.LeaveRequestId, &a.UploadedBy, &a.FileName, &a.ContentType, &a.Size, &a.StorageKey, &a.CreatedAt)
+}
+
+func (r *Attachment) Create(ctx context.Context, attachment *entity.LeaveRequestAttachment) error {
+	id, err := r.Insert(ctx,
+		"INSERT INTO leave_request_attachments (leave_request_id, uploaded_by, file_name, content_type, size_bytes, storage_key) VALUES ($1, $2, $3, $4, $5, $6)",
+		attachment.LeaveRequestId, attachment.UploadedBy, attachment.FileName, attachment.ContentType, attachment.Size, attachment.StorageKey,
+	)
+	if err != nil {
+		return err
+	}
+
+	attachment.ID = id
+	return nil
+}
+
+func (r *Attachment) FindById(ctx context.Context, leaveRequestId, id int) (*entity.LeaveRequestAttachment, error) {
+	return r.SelectSingle(ctx,
+		mapAttachment,
+		"SELECT "+attachmentSelectColumns+" FROM leave_request_attachments a WHERE a.id = $1 AND a.leave_request_id = $2",
+		id, leaveRequestId,
+	)
+}
+
+func (r *Attachment) GetByLeaveRequest(ctx context.Context, leaveRequestId int) ([]*entity.LeaveRequestAttachment, error) {
+	return r.SelectMultiple(ctx,
+		mapAttachments,
+		"SELECT "+attachmentSelectColumns+" FROM leave_request_attachments a WHERE a.leave_request_id = $1 ORDER BY a.id",
+		leaveRequestId,
+	)
+}
+
+func (r *Attachment) CountByLeaveRequest(ctx context.Context, leaveRequestId int) (int, error) {
+	var count int
+	err := r.SelectScalar(ctx,
+		&count,
+		"SELECT COUNT(*) FROM leave_request_attachments a WHERE a.leave_request_id = $1",
+		leaveRequestId,
+	)
+	return count, err
+}
+
+func (r *Attachment) Delete(ctx context.Context, id int) (bool, error) {
+	result, err := r.ExecuteQuery(ctx, "DELETE FROM leave_request_attachments WHERE id = $1", id)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
@@ -1,23 +1,42 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"strings"
 	"time"
 
 	"github.com/devonLoen/leave-request-service/internal/app/rest_api/database"
 	entity "github.com/devonLoen/leave-request-service/internal/app/rest_api/entity"
+	"github.com/lib/pq"
 )
 
+// LeaveRequestRepository records every change it makes to a leave request in the request's
+// history, together with the user who made it. Changes to an existing request only apply
+// while it still has the status and version it was loaded with; they report false when
+// someone else changed it in the meantime.
 type LeaveRequestRepository interface {
-	Create(leaveRequest *entity.LeaveRequest) error
-	FindById(id int) (*entity.LeaveRequest, error)
-	GetAllLeaveRequests(limit, offset int, sortBy, orderBy, search string, filter entity.LeaveRequestFilter) ([]*entity.LeaveRequest, error)
-	Approve(leaveRequestId int) error
-	Reject(leaveRequestId int) error
-	OverlapApprovedLeaveExists(userId int, startDate, endDate time.Time) (bool, error)
-	Submit(leaveRequestId int) error
+	Create(ctx context.Context, leaveRequest *entity.LeaveRequest, actorId int) error
+	Update(ctx context.Context, current, updated *entity.LeaveRequest, actorId int) (bool, error)
+	Delete(ctx context.Context, leaveRequest *entity.LeaveRequest, actorId int) (bool, error)
+	FindById(ctx context.Context, id int) (*entity.LeaveRequest, error)
+	GetOpenBetween(ctx context.Context, from, to time.Time) ([]*entity.LeaveRequest, error)
+	GetAllLeaveRequests(ctx context.Context, limit, offset int, sortBy, orderBy, search string, filter entity.LeaveRequestFilter) ([]*entity.LeaveRequest, error)
+	GetAbsences(ctx context.Context, filter entity.LeaveRequestFilter) ([]*entity.Absence, error)
+	ExportLeaveRequests(ctx context.Context, sortBy, orderBy, search string, filter entity.LeaveRequestFilter, fn func(*entity.LeaveRequestExport) error) error
+	GetEvents(ctx context.Context, leaveRequestId int) ([]*entity.LeaveRequestEvent, error)
+	Approve(ctx context.Context, leaveRequest *entity.LeaveRequest, decidedBy int, comment string) (bool, error)
+	AutoApprove(ctx context.Context, leaveRequest *entity.LeaveRequest, rule *entity.AutoApprovalRule, comment string) (bool, error)
+	Reject(ctx context.Context, leaveRequest *entity.LeaveRequest, decidedBy int, reason string) (bool, error)
+	OverlapApprovedLeaveExists(ctx context.Context, userId int, periodStart, periodEnd time.Time) (bool, error)
+	CountTeamAbsences(ctx context.Context, userId int, periodStart, periodEnd time.Time) (int, error)
+	Submit(ctx context.Context, leaveRequest *entity.LeaveRequest, actorId int) (bool, error)
+	RecountWorkingDays(ctx context.Context, leaveRequest *entity.LeaveRequest, workingDays float64) (bool, error)
+	Cancel(ctx context.Context, leaveRequest *entity.LeaveRequest, actorId int) (bool, error)
+	RequestCancellation(ctx context.Context, leaveRequest *entity.LeaveRequest, actorId int) (bool, error)
+	RejectCancellation(ctx context.Context, leaveRequest *entity.LeaveRequest, actorId int) (bool, error)
 }
 
 type LeaveRequest struct {
@@ -25,34 +44,56 @@ type LeaveRequest struct {
 	database.BaseSQLRepository[entity.LeaveRequest]
 }
 
-func NewLeaveRequestRepository(db *sql.DB) *LeaveRequest {
+func NewLeaveRequestRepository(db database.Querier) *LeaveRequest {
 	return &LeaveRequest{
 		BaseSQLRepository: database.BaseSQLRepository[entity.LeaveRequest]{DB: db},
 	}
 }
 
+const leaveRequestSelectColumns = "lr.id, lr.user_id, lr.start_date, lr.end_date, lr.working_days, lr.duration_unit, lr.start_session, lr.end_session, lr.period_start, lr.period_end, lr.type, lr.status, lr.reason, " +
+	"lr.decided_by, lr.decided_at, COALESCE(lr.rejection_reason, ''), COALESCE(lr.approval_comment, ''), lr.version"
+
+// teamManagersQuery selects the manager a team listing is for, together with everyone who
+// delegated their approvals to that manager for today. Both placeholders take the manager id.
+const teamManagersQuery = "SELECT $%d::int UNION SELECT d.delegator_id FROM approval_delegations d WHERE d.delegate_id = $%d AND CURRENT_DATE BETWEEN d.start_date AND d.end_date"
+
 func mapLeaveRequest(rows *sql.Row, lr *entity.LeaveRequest) error {
-	return rows.Scan(&lr.ID, &lr.UserId, &lr.StartDate, &lr.EndDate, &lr.Type, &lr.Status, &lr.Reason)
+	return rows.Scan(&lr.ID, &lr.UserId, &lr.StartDate, &lr.EndDate, &lr.WorkingDays, &lr.DurationUnit, &lr.StartSession, &lr.EndSession, &lr.PeriodStart, &lr.PeriodEnd, &lr.Type, &lr.Status, &lr.Reason,
+		&lr.DecidedBy, &lr.DecidedAt, &lr.RejectionReason, &lr.ApprovalComment, &lr.Version)
 }
 
 func mapLeaveRequests(rows *sql.Rows, lr *entity.LeaveRequest) error {
-	return rows.Scan(&lr.ID, &lr.UserId, &lr.StartDate, &lr.EndDate, &lr.Type, &lr.Status, &lr.Reason)
+	return rows.Scan(&lr.ID, &lr.UserId, &lr.StartDate, &lr.EndDate, &lr.WorkingDays, &lr.DurationUnit, &lr.StartSession, &lr.EndSession, &lr.PeriodStart, &lr.PeriodEnd, &lr.Type, &lr.Status, &lr.Reason,
+		&lr.DecidedBy, &lr.DecidedAt, &lr.RejectionReason, &lr.ApprovalComment, &lr.Version)
 }
 
-func (r *LeaveRequest) FindById(id int) (*entity.LeaveRequest, error) {
-	return r.SelectSingle(
+func (r *LeaveRequest) FindById(ctx context.Context, id int) (*entity.LeaveRequest, error) {
+	return r.SelectSingle(ctx,
 		mapLeaveRequest,
-		"SELECT lr.id, lr.user_id, lr.start_date, lr.end_date, lr.type, lr.status, lr.reason FROM leave_requests lr WHERE lr.id = $1",
+		"SELECT "+leaveRequestSelectColumns+" FROM leave_requests lr WHERE lr.id = $1",
 		id,
 	)
 }
 
-func (r *LeaveRequest) GetAllLeaveRequests(limit, offset int, sortBy, orderBy, search string, filter entity.LeaveRequestFilter) ([]*entity.LeaveRequest, error) {
-	baseQuery := "SELECT lr.id, lr.user_id, lr.start_date, lr.end_date, lr.type, lr.status, lr.reason FROM leave_requests lr"
-	var conditions []string
-	var args []interface{}
+// GetOpenBetween returns the requests that are, or may still be, charged to a balance
+// (drafts, requests waiting for approval, approved leave and leave awaiting cancellation)
+// and cover at least one day from from to to.
+func (r *LeaveRequest) GetOpenBetween(ctx context.Context, from, to time.Time) ([]*entity.LeaveRequest, error) {
+	return r.SelectMultiple(ctx,
+		mapLeaveRequests,
+		`SELECT `+leaveRequestSelectColumns+` FROM leave_requests lr
+		WHERE lr.status IN ('draft', 'waiting_approval', 'approved', 'cancellation_requested')
+		AND lr.start_date::date <= $2::date AND lr.end_date::date >= $1::date
+		ORDER BY lr.id`,
+		from, to,
+	)
+}
 
-	argId := 1
+// leaveRequestFilterConditions turns filter into WHERE conditions on leave_requests lr. Their
+// placeholders are numbered from argId; the number after the last one is returned.
+func leaveRequestFilterConditions(filter entity.LeaveRequestFilter, argId int) ([]string, []any, int) {
+	var conditions []string
+	var args []any
 
 	if filter.UserId != "" {
 		conditions = append(conditions, fmt.Sprintf("(lr.user_id = $%d)", argId))
@@ -66,6 +107,56 @@ func (r *LeaveRequest) GetAllLeaveRequests(limit, offset int, sortBy, orderBy, s
 		argId++
 	}
 
+	if len(filter.Statuses) > 0 {
+		statuses := make([]string, len(filter.Statuses))
+		for i, status := range filter.Statuses {
+			statuses[i] = string(status)
+		}
+		conditions = append(conditions, fmt.Sprintf("(lr.status::text = ANY($%d))", argId))
+		args = append(args, pq.Array(statuses))
+		argId++
+	}
+
+	if !filter.From.IsZero() {
+		conditions = append(conditions, fmt.Sprintf("(lr.period_end > $%d::timestamp)", argId))
+		args = append(args, filter.From)
+		argId++
+	}
+
+	if !filter.To.IsZero() {
+		conditions = append(conditions, fmt.Sprintf("(lr.period_start < $%d::timestamp)", argId))
+		args = append(args, filter.To.AddDate(0, 0, 1))
+		argId++
+	}
+
+	if filter.ManagerId != 0 {
+		if filter.IncludeIndirect {
+			conditions = append(conditions, fmt.Sprintf(
+				`(lr.user_id IN (
+					WITH RECURSIVE reports AS (
+						SELECT u.id FROM users u WHERE u.manager_id IN (`+teamManagersQuery+`)
+						UNION
+						SELECT u.id FROM users u JOIN reports r ON u.manager_id = r.id
+					)
+					SELECT id FROM reports
+				))`,
+				argId, argId,
+			))
+		} else {
+			conditions = append(conditions, fmt.Sprintf("(lr.user_id IN (SELECT u.id FROM users u WHERE u.manager_id IN ("+teamManagersQuery+")))", argId, argId))
+		}
+		args = append(args, filter.ManagerId)
+		argId++
+	}
+
+	return conditions, args, argId
+}
+
+// leaveRequestSearchConditions adds the free-text search of the leave request listings to the
+// conditions of filter.
+func leaveRequestSearchConditions(search string, filter entity.LeaveRequestFilter) ([]string, []any, int) {
+	conditions, args, argId := leaveRequestFilterConditions(filter, 1)
+
 	if search != "" {
 		conditions = append(conditions, fmt.Sprintf(
 			"(lr.type::text ILIKE $%d OR lr.status::text ILIKE $%d OR lr.reason ILIKE $%d)",
@@ -75,6 +166,13 @@ func (r *LeaveRequest) GetAllLeaveRequests(limit, offset int, sortBy, orderBy, s
 		argId++
 	}
 
+	return conditions, args, argId
+}
+
+func (r *LeaveRequest) GetAllLeaveRequests(ctx context.Context, limit, offset int, sortBy, orderBy, search string, filter entity.LeaveRequestFilter) ([]*entity.LeaveRequest, error) {
+	baseQuery := "SELECT " + leaveRequestSelectColumns + " FROM leave_requests lr"
+	conditions, args, argId := leaveRequestSearchConditions(search, filter)
+
 	if len(conditions) > 0 {
 		baseQuery += " WHERE " + strings.Join(conditions, " AND ")
 	}
@@ -82,48 +180,256 @@ func (r *LeaveRequest) GetAllLeaveRequests(limit, offset int, sortBy, orderBy, s
 	baseQuery += fmt.Sprintf(" ORDER BY lr.%s %s LIMIT $%d OFFSET $%d", sortBy, orderBy, argId, argId+1)
 	args = append(args, limit, offset)
 
-	return r.SelectMultiple(
+	return r.SelectMultiple(ctx,
 		mapLeaveRequests,
 		baseQuery,
 		args...,
 	)
 }
 
-func (r *LeaveRequest) Create(leaveRequest *entity.LeaveRequest) error {
-	_, err := r.Insert(
-		"INSERT INTO leave_requests (user_id, start_date, end_date, type, status, reason) VALUES ($1, $2, $3, $4, $5, $6)",
-		leaveRequest.UserId, leaveRequest.StartDate, leaveRequest.EndDate, leaveRequest.Type, leaveRequest.Status, leaveRequest.Reason,
+func mapLeaveRequestExports(rows *sql.Rows, e *entity.LeaveRequestExport) error {
+	return rows.Scan(&e.ID, &e.UserId, &e.StartDate, &e.EndDate, &e.WorkingDays, &e.DurationUnit, &e.StartSession, &e.EndSession, &e.PeriodStart, &e.PeriodEnd, &e.Type, &e.Status, &e.Reason,
+		&e.DecidedBy, &e.DecidedAt, &e.RejectionReason, &e.ApprovalComment, &e.Version, &e.FullName, &e.Email)
+}
+
+// ExportLeaveRequests streams every request GetAllLeaveRequests would list, without paging,
+// to fn together with its owner's name and email.
+func (r *LeaveRequest) ExportLeaveRequests(ctx context.Context, sortBy, orderBy, search string, filter entity.LeaveRequestFilter, fn func(*entity.LeaveRequestExport) error) error {
+	exports := database.BaseSQLRepository[entity.LeaveRequestExport]{DB: r.BaseSQLRepository.DB}
+
+	query := "SELECT " + leaveRequestSelectColumns + ", u.full_name, u.email FROM leave_requests lr JOIN users u ON u.id = lr.user_id"
+	conditions, args, _ := leaveRequestSearchConditions(search, filter)
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += fmt.Sprintf(" ORDER BY lr.%s %s, lr.id", sortBy, orderBy)
+
+	return exports.SelectEach(ctx, mapLeaveRequestExports, fn, query, args...)
+}
+
+func mapAbsences(rows *sql.Rows, a *entity.Absence) error {
+	return rows.Scan(&a.ID, &a.UserId, &a.StartDate, &a.EndDate, &a.WorkingDays, &a.DurationUnit, &a.StartSession, &a.EndSession, &a.PeriodStart, &a.PeriodEnd, &a.Type, &a.Status, &a.Reason,
+		&a.DecidedBy, &a.DecidedAt, &a.RejectionReason, &a.ApprovalComment, &a.Version, &a.FullName)
+}
+
+// GetAbsences returns every request matching filter together with its owner's name, ordered
+// by the start of their period. It is not paginated: bound it with From and To.
+func (r *LeaveRequest) GetAbsences(ctx context.Context, filter entity.LeaveRequestFilter) ([]*entity.Absence, error) {
+	absences := database.BaseSQLRepository[entity.Absence]{DB: r.BaseSQLRepository.DB}
+
+	query := "SELECT " + leaveRequestSelectColumns + ", u.full_name FROM leave_requests lr JOIN users u ON u.id = lr.user_id"
+	conditions, args, _ := leaveRequestFilterConditions(filter, 1)
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY lr.period_start, u.full_name, lr.id"
+
+	return absences.SelectMultiple(ctx, mapAbsences, query, args...)
+}
+
+func mapLeaveRequestEvents(rows *sql.Rows, e *entity.LeaveRequestEvent) error {
+	var payload []byte
+	if err := rows.Scan(&e.ID, &e.LeaveRequestId, &e.ActorId, &e.AutoApprovalRuleId, &e.ActorName, &e.Action, &e.PreviousStatus, &e.NewStatus, &payload, &e.CreatedAt); err != nil {
+		return err
+	}
+	if len(payload) == 0 {
+		return nil
+	}
+	return json.Unmarshal(payload, &e.Payload)
+}
+
+// Create inserts the request and its creation event in a single statement.
+func (r *LeaveRequest) Create(ctx context.Context, leaveRequest *entity.LeaveRequest, actorId int) error {
+	var id int
+	err := r.SelectScalar(ctx,
+		&id,
+		`WITH created AS (
+			INSERT INTO leave_requests (user_id, start_date, end_date, working_days, duration_unit, start_session, end_session, period_start, period_end, type, status, reason)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+			RETURNING id, status
+		)
+		INSERT INTO leave_request_events (leave_request_id, actor_id, action, new_status)
+		SELECT id, $13::int, 'create', status FROM created
+		RETURNING leave_request_id`,
+		leaveRequest.UserId, leaveRequest.StartDate, leaveRequest.EndDate, leaveRequest.WorkingDays, leaveRequest.DurationUnit, leaveRequest.StartSession, leaveRequest.EndSession,
+		leaveRequest.PeriodStart, leaveRequest.PeriodEnd, leaveRequest.Type, leaveRequest.Status, leaveRequest.Reason, actorId,
 	)
-	return err
+	if err != nil {
+		return err
+	}
+
+	leaveRequest.ID = id
+	leaveRequest.Version = 1
+	return nil
 }
 
-func (r *LeaveRequest) Approve(leaveRequestId int) error {
-	_, err := r.ExecuteQuery(
-		"UPDATE leave_requests SET status = 'approved' WHERE id = $1",
-		leaveRequestId,
+// Update overwrites the editable fields of current with those of updated. The changed fields
+// are stored with the edit event.
+func (r *LeaveRequest) Update(ctx context.Context, current, updated *entity.LeaveRequest, actorId int) (bool, error) {
+	changed, err := r.recordTransition(ctx,
+		current, actorId, entity.ActionEdit, current.Changes(updated),
+		`start_date = $8, end_date = $9, working_days = $10, duration_unit = $11, start_session = $12, end_session = $13,
+		period_start = $14, period_end = $15, type = $16, status = $17, reason = $18`,
+		updated.StartDate, updated.EndDate, updated.WorkingDays, updated.DurationUnit, updated.StartSession, updated.EndSession,
+		updated.PeriodStart, updated.PeriodEnd, updated.Type, updated.Status, updated.Reason,
+	)
+	if changed {
+		updated.Version = current.Version
+	}
+	return changed, err
+}
+
+// RecountWorkingDays stores the day count of a request whose days off changed because a
+// public holiday was added, moved or removed. Nobody edited the request, so the change is
+// recorded as an edit without an actor.
+func (r *LeaveRequest) RecountWorkingDays(ctx context.Context, leaveRequest *entity.LeaveRequest, workingDays float64) (bool, error) {
+	recounted := *leaveRequest
+	recounted.WorkingDays = workingDays
+
+	return r.recordTransitionBy(ctx,
+		leaveRequest, nil, nil, entity.ActionEdit, leaveRequest.Changes(&recounted),
+		"working_days = $8",
+		workingDays,
 	)
-	return err
 }
 
-func (r *LeaveRequest) Reject(leaveRequestId int) error {
-	_, err := r.ExecuteQuery(
-		"UPDATE leave_requests SET status = 'rejected' WHERE id = $1",
+// recordTransition applies the SET clause set to the leave request and appends the matching
+// history entry in the same statement, so the change and its history are written atomically.
+// The row is only changed, and its version bumped, while it still has the status and version
+// of current; on success current.Version is moved on to the new version. Placeholders $1 to $6
+// hold the leave request id, the actor, the action, the payload and the expected status and
+// version, and $7 the auto-approval rule that took the decision; args continue from $8.
+func (r *LeaveRequest) recordTransition(ctx context.Context, current *entity.LeaveRequest, actorId int, action entity.LeaveRequestAction, payload map[string]any, set string, args ...any) (bool, error) {
+	return r.recordTransitionBy(ctx, current, &actorId, nil, action, payload, set, args...)
+}
+
+// recordTransitionBy is recordTransition for a change taken either by a user or by an
+// auto-approval rule.
+func (r *LeaveRequest) recordTransitionBy(ctx context.Context, current *entity.LeaveRequest, actorId, ruleId *int, action entity.LeaveRequestAction, payload map[string]any, set string, args ...any) (bool, error) {
+	var encodedPayload *string
+	if len(payload) > 0 {
+		encoded, err := json.Marshal(payload)
+		if err != nil {
+			return false, err
+		}
+		value := string(encoded)
+		encodedPayload = &value
+	}
+
+	result, err := r.ExecuteQuery(ctx,
+		`WITH previous AS (
+			SELECT id, status FROM leave_requests WHERE id = $1 AND status = $5 AND version = $6 FOR UPDATE
+		), changed AS (
+			UPDATE leave_requests lr SET `+set+`, version = lr.version + 1, updated_at = CURRENT_TIMESTAMP
+			FROM previous WHERE lr.id = previous.id
+			RETURNING lr.id, previous.status AS previous_status, lr.status
+		)
+		INSERT INTO leave_request_events (leave_request_id, actor_id, auto_approval_rule_id, action, previous_status, new_status, payload)
+		SELECT id, $2::int, $7::int, $3::leave_request_action_enum, previous_status, status, $4::jsonb FROM changed`,
+		append([]any{current.ID, actorId, action, encodedPayload, current.Status, current.Version, ruleId}, args...)...,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	if affected == 0 {
+		return false, nil
+	}
+
+	current.Version++
+	return true, nil
+}
+
+func (r *LeaveRequest) GetEvents(ctx context.Context, leaveRequestId int) ([]*entity.LeaveRequestEvent, error) {
+	events := database.BaseSQLRepository[entity.LeaveRequestEvent]{DB: r.BaseSQLRepository.DB}
+
+	return events.SelectMultiple(ctx,
+		mapLeaveRequestEvents,
+		`SELECT e.id, e.leave_request_id, e.actor_id, e.auto_approval_rule_id, COALESCE(u.full_name, ar.name, e.payload->>'rule', ''), e.action, COALESCE(e.previous_status::text, ''), COALESCE(e.new_status::text, ''), e.payload, e.created_at
+		FROM leave_request_events e
+		LEFT JOIN users u ON u.id = e.actor_id
+		LEFT JOIN auto_approval_rules ar ON ar.id = e.auto_approval_rule_id
+		WHERE e.leave_request_id = $1 ORDER BY e.created_at, e.id`,
 		leaveRequestId,
 	)
-	return err
 }
 
-func (r *LeaveRequest) OverlapApprovedLeaveExists(userId int, startDate, endDate time.Time) (bool, error) {
-	query := `SELECT lr.id, lr.user_id, lr.start_date, lr.end_date, lr.type, lr.status, lr.reason 
+// Delete removes the request and records who deleted it. Its history is kept, so the
+// events of a deleted request can still be read.
+func (r *LeaveRequest) Delete(ctx context.Context, leaveRequest *entity.LeaveRequest, actorId int) (bool, error) {
+	result, err := r.ExecuteQuery(ctx,
+		`WITH deleted AS (
+			DELETE FROM leave_requests WHERE id = $1 AND status = $2 AND version = $3 RETURNING id, status
+		)
+		INSERT INTO leave_request_events (leave_request_id, actor_id, action, previous_status)
+		SELECT id, $4, $5::leave_request_action_enum, status FROM deleted`,
+		leaveRequest.ID, leaveRequest.Status, leaveRequest.Version, actorId, entity.ActionDelete,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
+
+func (r *LeaveRequest) Approve(ctx context.Context, leaveRequest *entity.LeaveRequest, decidedBy int, comment string) (bool, error) {
+	var payload map[string]any
+	if comment != "" {
+		payload = map[string]any{"comment": comment}
+	}
+
+	return r.recordTransition(ctx,
+		leaveRequest, decidedBy, entity.ActionApprove, payload,
+		"status = 'approved', decided_by = $2, decided_at = CURRENT_TIMESTAMP, approval_comment = NULLIF($8, '')",
+		comment,
+	)
+}
+
+// AutoApprove approves the request on behalf of an auto-approval rule. No user decided it,
+// so decided_by stays empty; the history names the rule, and keeps its name in the payload
+// should the rule be deleted later.
+func (r *LeaveRequest) AutoApprove(ctx context.Context, leaveRequest *entity.LeaveRequest, rule *entity.AutoApprovalRule, comment string) (bool, error) {
+	return r.recordTransitionBy(ctx,
+		leaveRequest, nil, &rule.ID, entity.ActionApprove, map[string]any{"rule": rule.Name, "comment": comment},
+		"status = 'approved', decided_by = NULL, decided_at = CURRENT_TIMESTAMP, approval_comment = NULLIF($8, '')",
+		comment,
+	)
+}
+
+func (r *LeaveRequest) Reject(ctx context.Context, leaveRequest *entity.LeaveRequest, decidedBy int, reason string) (bool, error) {
+	return r.recordTransition(ctx,
+		leaveRequest, decidedBy, entity.ActionReject, map[string]any{"reason": reason},
+		"status = 'rejected', decided_by = $2, decided_at = CURRENT_TIMESTAMP, rejection_reason = $8",
+		reason,
+	)
+}
+
+// OverlapApprovedLeaveExists reports whether an approved request of the user intersects
+// the wall-clock period [periodStart, periodEnd), so half days and hourly leave on the same
+// date only clash when their sessions or hours meet. It applies the same rule as the
+// excl_leave_requests_approved_overlap constraint, weekends and public holidays included,
+// so a request it lets through is not refused when it is approved. Leave awaiting
+// cancellation is still booked.
+func (r *LeaveRequest) OverlapApprovedLeaveExists(ctx context.Context, userId int, periodStart, periodEnd time.Time) (bool, error) {
+	query := `SELECT ` + leaveRequestSelectColumns + `
               FROM leave_requests lr 
               WHERE lr.user_id = $1 
-              AND lr.status = 'approved' 
-              AND NOT (lr.end_date < $2 OR lr.start_date > $3)`
+              AND lr.status IN ('approved', 'cancellation_requested') 
+              AND tsrange(lr.period_start, lr.period_end) && tsrange($2::timestamp, $3::timestamp)`
 
-	rows, err := r.SelectMultiple(
+	rows, err := r.SelectMultiple(ctx,
 		mapLeaveRequests,
 		query,
-		userId, startDate, endDate,
+		userId, periodStart, periodEnd,
 	)
 
 	if err != nil {
@@ -137,10 +443,36 @@ func (r *LeaveRequest) OverlapApprovedLeaveExists(userId int, startDate, endDate
 	return false, nil
 }
 
-func (r *LeaveRequest) Submit(leaveRequestId int) error {
-	_, err := r.ExecuteQuery(
-		"UPDATE leave_requests SET status = 'waiting_approval' WHERE id = $1",
-		leaveRequestId,
+// CountTeamAbsences counts the teammates of the user, those with the same manager, who have
+// approved leave during [periodStart, periodEnd). Users without a manager have no team.
+func (r *LeaveRequest) CountTeamAbsences(ctx context.Context, userId int, periodStart, periodEnd time.Time) (int, error) {
+	var count int
+	err := r.SelectScalar(ctx,
+		&count,
+		`SELECT COUNT(DISTINCT lr.user_id)
+		FROM leave_requests lr
+		JOIN users teammate ON teammate.id = lr.user_id
+		JOIN users u ON u.id = $1 AND u.manager_id = teammate.manager_id
+		WHERE lr.user_id <> $1
+		AND lr.status IN ('approved', 'cancellation_requested')
+		AND lr.period_start < $3::timestamp AND lr.period_end > $2::timestamp`,
+		userId, periodStart, periodEnd,
 	)
-	return err
+	return count, err
+}
+
+func (r *LeaveRequest) Submit(ctx context.Context, leaveRequest *entity.LeaveRequest, actorId int) (bool, error) {
+	return r.recordTransition(ctx, leaveRequest, actorId, entity.ActionSubmit, nil, "status = 'waiting_approval'")
+}
+
+func (r *LeaveRequest) Cancel(ctx context.Context, leaveRequest *entity.LeaveRequest, actorId int) (bool, error) {
+	return r.recordTransition(ctx, leaveRequest, actorId, entity.ActionCancel, nil, "status = 'cancelled'")
+}
+
+func (r *LeaveRequest) RequestCancellation(ctx context.Context, leaveRequest *entity.LeaveRequest, actorId int) (bool, error) {
+	return r.recordTransition(ctx, leaveRequest, actorId, entity.ActionRequestCancellation, nil, "status = 'cancellation_requested'")
+}
+
+func (r *LeaveRequest) RejectCancellation(ctx context.Context, leaveRequest *entity.LeaveRequest, actorId int) (bool, error) {
+	return r.recordTransition(ctx, leaveRequest, actorId, entity.ActionRejectCancellation, nil, "status = 'approved'")
 }
@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"database/sql/driver"
 	"errors"
 	"testing"
@@ -14,7 +15,9 @@ import (
 var ErrSimulatedDB = errors.New("simulated DB error")
 
 var leaveRequestColumns = []string{
-	"id", "user_id", "start_date", "end_date", "type", "status", "reason",
+	"id", "user_id", "start_date", "end_date", "working_days", "duration_unit", "start_session", "end_session",
+	"period_start", "period_end", "type", "status", "reason",
+	"decided_by", "decided_at", "rejection_reason", "approval_comment", "version",
 }
 
 func setupMockDB(t *testing.T) (*LeaveRequest, sqlmock.Sqlmock) {
@@ -43,7 +46,11 @@ func TestOverlapApprovedLeaveExists(t *testing.T) {
 		1, userID,
 		time.Date(2025, time.February, 5, 0, 0, 0, 0, time.UTC),
 		time.Date(2025, time.February, 15, 0, 0, 0, 0, time.UTC),
+		9, "day", "am", "pm",
+		time.Date(2025, time.February, 5, 0, 0, 0, 0, time.UTC),
+		time.Date(2025, time.February, 16, 0, 0, 0, 0, time.UTC),
 		"ANNUAL", "approved", "Holiday",
+		7, time.Date(2025, time.January, 20, 9, 0, 0, 0, time.UTC), "", "", 2,
 	}
 
 	tests := []struct {
@@ -66,7 +73,27 @@ func TestOverlapApprovedLeaveExists(t *testing.T) {
 			},
 		},
 		{
-			name:          "Case 2: No Overlap Found (Returns 0 Rows)",
+			name:          "Case 2: Weekend-only Overlap Found",
+			expectedExist: true,
+			expectedError: nil,
+			mockExpect: func(mock sqlmock.Sqlmock, userID int, start, end time.Time) {
+				// Approved Thursday to Saturday against the new Saturday to Tuesday: they only
+				// share the Saturday, which the exclusion constraint still refuses.
+				thursday := time.Date(2025, time.February, 6, 0, 0, 0, 0, time.UTC)
+				saturday := thursday.AddDate(0, 0, 2)
+				weekendRow := append([]driver.Value{}, overlappingRow...)
+				weekendRow[2], weekendRow[3] = thursday, saturday
+				weekendRow[8], weekendRow[9] = thursday, saturday.AddDate(0, 0, 1)
+				mock.ExpectQuery(`tsrange\(lr.period_start, lr.period_end\) && tsrange\(\$2::timestamp, \$3::timestamp\)`).
+					WithArgs(userID, start, end).
+					WillReturnRows(
+						sqlmock.NewRows(leaveRequestColumns).
+							AddRow(weekendRow...),
+					)
+			},
+		},
+		{
+			name:          "Case 3: No Overlap Found (Returns 0 Rows)",
 			expectedExist: false,
 			expectedError: nil,
 			mockExpect: func(mock sqlmock.Sqlmock, userID int, start, end time.Time) {
@@ -76,7 +103,7 @@ func TestOverlapApprovedLeaveExists(t *testing.T) {
 			},
 		},
 		{
-			name:          "Case 3: Database Error Occurred",
+			name:          "Case 4: Database Error Occurred",
 			expectedExist: false,
 			expectedError: ErrSimulatedDB,
 			mockExpect: func(mock sqlmock.Sqlmock, userID int, start, end time.Time) {
@@ -94,7 +121,7 @@ func TestOverlapApprovedLeaveExists(t *testing.T) {
 
 			tt.mockExpect(mock, userID, startDate, endDate)
 
-			exists, err := repo.OverlapApprovedLeaveExists(userID, startDate, endDate)
+			exists, err := repo.OverlapApprovedLeaveExists(context.Background(), userID, startDate, endDate)
 
 			if errMock := mock.ExpectationsWereMet(); errMock != nil {
 				t.Fatalf("Mock expectations not met: %s", errMock)
@@ -114,3 +141,97 @@ func TestOverlapApprovedLeaveExists(t *testing.T) {
 		})
 	}
 }
+
+func TestDeleteRecordsEvent(t *testing.T) {
+	repo, mock := setupMockDB(t)
+	defer repo.DB.Close()
+
+	leaveRequest := &entity.LeaveRequest{ID: 7, Status: entity.WaitingApproval, Version: 2}
+	mock.ExpectExec(`WITH deleted AS \(\s*DELETE FROM leave_requests.+INSERT INTO leave_request_events`).
+		WithArgs(7, entity.WaitingApproval, 2, 1, entity.ActionDelete).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	deleted, err := repo.Delete(context.Background(), leaveRequest, 1)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !deleted {
+		t.Errorf("Expected the request to be deleted")
+	}
+
+	if errMock := mock.ExpectationsWereMet(); errMock != nil {
+		t.Fatalf("Mock expectations not met: %s", errMock)
+	}
+}
+
+func TestRejectRecordsEvent(t *testing.T) {
+	repo, mock := setupMockDB(t)
+	defer repo.DB.Close()
+
+	leaveRequest := &entity.LeaveRequest{ID: 7, Status: entity.WaitingApproval, Version: 3}
+	mock.ExpectExec(`WITH previous AS \(.+status = \$5 AND version = \$6 FOR UPDATE.+version = lr.version \+ 1.+INSERT INTO leave_request_events`).
+		WithArgs(7, 9, entity.ActionReject, `{"reason":"Team is short-staffed"}`, entity.WaitingApproval, 3, nil, "Team is short-staffed").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	rejected, err := repo.Reject(context.Background(), leaveRequest, 9, "Team is short-staffed")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !rejected || leaveRequest.Version != 4 {
+		t.Errorf("Expected the request to move to version 4, got rejected=%t version=%d", rejected, leaveRequest.Version)
+	}
+
+	if errMock := mock.ExpectationsWereMet(); errMock != nil {
+		t.Fatalf("Mock expectations not met: %s", errMock)
+	}
+}
+
+func TestStaleTransitionChangesNothing(t *testing.T) {
+	repo, mock := setupMockDB(t)
+	defer repo.DB.Close()
+
+	leaveRequest := &entity.LeaveRequest{ID: 7, Status: entity.WaitingApproval, Version: 3}
+	mock.ExpectExec(`WITH previous AS`).
+		WithArgs(7, 9, entity.ActionApprove, nil, entity.WaitingApproval, 3, nil, "").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	approved, err := repo.Approve(context.Background(), leaveRequest, 9, "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if approved || leaveRequest.Version != 3 {
+		t.Errorf("Expected a stale request to stay at version 3, got approved=%t version=%d", approved, leaveRequest.Version)
+	}
+
+	if errMock := mock.ExpectationsWereMet(); errMock != nil {
+		t.Fatalf("Mock expectations not met: %s", errMock)
+	}
+}
+
+func TestGetEventsDecodesPayload(t *testing.T) {
+	repo, mock := setupMockDB(t)
+	defer repo.DB.Close()
+
+	createdAt := time.Date(2025, time.February, 1, 9, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(`FROM leave_request_events e`).
+		WithArgs(7).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "leave_request_id", "actor_id", "auto_approval_rule_id", "actor_name", "action", "previous_status", "new_status", "payload", "created_at"}).
+			AddRow(1, 7, 1, nil, "Jane Doe", "create", "", "draft", nil, createdAt).
+			AddRow(2, 7, 1, nil, "Jane Doe", "edit", "draft", "draft", []byte(`{"reason":{"from":"Family trip","to":"Family wedding"}}`), createdAt))
+
+	events, err := repo.GetEvents(context.Background(), 7)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(events) != 2 {
+		t.Fatalf("Expected 2 events, got %d", len(events))
+	}
+	if events[0].Payload != nil {
+		t.Errorf("Expected no payload for the creation event, got %v", events[0].Payload)
+	}
+	reason, ok := events[1].Payload["reason"].(map[string]any)
+	if !ok || reason["to"] != "Family wedding" {
+		t.Errorf("Unexpected edit payload: %v", events[1].Payload)
+	}
+}
@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/devonLoen/leave-request-service/internal/app/rest_api/database"
+	entity "github.com/devonLoen/leave-request-service/internal/app/rest_api/entity"
+	"github.com/lib/pq"
+)
+
+type LeaveTypeRepository interface {
+	GetAll(ctx context.Context) ([]*entity.LeaveType, error)
+	FindByCode(ctx context.Context, code entity.LeaveRequestType) (*entity.LeaveType, error)
+	Upsert(ctx context.Context, leaveType *entity.LeaveType) error
+}
+
+type LeaveType struct {
+	database.BaseSQLRepository[entity.LeaveType]
+}
+
+func NewLeaveTypeRepository(db database.Querier) *LeaveType {
+	return &LeaveType{
+		BaseSQLRepository: database.BaseSQLRepository[entity.LeaveType]{DB: db},
+	}
+}
+
+const leaveTypeSelectColumns = `lt.code, lt.name, lt.paid, lt.deducts_balance, lt.requires_attachment, lt.attachment_after_days,
+	lt.max_consecutive_days, lt.min_notice_days, lt.allowed_roles, lt.active`
+
+type leaveTypeScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanLeaveType(row leaveTypeScanner, t *entity.LeaveType) error {
+	var roles pq.StringArray
+	var maxConsecutiveDays sql.NullFloat64
+	if err := row.Scan(&t.Code, &t.Name, &t.Paid, &t.DeductsBalance, &t.RequiresAttachment, &t.AttachmentAfterDays,
+		&maxConsecutiveDays, &t.MinNoticeDays, &roles, &t.Active); err != nil {
+		return err
+	}
+
+	if maxConsecutiveDays.Valid {
+		t.MaxConsecutiveDays = &maxConsecutiveDays.Float64
+	}
+	t.AllowedRoles = make([]entity.UserRole, 0, len(roles))
+	for _, role := range roles {
+		t.AllowedRoles = append(t.AllowedRoles, entity.UserRole(role))
+	}
+	return nil
+}
+
+func mapLeaveType(row *sql.Row, t *entity.LeaveType) error {
+	return scanLeaveType(row, t)
+}
+
+func mapLeaveTypes(rows *sql.Rows, t *entity.LeaveType) error {
+	return scanLeaveType(rows, t)
+}
+
+func (r *LeaveType) GetAll(ctx context.Context) ([]*entity.LeaveType, error) {
+	return r.SelectMultiple(ctx,
+		mapLeaveTypes,
+		"SELECT "+leaveTypeSelectColumns+" FROM leave_types lt ORDER BY lt.code",
+	)
+}
+
+func (r *LeaveType) FindByCode(ctx context.Context, code entity.LeaveRequestType) (*entity.LeaveType, error) {
+	return r.SelectSingle(ctx,
+		mapLeaveType,
+		"SELECT "+leaveTypeSelectColumns+" FROM leave_types lt WHERE lt.code = $1",
+		code,
+	)
+}
+
+// Upsert adds the leave type or replaces the attributes of the one with the same code.
+func (r *LeaveType) Upsert(ctx context.Context, leaveType *entity.LeaveType) error {
+	roles := make(pq.StringArray, 0, len(leaveType.AllowedRoles))
+	for _, role := range leaveType.AllowedRoles {
+		roles = append(roles, string(role))
+	}
+
+	_, err := r.ExecuteQuery(ctx,
+		`INSERT INTO leave_types (code, name, paid, deducts_balance, requires_attachment, attachment_after_days, max_consecutive_days, min_notice_days, allowed_roles, active)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9::role_type[], $10)
+		ON CONFLICT (code) DO UPDATE SET name = EXCLUDED.name, paid = EXCLUDED.paid, deducts_balance = EXCLUDED.deducts_balance,
+		requires_attachment = EXCLUDED.requires_attachment, attachment_after_days = EXCLUDED.attachment_after_days,
+		max_consecutive_days = EXCLUDED.max_consecutive_days, min_notice_days = EXCLUDED.min_notice_days,
+		allowed_roles = EXCLUDED.allowed_roles, active = EXCLUDED.active, updated_at = CURRENT_TIMESTAMP`,
+		leaveType.Code, leaveType.Name, leaveType.Paid, leaveType.DeductsBalance, leaveType.RequiresAttachment, leaveType.AttachmentAfterDays,
+		leaveType.MaxConsecutiveDays, leaveType.MinNoticeDays, roles, leaveType.Active,
+	)
+	return err
+}
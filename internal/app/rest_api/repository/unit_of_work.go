@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/devonLoen/leave-request-service/internal/app/rest_api/database"
+)
+
+// Repositories are bound to the transaction of one unit of work.
+type Repositories struct {
+	User         *User
+	LeaveRequest LeaveRequestRepository
+	LeaveBalance LeaveBalanceRepository
+	Approval     ApprovalRepository
+	Holiday      HolidayRepository
+}
+
+// UnitOfWork makes several changes atomically. fn gets repositories bound to one
+// transaction, which is committed when fn returns nil and rolled back otherwise.
+type UnitOfWork interface {
+	Do(ctx context.Context, fn func(repos *Repositories) error) error
+}
+
+type SQLUnitOfWork struct {
+	db *sql.DB
+}
+
+func NewUnitOfWork(db *sql.DB) *SQLUnitOfWork {
+	return &SQLUnitOfWork{db: db}
+}
+
+func (u *SQLUnitOfWork) Do(ctx context.Context, fn func(repos *Repositories) error) error {
+	return database.RunInTx(ctx, u.db, func(tx *sql.Tx) error {
+		return fn(&Repositories{
+			User:         NewUserRepository(tx),
+			LeaveRequest: NewLeaveRequestRepository(tx),
+			LeaveBalance: NewLeaveBalanceRepository(tx),
+			Approval:     NewApprovalRepository(tx),
+			Holiday:      NewHolidayRepository(tx),
+		})
+	})
+}
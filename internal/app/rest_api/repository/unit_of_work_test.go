@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	entity "github.com/devonLoen/leave-request-service/internal/app/rest_api/entity"
+)
+
+func TestUnitOfWork(t *testing.T) {
+	tests := []struct {
+		name      string
+		setupMock func(mock sqlmock.Sqlmock)
+		fail      bool
+	}{
+		{
+			name: "Commits when every change succeeds",
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`WITH previous AS`).
+					WithArgs(7, 9, entity.ActionSubmit, nil, entity.Draft, 1, nil).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(`SELECT u.id`).WithArgs(9).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "full_name", "email", "role", "manager_id", "department", "hire_date"}).
+						AddRow(9, "Super Admin", "root@example.com", "superadmin", nil, "", time.Date(2020, time.January, 6, 0, 0, 0, 0, time.UTC)))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "Rolls back when a change fails",
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`WITH previous AS`).
+					WithArgs(7, 9, entity.ActionSubmit, nil, entity.Draft, 1, nil).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(`SELECT u.id`).WithArgs(9).
+					WillReturnError(ErrSimulatedDB)
+				mock.ExpectRollback()
+			},
+			fail: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("Error creating mock database: %v", err)
+			}
+			defer db.Close()
+			tt.setupMock(mock)
+
+			err = NewUnitOfWork(db).Do(context.Background(), func(repos *Repositories) error {
+				if _, err := repos.LeaveRequest.Submit(context.Background(), &entity.LeaveRequest{ID: 7, Status: entity.Draft, Version: 1}, 9); err != nil {
+					return err
+				}
+				_, err := repos.User.FindById(context.Background(), 9)
+				return err
+			})
+
+			if tt.fail != (err != nil) {
+				t.Errorf("Expected failure %t, got %v", tt.fail, err)
+			}
+			if errMock := mock.ExpectationsWereMet(); errMock != nil {
+				t.Fatalf("Mock expectations not met: %s", errMock)
+			}
+		})
+	}
+}
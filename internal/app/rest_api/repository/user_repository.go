@@ -1,9 +1,11 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/devonLoen/leave-request-service/internal/app/rest_api/database"
 	entity "github.com/devonLoen/leave-request-service/internal/app/rest_api/entity"
@@ -13,50 +15,52 @@ type User struct {
 	database.BaseSQLRepository[entity.User]
 }
 
-func NewUserRepository(db *sql.DB) *User {
+func NewUserRepository(db database.Querier) *User {
 	return &User{
 		BaseSQLRepository: database.BaseSQLRepository[entity.User]{DB: db},
 	}
 }
 
+const userSelectColumns = "u.id, u.full_name, u.email, u.role, u.manager_id, COALESCE(u.department, ''), u.hire_date"
+
 func mapUser(rows *sql.Row, u *entity.User) error {
-	return rows.Scan(&u.ID, &u.FullName, &u.Email, &u.Role)
+	return rows.Scan(&u.ID, &u.FullName, &u.Email, &u.Role, &u.ManagerId, &u.Department, &u.HireDate)
 }
 
 func mapUserWithPassword(rows *sql.Row, u *entity.User) error {
-	return rows.Scan(&u.ID, &u.FullName, &u.Email, &u.Role, &u.Password)
+	return rows.Scan(&u.ID, &u.FullName, &u.Email, &u.Role, &u.ManagerId, &u.Department, &u.HireDate, &u.Password)
 }
 
 func mapUsers(rows *sql.Rows, u *entity.User) error {
-	return rows.Scan(&u.ID, &u.FullName, &u.Email, &u.Role)
+	return rows.Scan(&u.ID, &u.FullName, &u.Email, &u.Role, &u.ManagerId, &u.Department, &u.HireDate)
 }
 
-func (r *User) FindByEmail(email string) (*entity.User, error) {
-	return r.SelectSingle(
+func (r *User) FindByEmail(ctx context.Context, email string) (*entity.User, error) {
+	return r.SelectSingle(ctx,
 		mapUser,
-		"SELECT u.id, u.full_name, u.email, u.role FROM users u WHERE u.email = $1",
+		"SELECT "+userSelectColumns+" FROM users u WHERE LOWER(u.email) = LOWER($1)",
 		email,
 	)
 }
 
-func (r *User) FindByEmailWithPassword(email string) (*entity.User, error) {
-	return r.SelectSingle(
+func (r *User) FindByEmailWithPassword(ctx context.Context, email string) (*entity.User, error) {
+	return r.SelectSingle(ctx,
 		mapUserWithPassword,
-		"SELECT u.id, u.full_name, u.email, u.role, u.password FROM users u WHERE u.email = $1",
+		"SELECT "+userSelectColumns+", u.password FROM users u WHERE u.email = $1",
 		email,
 	)
 }
 
-func (r *User) FindById(id int) (*entity.User, error) {
-	return r.SelectSingle(
+func (r *User) FindById(ctx context.Context, id int) (*entity.User, error) {
+	return r.SelectSingle(ctx,
 		mapUser,
-		"SELECT u.id, u.full_name, u.email, u.role FROM users u WHERE u.id = $1",
+		"SELECT "+userSelectColumns+" FROM users u WHERE u.id = $1",
 		id,
 	)
 }
 
-func (r *User) GetAllUsers(limit, offset int, sortBy, orderBy, search string, filter entity.UserFilter) ([]*entity.User, error) {
-	baseQuery := "SELECT u.id, u.full_name, u.email, u.role FROM users u"
+func (r *User) GetAllUsers(ctx context.Context, limit, offset int, sortBy, orderBy, search string, filter entity.UserFilter) ([]*entity.User, error) {
+	baseQuery := "SELECT " + userSelectColumns + " FROM users u"
 	var conditions []string
 	var args []interface{}
 
@@ -84,18 +88,56 @@ func (r *User) GetAllUsers(limit, offset int, sortBy, orderBy, search string, fi
 	baseQuery += fmt.Sprintf(" ORDER BY u.%s %s LIMIT $%d OFFSET $%d", sortBy, orderBy, argId, argId+1)
 	args = append(args, limit, offset)
 
-	return r.SelectMultiple(
+	return r.SelectMultiple(ctx,
 		mapUsers,
 		baseQuery,
 		args...,
 	)
 }
 
-func (r *User) Create(user *entity.User) error {
-	_, err := r.Insert(
-		"INSERT INTO users (full_name, email, password, role) VALUES ($1, $2, $3, $4)",
-		user.FullName, user.Email, user.Password, user.Role,
+// GetUsersHiredBy returns the users whose hire date is on or before the given day.
+func (r *User) GetUsersHiredBy(ctx context.Context, on time.Time) ([]*entity.User, error) {
+	return r.SelectMultiple(ctx,
+		mapUsers,
+		"SELECT "+userSelectColumns+" FROM users u WHERE u.hire_date <= $1 ORDER BY u.id",
+		on,
+	)
+}
+
+func (r *User) Create(ctx context.Context, user *entity.User) error {
+	id, err := r.Insert(ctx,
+		"INSERT INTO users (full_name, email, password, role, manager_id, department, hire_date) VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), $7)",
+		user.FullName, user.Email, user.Password, user.Role, user.ManagerId, user.Department, user.HireDate,
 	)
+	if err != nil {
+		return err
+	}
 
+	user.ID = id
+	return nil
+}
+
+func (r *User) SetManager(ctx context.Context, userId int, managerId *int) error {
+	_, err := r.ExecuteQuery(ctx,
+		"UPDATE users SET manager_id = $2, updated_at = CURRENT_TIMESTAMP WHERE id = $1",
+		userId, managerId,
+	)
 	return err
 }
+
+// IsManagerOf reports whether managerId is above userId in the reporting line, either as
+// the direct manager or further up the chain.
+func (r *User) IsManagerOf(ctx context.Context, managerId, userId int) (bool, error) {
+	var isManager bool
+	err := r.SelectScalar(ctx,
+		&isManager,
+		`WITH RECURSIVE chain AS (
+			SELECT u.manager_id FROM users u WHERE u.id = $2
+			UNION
+			SELECT u.manager_id FROM users u JOIN chain c ON u.id = c.manager_id
+		)
+		SELECT EXISTS (SELECT 1 FROM chain WHERE manager_id = $1)`,
+		managerId, userId,
+	)
+	return isManager, err
+}
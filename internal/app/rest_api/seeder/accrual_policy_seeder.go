@@ -0,0 +1,33 @@
+package seeder
+
+import (
+	"database/sql"
+	"log"
+
+	entity "github.com/devonLoen/leave-request-service/internal/app/rest_api/entity"
+)
+
+// SeedAccrualPolicies inserts the default accrual policies without touching ones
+// that were already configured by an admin.
+func SeedAccrualPolicies(db *sql.DB) {
+	policies := []entity.AccrualPolicy{
+		{Type: entity.Annual, DaysPerMonth: 1, CarryOverCap: 5, CarryOverExpiryMonths: 3, Active: true},
+		{Type: entity.Sick, DaysPerMonth: 1, CarryOverCap: 0, CarryOverExpiryMonths: 0, Active: true},
+	}
+
+	query := `
+		INSERT INTO accrual_policies (type, days_per_month, carry_over_cap, carry_over_expiry_months, active)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (type) DO NOTHING;
+	`
+
+	for _, policy := range policies {
+		_, err := db.Exec(query, policy.Type, policy.DaysPerMonth, policy.CarryOverCap, policy.CarryOverExpiryMonths, policy.Active)
+		if err != nil {
+			log.Println("Failed to insert accrual policy:", err)
+			return
+		}
+	}
+
+	log.Println("Accrual policy seeder finished successfully.")
+}
@@ -0,0 +1,36 @@
+package seeder
+
+import (
+	"database/sql"
+	"log"
+)
+
+// SeedApprovalChains inserts the default approval chains: unpaid leave and annual leave of
+// five working days or more need both the line manager and HR. Chains an admin already
+// configured are left alone.
+func SeedApprovalChains(db *sql.DB) {
+	chains := []struct {
+		Type           string
+		MinWorkingDays float64
+		Steps          string
+	}{
+		{Type: "unpaid", MinWorkingDays: 0, Steps: "{manager,admin}"},
+		{Type: "annual", MinWorkingDays: 5, Steps: "{manager,admin}"},
+	}
+
+	query := `
+		INSERT INTO approval_chains (type, min_working_days, steps)
+		VALUES ($1, $2, $3::approver_kind_enum[])
+		ON CONFLICT (type, min_working_days) DO NOTHING;
+	`
+
+	for _, chain := range chains {
+		_, err := db.Exec(query, chain.Type, chain.MinWorkingDays, chain.Steps)
+		if err != nil {
+			log.Println("Failed to insert approval chain:", err)
+			return
+		}
+	}
+
+	log.Println("Approval chain seeder finished successfully.")
+}
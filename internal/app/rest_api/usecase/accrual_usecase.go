@@ -0,0 +1,242 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"time"
+
+	entity "github.com/devonLoen/leave-request-service/internal/app/rest_api/entity"
+	models "github.com/devonLoen/leave-request-service/internal/app/rest_api/model"
+	"github.com/devonLoen/leave-request-service/internal/app/rest_api/model/dto"
+	"github.com/devonLoen/leave-request-service/internal/app/rest_api/repository"
+)
+
+type AccrualUsecase interface {
+	Run(ctx context.Context, from, to time.Time) (*entity.AccrualRunResult, error)
+	GetPolicies(ctx context.Context) (*dto.GetAccrualPoliciesResponse, *models.ErrorResponse)
+	UpsertPolicy(ctx context.Context, leaveType string, req *dto.UpsertAccrualPolicyRequest) (*dto.AccrualPolicyResponse, *models.ErrorResponse)
+}
+
+type Accrual struct {
+	accrualPolicyRepo repository.AccrualPolicyRepository
+	leaveTypeRepo     repository.LeaveTypeRepository
+	leavePolicyRepo   repository.LeavePolicyRepository
+	leaveBalanceRepo  repository.LeaveBalanceRepository
+	userRepo          *repository.User
+}
+
+func NewAccrualUsecase(accrualPolicyRepo repository.AccrualPolicyRepository, leaveTypeRepo repository.LeaveTypeRepository, leavePolicyRepo repository.LeavePolicyRepository, leaveBalanceRepo repository.LeaveBalanceRepository, userRepo *repository.User) *Accrual {
+	return &Accrual{accrualPolicyRepo: accrualPolicyRepo, leaveTypeRepo: leaveTypeRepo, leavePolicyRepo: leavePolicyRepo, leaveBalanceRepo: leaveBalanceRepo, userRepo: userRepo}
+}
+
+// Run posts the ledger entries due for every accrual period (the first day of a month)
+// between from and to, inclusive. Each period expires unused carry-over that reached its
+// deadline, carries the previous year's balance over on January 1st and then accrues the
+// monthly entitlement, which a leave policy applying to the user on that day may replace.
+// Only users hired by the period accrue it, so the month someone joins in is not credited
+// unless they start on its first day. Entries are keyed by period, so a range can be
+// replayed safely.
+func (us *Accrual) Run(ctx context.Context, from, to time.Time) (*entity.AccrualRunResult, error) {
+	from = time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	to = time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+	result := &entity.AccrualRunResult{From: from, To: to}
+
+	policies, err := us.accrualPolicyRepo.GetActivePolicies(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("load accrual policies: %w", err)
+	}
+
+	period := time.Date(from.Year(), from.Month(), 1, 0, 0, 0, 0, time.UTC)
+	if period.Before(from) {
+		period = period.AddDate(0, 1, 0)
+	}
+
+	for ; !period.After(to); period = period.AddDate(0, 1, 0) {
+		users, err := us.userRepo.GetUsersHiredBy(ctx, period)
+		if err != nil {
+			return nil, fmt.Errorf("load users for %s: %w", period.Format(time.DateOnly), err)
+		}
+
+		for _, policy := range policies {
+			for _, user := range users {
+				if err := us.runPeriod(ctx, policy, user.ID, period, result); err != nil {
+					return nil, fmt.Errorf("accrue %s leave for user %d on %s: %w", policy.Type, user.ID, period.Format(time.DateOnly), err)
+				}
+			}
+		}
+	}
+
+	return result, nil
+}
+
+func (us *Accrual) runPeriod(ctx context.Context, policy *entity.AccrualPolicy, userId int, period time.Time, result *entity.AccrualRunResult) error {
+	origin := period.AddDate(0, -policy.CarryOverExpiryMonths, 0)
+	if expiresAt, ok := policy.CarryOverExpiresAt(origin.Year()); ok && expiresAt.Equal(period) {
+		posted, err := us.expireCarryOver(ctx, policy, userId, origin.Year(), period)
+		if err != nil {
+			return err
+		}
+		if posted {
+			result.Expired++
+		}
+	}
+
+	if period.Month() == time.January {
+		posted, err := us.carryOver(ctx, policy, userId, period)
+		if err != nil {
+			return err
+		}
+		if posted {
+			result.CarriedOver++
+		}
+	}
+
+	days, err := us.monthlyAccrual(ctx, policy, userId, period)
+	if err != nil {
+		return err
+	}
+
+	if days > 0 {
+		posted, err := us.leaveBalanceRepo.AddPeriodEntry(ctx, &entity.LeaveBalanceEntry{
+			UserId:        userId,
+			LeaveYear:     period.Year(),
+			Type:          policy.Type,
+			EntryType:     entity.BalanceAccrual,
+			Days:          days,
+			Note:          fmt.Sprintf("Monthly accrual for %s", period.Format("January 2006")),
+			AccrualPeriod: &period,
+		})
+		if err != nil {
+			return err
+		}
+		if posted {
+			result.Accrued++
+		}
+	}
+
+	return nil
+}
+
+// monthlyAccrual returns the days the user accrues for period: those of the leave policy that
+// applies to them, or else those of the accrual policy.
+func (us *Accrual) monthlyAccrual(ctx context.Context, policy *entity.AccrualPolicy, userId int, period time.Time) (float64, error) {
+	leavePolicy, err := us.leavePolicyRepo.FindApplicable(ctx, userId, policy.Type, period)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return policy.DaysPerMonth, nil
+		}
+		return 0, err
+	}
+
+	days, ok := leavePolicy.MonthlyAccrual()
+	if !ok {
+		return policy.DaysPerMonth, nil
+	}
+
+	return math.Round(days*100) / 100, nil
+}
+
+func (us *Accrual) carryOver(ctx context.Context, policy *entity.AccrualPolicy, userId int, period time.Time) (bool, error) {
+	if policy.CarryOverCap <= 0 {
+		return false, nil
+	}
+
+	previous, err := us.leaveBalanceRepo.GetBalance(ctx, userId, period.Year()-1, policy.Type, 0)
+	if err != nil {
+		return false, err
+	}
+
+	days := math.Min(previous.Available(), policy.CarryOverCap)
+	if days <= 0 {
+		return false, nil
+	}
+
+	return us.leaveBalanceRepo.AddPeriodEntry(ctx, &entity.LeaveBalanceEntry{
+		UserId:        userId,
+		LeaveYear:     period.Year(),
+		Type:          policy.Type,
+		EntryType:     entity.BalanceCarryOver,
+		Days:          days,
+		Note:          fmt.Sprintf("Carried over from %d", period.Year()-1),
+		AccrualPeriod: &period,
+	})
+}
+
+// expireCarryOver removes the part of the carry-over into leaveYear that was not used by
+// leave starting before the expiry date. Carried-over days are considered consumed first.
+func (us *Accrual) expireCarryOver(ctx context.Context, policy *entity.AccrualPolicy, userId, leaveYear int, expiresAt time.Time) (bool, error) {
+	carried, err := us.leaveBalanceRepo.SumEntries(ctx, userId, leaveYear, policy.Type, entity.BalanceCarryOver)
+	if err != nil || carried <= 0 {
+		return false, err
+	}
+
+	used, err := us.leaveBalanceRepo.GetUsedBefore(ctx, userId, leaveYear, policy.Type, expiresAt)
+	if err != nil {
+		return false, err
+	}
+
+	days := carried - used
+	if days <= 0 {
+		return false, nil
+	}
+
+	return us.leaveBalanceRepo.AddPeriodEntry(ctx, &entity.LeaveBalanceEntry{
+		UserId:        userId,
+		LeaveYear:     leaveYear,
+		Type:          policy.Type,
+		EntryType:     entity.BalanceExpiry,
+		Days:          days,
+		Note:          fmt.Sprintf("Unused carry-over expired on %s", expiresAt.Format(time.DateOnly)),
+		AccrualPeriod: &expiresAt,
+	})
+}
+
+func (us *Accrual) GetPolicies(ctx context.Context) (*dto.GetAccrualPoliciesResponse, *models.ErrorResponse) {
+	response := &dto.GetAccrualPoliciesResponse{}
+
+	policies, err := us.accrualPolicyRepo.GetAllPolicies(ctx)
+	if err != nil {
+		return nil, &models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal Server Error",
+		}
+	}
+
+	response.MapAccrualPoliciesResponse(policies)
+
+	return response, nil
+}
+
+func (us *Accrual) UpsertPolicy(ctx context.Context, leaveType string, req *dto.UpsertAccrualPolicyRequest) (*dto.AccrualPolicyResponse, *models.ErrorResponse) {
+	response := &dto.AccrualPolicyResponse{}
+
+	existingLeaveType, errType := findLeaveType(ctx, us.leaveTypeRepo, entity.LeaveRequestType(leaveType))
+	if errType != nil {
+		return nil, errType
+	}
+
+	if !existingLeaveType.DeductsBalance {
+		return nil, &models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Leave type does not accrue balance",
+		}
+	}
+
+	policy := req.ToAccrualPolicy(existingLeaveType.Code)
+
+	err := us.accrualPolicyRepo.Upsert(ctx, policy)
+	if err != nil {
+		return nil, &models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to save accrual policy",
+		}
+	}
+
+	response.MapAccrualPolicyResponse(policy)
+
+	return response, nil
+}
@@ -0,0 +1,135 @@
+package usecase_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	entity "github.com/devonLoen/leave-request-service/internal/app/rest_api/entity"
+	"github.com/devonLoen/leave-request-service/internal/app/rest_api/repository"
+	"github.com/devonLoen/leave-request-service/internal/app/rest_api/usecase"
+)
+
+type MockAccrualPolicyRepo struct {
+	mock.Mock
+}
+
+func (m *MockAccrualPolicyRepo) GetAllPolicies(ctx context.Context) ([]*entity.AccrualPolicy, error) {
+	args := m.Called()
+	return args.Get(0).([]*entity.AccrualPolicy), args.Error(1)
+}
+
+func (m *MockAccrualPolicyRepo) GetActivePolicies(ctx context.Context) ([]*entity.AccrualPolicy, error) {
+	args := m.Called()
+	return args.Get(0).([]*entity.AccrualPolicy), args.Error(1)
+}
+
+func (m *MockAccrualPolicyRepo) Upsert(ctx context.Context, policy *entity.AccrualPolicy) error {
+	return m.Called(policy).Error(0)
+}
+
+func entryOfType(entryType entity.BalanceEntryType, days float64) interface{} {
+	return mock.MatchedBy(func(e *entity.LeaveBalanceEntry) bool {
+		return e.EntryType == entryType && e.Days == days
+	})
+}
+
+func TestAccrualRun(t *testing.T) {
+	seniorDays := 18.0
+	annual := &entity.AccrualPolicy{Type: entity.Annual, DaysPerMonth: 1, CarryOverCap: 5, CarryOverExpiryMonths: 3, Active: true}
+
+	tests := []struct {
+		name        string
+		from, to    time.Time
+		leavePolicy *entity.LeavePolicy
+		setupMock   func(balanceRepo *MockLeaveBalanceRepo)
+		want        entity.AccrualRunResult
+	}{
+		{
+			name: "January carries over up to the cap before accruing",
+			from: time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC),
+			to:   time.Date(2026, time.January, 20, 0, 0, 0, 0, time.UTC),
+			setupMock: func(balanceRepo *MockLeaveBalanceRepo) {
+				balanceRepo.On("GetBalance", 7, 2025, entity.Annual, 0).
+					Return(&entity.LeaveBalance{Entitled: 12, Used: 4}, nil).Once()
+				balanceRepo.On("AddPeriodEntry", entryOfType(entity.BalanceCarryOver, 5)).Return(true, nil).Once()
+				balanceRepo.On("AddPeriodEntry", entryOfType(entity.BalanceAccrual, 1)).Return(true, nil).Once()
+			},
+			want: entity.AccrualRunResult{Accrued: 1, CarriedOver: 1},
+		},
+		{
+			name: "Unused carry-over expires after the configured months",
+			from: time.Date(2026, time.April, 1, 0, 0, 0, 0, time.UTC),
+			to:   time.Date(2026, time.April, 1, 0, 0, 0, 0, time.UTC),
+			setupMock: func(balanceRepo *MockLeaveBalanceRepo) {
+				balanceRepo.On("SumEntries", 7, 2026, entity.Annual, entity.BalanceCarryOver).Return(5.0, nil).Once()
+				balanceRepo.On("GetUsedBefore", 7, 2026, entity.Annual, time.Date(2026, time.April, 1, 0, 0, 0, 0, time.UTC)).
+					Return(2.0, nil).Once()
+				balanceRepo.On("AddPeriodEntry", entryOfType(entity.BalanceExpiry, 3)).Return(true, nil).Once()
+				balanceRepo.On("AddPeriodEntry", entryOfType(entity.BalanceAccrual, 1)).Return(false, nil).Once()
+			},
+			want: entity.AccrualRunResult{Expired: 1},
+		},
+		{
+			name:        "Leave policy replaces the monthly accrual",
+			from:        time.Date(2026, time.March, 1, 0, 0, 0, 0, time.UTC),
+			to:          time.Date(2026, time.March, 1, 0, 0, 0, 0, time.UTC),
+			leavePolicy: &entity.LeavePolicy{Name: "Seniors", LeaveType: entity.Annual, Entitled: true, DaysPerYear: &seniorDays},
+			setupMock: func(balanceRepo *MockLeaveBalanceRepo) {
+				balanceRepo.On("AddPeriodEntry", entryOfType(entity.BalanceAccrual, 1.5)).Return(true, nil).Once()
+			},
+			want: entity.AccrualRunResult{Accrued: 1},
+		},
+		{
+			name:        "No accrual while the leave policy does not entitle",
+			from:        time.Date(2026, time.March, 1, 0, 0, 0, 0, time.UTC),
+			to:          time.Date(2026, time.March, 1, 0, 0, 0, 0, time.UTC),
+			leavePolicy: &entity.LeavePolicy{Name: "Interns probation", LeaveType: entity.Annual},
+			setupMock:   func(balanceRepo *MockLeaveBalanceRepo) {},
+			want:        entity.AccrualRunResult{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, sqlMock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("Error creating mock database: %v", err)
+			}
+			defer db.Close()
+
+			sqlMock.ExpectQuery(`WHERE u.hire_date <= \$1`).WithArgs(tt.from).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "full_name", "email", "role", "manager_id", "department", "hire_date"}).
+					AddRow(7, "Jane Doe", "jane@example.com", "employee", nil, "", hiredOn))
+
+			policyRepo := new(MockAccrualPolicyRepo)
+			policyRepo.On("GetActivePolicies").Return([]*entity.AccrualPolicy{annual}, nil).Once()
+			balanceRepo := new(MockLeaveBalanceRepo)
+			tt.setupMock(balanceRepo)
+
+			leavePolicyRepo := new(MockLeavePolicyRepo)
+			if tt.leavePolicy != nil {
+				leavePolicyRepo.On("FindApplicable", 7, entity.Annual, tt.from).Return(tt.leavePolicy, nil).Once()
+			} else {
+				leavePolicyRepo.On("FindApplicable", 7, entity.Annual, tt.from).Return(nil, sql.ErrNoRows).Once()
+			}
+
+			uc := usecase.NewAccrualUsecase(policyRepo, new(MockLeaveTypeRepo), leavePolicyRepo, balanceRepo, repository.NewUserRepository(db))
+
+			result, err := uc.Run(context.Background(), tt.from, tt.to)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want.Accrued, result.Accrued)
+			assert.Equal(t, tt.want.CarriedOver, result.CarriedOver)
+			assert.Equal(t, tt.want.Expired, result.Expired)
+			balanceRepo.AssertExpectations(t)
+			leavePolicyRepo.AssertExpectations(t)
+			assert.NoError(t, sqlMock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package usecase
+
+import (
+	"context"
+	"net/http"
+
+	models "github.com/devonLoen/leave-request-service/internal/app/rest_api/model"
+	"github.com/devonLoen/leave-request-service/internal/app/rest_api/model/dto"
+	"github.com/devonLoen/leave-request-service/internal/app/rest_api/repository"
+)
+
+type ApprovalChainUsecase interface {
+	GetApprovalChains(ctx context.Context) (*dto.GetApprovalChainsResponse, *models.ErrorResponse)
+	UpsertApprovalChain(ctx context.Context, req *dto.UpsertApprovalChainRequest) (*dto.ApprovalChainResponse, *models.ErrorResponse)
+	DeleteApprovalChain(ctx context.Context, approvalChainID int) *models.ErrorResponse
+}
+
+type ApprovalChain struct {
+	approvalRepo  repository.ApprovalRepository
+	leaveTypeRepo repository.LeaveTypeRepository
+}
+
+func NewApprovalChainUsecase(approvalRepo repository.ApprovalRepository, leaveTypeRepo repository.LeaveTypeRepository) *ApprovalChain {
+	return &ApprovalChain{approvalRepo: approvalRepo, leaveTypeRepo: leaveTypeRepo}
+}
+
+func (us *ApprovalChain) GetApprovalChains(ctx context.Context) (*dto.GetApprovalChainsResponse, *models.ErrorResponse) {
+	response := &dto.GetApprovalChainsResponse{}
+
+	chains, err := us.approvalRepo.GetAllChains(ctx)
+	if err != nil {
+		return nil, &models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal Server Error",
+		}
+	}
+
+	response.MapApprovalChainsResponse(chains)
+
+	return response, nil
+}
+
+// UpsertApprovalChain replaces the steps of the chain with the same leave type and threshold,
+// or adds a new one. Requests already waiting keep the steps they were submitted with.
+func (us *ApprovalChain) UpsertApprovalChain(ctx context.Context, req *dto.UpsertApprovalChainRequest) (*dto.ApprovalChainResponse, *models.ErrorResponse) {
+	response := &dto.ApprovalChainResponse{}
+
+	chain := req.ToApprovalChain()
+
+	if _, errType := findLeaveType(ctx, us.leaveTypeRepo, chain.Type); errType != nil {
+		return nil, errType
+	}
+
+	err := us.approvalRepo.UpsertChain(ctx, chain)
+	if err != nil {
+		return nil, &models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to save approval chain",
+		}
+	}
+
+	response.MapApprovalChainResponse(chain)
+
+	return response, nil
+}
+
+func (us *ApprovalChain) DeleteApprovalChain(ctx context.Context, approvalChainID int) *models.ErrorResponse {
+	deleted, err := us.approvalRepo.DeleteChain(ctx, approvalChainID)
+	if err != nil {
+		return &models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to delete approval chain",
+		}
+	}
+
+	if !deleted {
+		return &models.ErrorResponse{
+			Code:    http.StatusNotFound,
+			Message: "Approval Chain Not Found",
+		}
+	}
+
+	return nil
+}
@@ -1,6 +1,7 @@
 package usecase
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"net/http"
@@ -19,10 +20,10 @@ func NewAuthUsecase(userRepo *repository.User) *Auth {
 	return &Auth{userRepo: userRepo}
 }
 
-func (a *Auth) Login(req *dto.LoginRequest) (*dto.LoginResponse, *model.ErrorResponse) {
+func (a *Auth) Login(ctx context.Context, req *dto.LoginRequest) (*dto.LoginResponse, *model.ErrorResponse) {
 	loginResponse := &dto.LoginResponse{}
 
-	user, err := a.userRepo.FindByEmailWithPassword(req.Email)
+	user, err := a.userRepo.FindByEmailWithPassword(ctx, req.Email)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, &model.ErrorResponse{
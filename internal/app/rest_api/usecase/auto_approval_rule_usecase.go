@@ -0,0 +1,132 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+
+	entity "github.com/devonLoen/leave-request-service/internal/app/rest_api/entity"
+	models "github.com/devonLoen/leave-request-service/internal/app/rest_api/model"
+	"github.com/devonLoen/leave-request-service/internal/app/rest_api/model/dto"
+	"github.com/devonLoen/leave-request-service/internal/app/rest_api/repository"
+)
+
+type AutoApprovalRuleUsecase interface {
+	GetAutoApprovalRules(ctx context.Context) (*dto.GetAutoApprovalRulesResponse, *models.ErrorResponse)
+	CreateAutoApprovalRule(ctx context.Context, req *dto.AutoApprovalRuleRequest) (*dto.AutoApprovalRuleResponse, *models.ErrorResponse)
+	UpdateAutoApprovalRule(ctx context.Context, autoApprovalRuleID int, req *dto.AutoApprovalRuleRequest) (*dto.AutoApprovalRuleResponse, *models.ErrorResponse)
+	DeleteAutoApprovalRule(ctx context.Context, autoApprovalRuleID int) *models.ErrorResponse
+}
+
+type AutoApprovalRule struct {
+	autoApprovalRuleRepo repository.AutoApprovalRuleRepository
+	leaveTypeRepo        repository.LeaveTypeRepository
+}
+
+func NewAutoApprovalRuleUsecase(autoApprovalRuleRepo repository.AutoApprovalRuleRepository, leaveTypeRepo repository.LeaveTypeRepository) *AutoApprovalRule {
+	return &AutoApprovalRule{autoApprovalRuleRepo: autoApprovalRuleRepo, leaveTypeRepo: leaveTypeRepo}
+}
+
+func (us *AutoApprovalRule) GetAutoApprovalRules(ctx context.Context) (*dto.GetAutoApprovalRulesResponse, *models.ErrorResponse) {
+	response := &dto.GetAutoApprovalRulesResponse{}
+
+	rules, err := us.autoApprovalRuleRepo.GetAll(ctx)
+	if err != nil {
+		return nil, &models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal Server Error",
+		}
+	}
+
+	response.MapAutoApprovalRulesResponse(rules)
+
+	return response, nil
+}
+
+func (us *AutoApprovalRule) CreateAutoApprovalRule(ctx context.Context, req *dto.AutoApprovalRuleRequest) (*dto.AutoApprovalRuleResponse, *models.ErrorResponse) {
+	response := &dto.AutoApprovalRuleResponse{}
+	rule := req.ToAutoApprovalRule()
+
+	if errResponse := us.checkLeaveType(ctx, rule); errResponse != nil {
+		return nil, errResponse
+	}
+
+	err := us.autoApprovalRuleRepo.Create(ctx, rule)
+	if err != nil {
+		return nil, &models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to create auto-approval rule",
+		}
+	}
+
+	response.MapAutoApprovalRuleResponse(rule)
+
+	return response, nil
+}
+
+func (us *AutoApprovalRule) UpdateAutoApprovalRule(ctx context.Context, autoApprovalRuleID int, req *dto.AutoApprovalRuleRequest) (*dto.AutoApprovalRuleResponse, *models.ErrorResponse) {
+	response := &dto.AutoApprovalRuleResponse{}
+
+	_, err := us.autoApprovalRuleRepo.FindById(ctx, autoApprovalRuleID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, &models.ErrorResponse{
+				Code:    http.StatusNotFound,
+				Message: "Auto-Approval Rule Not Found",
+			}
+		}
+		return nil, &models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal Server Error",
+		}
+	}
+
+	rule := req.ToAutoApprovalRule()
+	rule.ID = autoApprovalRuleID
+
+	if errResponse := us.checkLeaveType(ctx, rule); errResponse != nil {
+		return nil, errResponse
+	}
+
+	err = us.autoApprovalRuleRepo.Update(ctx, rule)
+	if err != nil {
+		return nil, &models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to update auto-approval rule",
+		}
+	}
+
+	response.MapAutoApprovalRuleResponse(rule)
+
+	return response, nil
+}
+
+func (us *AutoApprovalRule) DeleteAutoApprovalRule(ctx context.Context, autoApprovalRuleID int) *models.ErrorResponse {
+	deleted, err := us.autoApprovalRuleRepo.Delete(ctx, autoApprovalRuleID)
+	if err != nil {
+		return &models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to delete auto-approval rule",
+		}
+	}
+
+	if !deleted {
+		return &models.ErrorResponse{
+			Code:    http.StatusNotFound,
+			Message: "Auto-Approval Rule Not Found",
+		}
+	}
+
+	return nil
+}
+
+// checkLeaveType makes sure a rule limited to one leave type refers to an existing one.
+func (us *AutoApprovalRule) checkLeaveType(ctx context.Context, rule *entity.AutoApprovalRule) *models.ErrorResponse {
+	if rule.LeaveType == nil {
+		return nil
+	}
+
+	_, errResponse := findLeaveType(ctx, us.leaveTypeRepo, *rule.LeaveType)
+	return errResponse
+}
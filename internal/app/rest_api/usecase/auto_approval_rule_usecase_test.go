@@ -0,0 +1,247 @@
+package usecase_test
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	entity "github.com/devonLoen/leave-request-service/internal/app/rest_api/entity"
+	"github.com/devonLoen/leave-request-service/internal/app/rest_api/model/dto"
+	"github.com/devonLoen/leave-request-service/internal/app/rest_api/repository"
+	"github.com/devonLoen/leave-request-service/internal/app/rest_api/usecase"
+)
+
+type MockAutoApprovalRuleRepo struct {
+	mock.Mock
+}
+
+func (m *MockAutoApprovalRuleRepo) GetAll(ctx context.Context) ([]*entity.AutoApprovalRule, error) {
+	args := m.Called()
+	if args.Get(0) != nil {
+		return args.Get(0).([]*entity.AutoApprovalRule), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockAutoApprovalRuleRepo) GetActive(ctx context.Context) ([]*entity.AutoApprovalRule, error) {
+	args := m.Called()
+	if args.Get(0) != nil {
+		return args.Get(0).([]*entity.AutoApprovalRule), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockAutoApprovalRuleRepo) FindById(ctx context.Context, id int) (*entity.AutoApprovalRule, error) {
+	args := m.Called(id)
+	if args.Get(0) != nil {
+		return args.Get(0).(*entity.AutoApprovalRule), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockAutoApprovalRuleRepo) Create(ctx context.Context, rule *entity.AutoApprovalRule) error {
+	return m.Called(rule).Error(0)
+}
+
+func (m *MockAutoApprovalRuleRepo) Update(ctx context.Context, rule *entity.AutoApprovalRule) error {
+	return m.Called(rule).Error(0)
+}
+
+func (m *MockAutoApprovalRuleRepo) Delete(ctx context.Context, id int) (bool, error) {
+	args := m.Called(id)
+	return args.Bool(0), args.Error(1)
+}
+
+func TestCreateAutoApprovalRule(t *testing.T) {
+	active := true
+	maxDays := 1.0
+
+	tests := []struct {
+		name      string
+		leaveType string
+		setupMock func(leaveTypeRepo *MockLeaveTypeRepo)
+		wantCode  int
+	}{
+		{
+			name:      "Unknown leave type",
+			leaveType: "sabbatical",
+			setupMock: func(leaveTypeRepo *MockLeaveTypeRepo) {
+				leaveTypeRepo.On("FindByCode", entity.LeaveRequestType("sabbatical")).Return(nil, sql.ErrNoRows).Once()
+			},
+			wantCode: http.StatusBadRequest,
+		},
+		{name: "Any leave type", leaveType: "", setupMock: func(leaveTypeRepo *MockLeaveTypeRepo) {}, wantCode: 0},
+		{
+			name:      "Success",
+			leaveType: "sick",
+			setupMock: func(leaveTypeRepo *MockLeaveTypeRepo) {
+				leaveTypeRepo.On("FindByCode", entity.Sick).Return(sickLeave, nil).Once()
+			},
+			wantCode: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			leaveTypeRepo := new(MockLeaveTypeRepo)
+			tt.setupMock(leaveTypeRepo)
+			ruleRepo := new(MockAutoApprovalRuleRepo)
+			if tt.wantCode == 0 {
+				ruleRepo.On("Create", mock.AnythingOfType("*entity.AutoApprovalRule")).Return(nil).Run(func(args mock.Arguments) {
+					args.Get(0).(*entity.AutoApprovalRule).ID = 1
+				}).Once()
+			}
+			uc := usecase.NewAutoApprovalRuleUsecase(ruleRepo, leaveTypeRepo)
+
+			res, err := uc.CreateAutoApprovalRule(context.Background(), &dto.AutoApprovalRuleRequest{
+				Name:           "Single sick day",
+				LeaveType:      tt.leaveType,
+				MaxWorkingDays: &maxDays,
+				Active:         &active,
+			})
+
+			if tt.wantCode != 0 {
+				assert.Nil(t, res)
+				assert.Equal(t, tt.wantCode, err.Code)
+				ruleRepo.AssertNotCalled(t, "Create", mock.Anything)
+			} else {
+				assert.Nil(t, err)
+				assert.Equal(t, 1, res.ID)
+				assert.Equal(t, 1.0, *res.MaxWorkingDays)
+			}
+			ruleRepo.AssertExpectations(t)
+			leaveTypeRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestSubmitAutoApproval(t *testing.T) {
+	monday := nextMonday()
+	sick := entity.Sick
+	oneDay := 1.0
+	twoWeeks := 14
+	nobodyAway := 0
+
+	tests := []struct {
+		name         string
+		rule         *entity.AutoApprovalRule
+		workingDays  float64
+		teamAbsent   int
+		wantApproved bool
+	}{
+		{
+			name:         "Single sick day is approved",
+			rule:         &entity.AutoApprovalRule{ID: 3, Name: "Single sick day", LeaveType: &sick, MaxWorkingDays: &oneDay, Active: true},
+			workingDays:  1,
+			wantApproved: true,
+		},
+		{
+			name:        "Longer sick leave waits for an approver",
+			rule:        &entity.AutoApprovalRule{ID: 3, Name: "Single sick day", LeaveType: &sick, MaxWorkingDays: &oneDay, Active: true},
+			workingDays: 2,
+		},
+		{
+			name:        "Short notice waits for an approver",
+			rule:        &entity.AutoApprovalRule{ID: 3, Name: "Planned ahead", MinNoticeDays: &twoWeeks, Active: true},
+			workingDays: 1,
+		},
+		{
+			name:         "Team coverage allows it",
+			rule:         &entity.AutoApprovalRule{ID: 3, Name: "Team covered", MaxTeamAbsent: &nobodyAway, Active: true},
+			workingDays:  1,
+			wantApproved: true,
+		},
+		{
+			name:        "Teammate away waits for an approver",
+			rule:        &entity.AutoApprovalRule{ID: 3, Name: "Team covered", MaxTeamAbsent: &nobodyAway, Active: true},
+			workingDays: 1,
+			teamAbsent:  1,
+		},
+		{
+			name:        "Inactive rule is ignored",
+			rule:        &entity.AutoApprovalRule{ID: 3, Name: "Single sick day", LeaveType: &sick, Active: false},
+			workingDays: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, sqlMock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("Error creating mock database: %v", err)
+			}
+			defer db.Close()
+			userRepo := repository.NewUserRepository(db)
+
+			mockRepo := new(MockLeaveRequestRepo)
+			mockBalanceRepo := new(MockLeaveBalanceRepo)
+			mockApprovalRepo := new(MockApprovalRepo)
+			unitOfWork := &MockUnitOfWork{repos: &repository.Repositories{User: userRepo, LeaveRequest: mockRepo, LeaveBalance: mockBalanceRepo, Approval: mockApprovalRepo}}
+			// The submission reads the leave type to check it and to reserve the days, and an
+			// automatic approval reads it twice more to release the reservation and debit the days.
+			lookups := 2
+			if tt.wantApproved {
+				lookups = 4
+			}
+			leaveTypeRepo := new(MockLeaveTypeRepo)
+			leaveTypeRepo.On("FindByCode", entity.Sick).Return(sickLeave, nil).Times(lookups)
+			leavePolicyRepo := new(MockLeavePolicyRepo)
+			leavePolicyRepo.On("FindApplicable", 1, entity.Sick, mock.Anything).Return(nil, sql.ErrNoRows).Once()
+			ruleRepo := new(MockAutoApprovalRuleRepo)
+			if tt.rule.Active {
+				ruleRepo.On("GetActive").Return([]*entity.AutoApprovalRule{tt.rule}, nil).Once()
+			} else {
+				ruleRepo.On("GetActive").Return([]*entity.AutoApprovalRule{}, nil).Once()
+			}
+			uc := usecase.NewLeaveRequestUsecase(mockRepo, mockBalanceRepo, new(MockHolidayRepo), leaveTypeRepo, leavePolicyRepo, ruleRepo, userRepo, mockApprovalRepo, new(MockDelegationRepo), new(MockAttachmentRepo), new(MockStorage), unitOfWork)
+
+			if tt.rule.Active {
+				sqlMock.ExpectQuery(`SELECT u.id`).WithArgs(1).
+					WillReturnRows(userRows().AddRow(1, "Jane Doe", "jane@example.com", "employee", nil, "Engineering", hiredOn))
+			}
+			mockRepo.On("FindById", 7).
+				Return(&entity.LeaveRequest{ID: 7, UserId: 1, StartDate: monday, EndDate: monday, Type: entity.Sick, Status: entity.Draft, WorkingDays: tt.workingDays}, nil).Once()
+			mockBalanceRepo.On("LockUser", 1).Return(nil).Once()
+			mockBalanceRepo.On("GetBalance", 1, monday.Year(), entity.Sick, 7).
+				Return(&entity.LeaveBalance{Entitled: 12}, nil).Once()
+			if tt.rule.MaxTeamAbsent != nil {
+				mockRepo.On("CountTeamAbsences", 1, mock.Anything, mock.Anything).Return(tt.teamAbsent, nil).Once()
+			}
+			mockRepo.On("Submit", 7, 1).Return(true, nil).Once()
+			mockApprovalRepo.On("FindChainFor", entity.Sick, tt.workingDays).Return(nil, sql.ErrNoRows).Once()
+			mockApprovalRepo.On("CreateSteps", 7, entity.DefaultApprovalSteps).Return(nil).Once()
+			if tt.wantApproved {
+				comment := `Approved automatically by rule "` + tt.rule.Name + `"`
+				mockRepo.On("OverlapApprovedLeaveExists", 1, mock.Anything, mock.Anything).Return(false, nil).Once()
+				mockApprovalRepo.On("ApprovePendingSteps", 7, 3, comment).Return(nil).Once()
+				mockRepo.On("AutoApprove", 7, 3, comment).Return(true, nil).Once()
+				mockBalanceRepo.On("ReleaseReservation", 7).Return(nil).Once()
+				mockBalanceRepo.On("AddEntry", mock.Anything).Return(nil).Twice()
+			} else {
+				mockBalanceRepo.On("AddEntry", mock.Anything).Return(nil).Once()
+			}
+
+			status, errResp := uc.Submit(context.Background(), 7, 1, 0)
+
+			assert.Nil(t, errResp)
+			if tt.wantApproved {
+				assert.Equal(t, entity.Approved, status)
+			} else {
+				assert.Equal(t, entity.WaitingApproval, status)
+				mockRepo.AssertNotCalled(t, "AutoApprove", mock.Anything, mock.Anything, mock.Anything)
+			}
+			mockRepo.AssertExpectations(t)
+			mockBalanceRepo.AssertExpectations(t)
+			mockApprovalRepo.AssertExpectations(t)
+			leaveTypeRepo.AssertExpectations(t)
+			leavePolicyRepo.AssertExpectations(t)
+			ruleRepo.AssertExpectations(t)
+			assert.NoError(t, sqlMock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,368 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	entity "github.com/devonLoen/leave-request-service/internal/app/rest_api/entity"
+	models "github.com/devonLoen/leave-request-service/internal/app/rest_api/model"
+	"github.com/devonLoen/leave-request-service/internal/app/rest_api/model/dto"
+	"github.com/devonLoen/leave-request-service/internal/app/rest_api/pkg/util"
+	"github.com/devonLoen/leave-request-service/internal/app/rest_api/repository"
+)
+
+// MaxCalendarDays bounds the range of a single calendar query.
+const MaxCalendarDays = 92
+
+// Calendar feeds cover approved leave from feedYearsBack years ago to feedYearsAhead years
+// from now.
+const (
+	feedYearsBack  = 1
+	feedYearsAhead = 2
+)
+
+type CalendarUsecase interface {
+	GetCalendar(ctx context.Context, userID int, filter entity.LeaveRequestFilter, includePending bool) (*dto.GetCalendarResponse, *models.ErrorResponse)
+	CreateFeedToken(ctx context.Context, userID int) (*dto.CalendarFeedTokenResponse, *models.ErrorResponse)
+	RevokeFeedToken(ctx context.Context, userID int) *models.ErrorResponse
+	GetUserFeed(ctx context.Context, token string) ([]byte, *models.ErrorResponse)
+	GetTeamFeed(ctx context.Context, token string, includeIndirect bool) ([]byte, *models.ErrorResponse)
+	GetLeaveRequestICal(ctx context.Context, leaveRequestID, userID int) ([]byte, *models.ErrorResponse)
+}
+
+type Calendar struct {
+	leaveRequestRepo repository.LeaveRequestRepository
+	holidayRepo      repository.HolidayRepository
+	userRepo         *repository.User
+	feedTokenRepo    repository.CalendarFeedTokenRepository
+}
+
+func NewCalendarUsecase(leaveRequestRepo repository.LeaveRequestRepository, holidayRepo repository.HolidayRepository, userRepo *repository.User, feedTokenRepo repository.CalendarFeedTokenRepository) *Calendar {
+	return &Calendar{leaveRequestRepo: leaveRequestRepo, holidayRepo: holidayRepo, userRepo: userRepo, feedTokenRepo: feedTokenRepo}
+}
+
+// GetCalendar lists, day by day from filter.From to filter.To, who in the team of
+// filter.ManagerId is absent. Approved leave, and leave awaiting cancellation, is always
+// shown; includePending adds requests still waiting for approval. Weekends and public
+// holidays are listed without absences, as leave never takes them off.
+func (us *Calendar) GetCalendar(ctx context.Context, userID int, filter entity.LeaveRequestFilter, includePending bool) (*dto.GetCalendarResponse, *models.ErrorResponse) {
+	if filter.From.After(filter.To) {
+		return nil, &models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "from cannot be after to",
+		}
+	}
+	if filter.To.Sub(filter.From) >= MaxCalendarDays*24*time.Hour {
+		return nil, &models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("The calendar covers at most %d days", MaxCalendarDays),
+		}
+	}
+
+	if errAuth := us.authorizeTeam(ctx, userID, filter.ManagerId, "You can only see the calendar of teams in your reporting line."); errAuth != nil {
+		return nil, errAuth
+	}
+
+	filter.Statuses = []entity.LeaveRequestStatus{entity.Approved, entity.CancellationRequested}
+	if includePending {
+		filter.Statuses = append(filter.Statuses, entity.WaitingApproval)
+	}
+
+	absences, err := us.leaveRequestRepo.GetAbsences(ctx, filter)
+	if err != nil {
+		return nil, &models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal Server Error",
+		}
+	}
+
+	holidays, err := us.holidayRepo.GetHolidaysBetween(ctx, filter.From, filter.To)
+	if err != nil {
+		return nil, &models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal Server Error",
+		}
+	}
+	holidayDates := make([]time.Time, 0, len(holidays))
+	for _, holiday := range holidays {
+		holidayDates = append(holidayDates, holiday.Date)
+	}
+	holidaySet := util.NewHolidaySet(holidayDates...)
+
+	response := &dto.GetCalendarResponse{
+		From: filter.From.Format(time.DateOnly),
+		To:   filter.To.Format(time.DateOnly),
+		Days: []*dto.CalendarDayResponse{},
+	}
+	for day := filter.From; !day.After(filter.To); day = day.AddDate(0, 0, 1) {
+		dayResponse := &dto.CalendarDayResponse{}
+		dayResponse.MapCalendarDayResponse(day, util.IsWorkingDay(day, holidaySet))
+
+		if dayResponse.WorkingDay {
+			absent := map[int]bool{}
+			for _, absence := range absences {
+				portion, ok := absence.PortionOn(day)
+				if !ok {
+					continue
+				}
+				absenceResponse := &dto.CalendarAbsenceResponse{}
+				absenceResponse.MapCalendarAbsenceResponse(absence, portion)
+				dayResponse.Absences = append(dayResponse.Absences, absenceResponse)
+				absent[absence.UserId] = true
+			}
+			dayResponse.AbsentCount = len(absent)
+		}
+
+		response.Days = append(response.Days, dayResponse)
+	}
+
+	return response, nil
+}
+
+// authorizeTeam lets a user see the leave of targetID when it is the user, someone below them
+// in the reporting line or, for admins, anyone. Otherwise it fails with 403 and message.
+func (us *Calendar) authorizeTeam(ctx context.Context, userID, targetID int, message string) *models.ErrorResponse {
+	if targetID == userID {
+		return nil
+	}
+
+	user, err := us.userRepo.FindById(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return &models.ErrorResponse{
+				Code:    http.StatusForbidden,
+				Message: "User not found",
+			}
+		}
+		return &models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal Server Error",
+		}
+	}
+	if user.Role.IsAdmin() {
+		return nil
+	}
+
+	isManager, err := us.userRepo.IsManagerOf(ctx, userID, targetID)
+	if err != nil {
+		return &models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal Server Error",
+		}
+	}
+	if !isManager {
+		return &models.ErrorResponse{
+			Code:    http.StatusForbidden,
+			Message: message,
+		}
+	}
+
+	return nil
+}
+
+// CreateFeedToken gives the user a new token for their calendar feeds, revoking the old one.
+func (us *Calendar) CreateFeedToken(ctx context.Context, userID int) (*dto.CalendarFeedTokenResponse, *models.ErrorResponse) {
+	token, err := util.GenerateFeedToken()
+	if err != nil {
+		return nil, &models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to create calendar feed token",
+		}
+	}
+
+	err = us.feedTokenRepo.Replace(ctx, &entity.CalendarFeedToken{UserId: userID, TokenHash: util.HashFeedToken(token)})
+	if err != nil {
+		return nil, &models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to create calendar feed token",
+		}
+	}
+
+	response := &dto.CalendarFeedTokenResponse{}
+	response.MapCalendarFeedTokenResponse(token)
+
+	return response, nil
+}
+
+func (us *Calendar) RevokeFeedToken(ctx context.Context, userID int) *models.ErrorResponse {
+	deleted, err := us.feedTokenRepo.Delete(ctx, userID)
+	if err != nil {
+		return &models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to revoke calendar feed token",
+		}
+	}
+
+	if !deleted {
+		return &models.ErrorResponse{
+			Code:    http.StatusNotFound,
+			Message: "Calendar feed token not found",
+		}
+	}
+
+	return nil
+}
+
+// GetUserFeed renders the approved leave of the token's owner as an iCalendar stream.
+func (us *Calendar) GetUserFeed(ctx context.Context, token string) ([]byte, *models.ErrorResponse) {
+	user, errResp := us.findFeedOwner(ctx, token)
+	if errResp != nil {
+		return nil, errResp
+	}
+
+	return us.renderFeed(ctx, "Leave of "+user.FullName, entity.LeaveRequestFilter{UserId: strconv.Itoa(user.ID)})
+}
+
+// GetTeamFeed renders the approved leave of the team managed by the token's owner, including
+// the teams delegated to them, and with includeIndirect everyone below them.
+func (us *Calendar) GetTeamFeed(ctx context.Context, token string, includeIndirect bool) ([]byte, *models.ErrorResponse) {
+	user, errResp := us.findFeedOwner(ctx, token)
+	if errResp != nil {
+		return nil, errResp
+	}
+
+	return us.renderFeed(ctx, "Team leave of "+user.FullName, entity.LeaveRequestFilter{ManagerId: user.ID, IncludeIndirect: includeIndirect})
+}
+
+// GetLeaveRequestICal renders a single request, whatever its status, for its owner, their
+// managers and admins.
+func (us *Calendar) GetLeaveRequestICal(ctx context.Context, leaveRequestID, userID int) ([]byte, *models.ErrorResponse) {
+	leaveRequest, err := us.leaveRequestRepo.FindById(ctx, leaveRequestID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, &models.ErrorResponse{
+				Code:    http.StatusNotFound,
+				Message: "Leave Request Not Found",
+			}
+		}
+		return nil, &models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal Server Error",
+		}
+	}
+
+	if leaveRequest.UserId != userID {
+		errAuth := us.authorizeTeam(ctx, userID, leaveRequest.UserId, "The specified leave request belongs to another user.")
+		if errAuth != nil {
+			return nil, errAuth
+		}
+	}
+
+	owner, err := us.userRepo.FindById(ctx, leaveRequest.UserId)
+	if err != nil {
+		return nil, &models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal Server Error",
+		}
+	}
+
+	return writeICalendar(util.ICalendar{
+		Name:   fmt.Sprintf("Leave request %d", leaveRequest.ID),
+		Stamp:  time.Now(),
+		Events: []util.ICalEvent{leaveICalEvent(leaveRequest, owner.FullName)},
+	})
+}
+
+// findFeedOwner resolves a feed token to its user. Unknown and revoked tokens are not found.
+func (us *Calendar) findFeedOwner(ctx context.Context, token string) (*entity.User, *models.ErrorResponse) {
+	notFound := &models.ErrorResponse{
+		Code:    http.StatusNotFound,
+		Message: "Calendar feed not found",
+	}
+
+	feedToken, err := us.feedTokenRepo.FindByTokenHash(ctx, util.HashFeedToken(token))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, notFound
+		}
+		return nil, &models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal Server Error",
+		}
+	}
+
+	user, err := us.userRepo.FindById(ctx, feedToken.UserId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, notFound
+		}
+		return nil, &models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal Server Error",
+		}
+	}
+
+	return user, nil
+}
+
+// renderFeed writes the approved leave matching filter, and leave awaiting cancellation,
+// which is still booked.
+func (us *Calendar) renderFeed(ctx context.Context, name string, filter entity.LeaveRequestFilter) ([]byte, *models.ErrorResponse) {
+	today := util.DateOnly(time.Now())
+	filter.Statuses = []entity.LeaveRequestStatus{entity.Approved, entity.CancellationRequested}
+	filter.From = today.AddDate(-feedYearsBack, 0, 0)
+	filter.To = today.AddDate(feedYearsAhead, 0, 0)
+
+	absences, err := us.leaveRequestRepo.GetAbsences(ctx, filter)
+	if err != nil {
+		return nil, &models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal Server Error",
+		}
+	}
+
+	cal := util.ICalendar{Name: name, Stamp: time.Now()}
+	for _, absence := range absences {
+		cal.Events = append(cal.Events, leaveICalEvent(&absence.LeaveRequest, absence.FullName))
+	}
+
+	return writeICalendar(cal)
+}
+
+func writeICalendar(cal util.ICalendar) ([]byte, *models.ErrorResponse) {
+	var buf bytes.Buffer
+	if err := util.WriteICalendar(&buf, cal); err != nil {
+		return nil, &models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal Server Error",
+		}
+	}
+
+	return buf.Bytes(), nil
+}
+
+// leaveICalEvent turns a request into an event. Requests of whole days are all-day events;
+// half days and hourly leave keep their wall-clock period. The version is the sequence, so
+// clients pick up edits.
+func leaveICalEvent(leaveRequest *entity.LeaveRequest, fullName string) util.ICalEvent {
+	event := util.ICalEvent{
+		UID:      fmt.Sprintf("leave-request-%d@leave-request-service", leaveRequest.ID),
+		Summary:  fmt.Sprintf("%s (%s leave)", fullName, leaveRequest.Type),
+		Start:    leaveRequest.PeriodStart,
+		End:      leaveRequest.PeriodEnd,
+		Sequence: leaveRequest.Version,
+	}
+
+	if leaveRequest.DurationUnit == entity.DurationDay && leaveRequest.StartSession == entity.SessionAM && leaveRequest.EndSession == entity.SessionPM {
+		event.AllDay = true
+		event.Start = util.DateOnly(leaveRequest.StartDate)
+		event.End = util.DateOnly(leaveRequest.EndDate).AddDate(0, 0, 1)
+	}
+
+	switch leaveRequest.Status {
+	case entity.Approved, entity.CancellationRequested:
+		event.Status = "CONFIRMED"
+	case entity.Draft, entity.WaitingApproval:
+		event.Status = "TENTATIVE"
+	default:
+		event.Status = "CANCELLED"
+	}
+
+	return event
+}
@@ -0,0 +1,202 @@
+package usecase_test
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	entity "github.com/devonLoen/leave-request-service/internal/app/rest_api/entity"
+	"github.com/devonLoen/leave-request-service/internal/app/rest_api/pkg/util"
+	"github.com/devonLoen/leave-request-service/internal/app/rest_api/repository"
+	"github.com/devonLoen/leave-request-service/internal/app/rest_api/usecase"
+)
+
+type MockCalendarFeedTokenRepo struct {
+	mock.Mock
+}
+
+func (m *MockCalendarFeedTokenRepo) Replace(ctx context.Context, token *entity.CalendarFeedToken) error {
+	args := m.Called(token)
+	return args.Error(0)
+}
+
+func (m *MockCalendarFeedTokenRepo) Delete(ctx context.Context, userId int) (bool, error) {
+	args := m.Called(userId)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockCalendarFeedTokenRepo) FindByTokenHash(ctx context.Context, tokenHash string) (*entity.CalendarFeedToken, error) {
+	args := m.Called(tokenHash)
+	if args.Get(0) != nil {
+		return args.Get(0).(*entity.CalendarFeedToken), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func TestGetCalendar(t *testing.T) {
+	monday := time.Date(2026, time.March, 2, 0, 0, 0, 0, time.UTC)
+	sunday := monday.AddDate(0, 0, 6)
+
+	fullDays := &entity.LeaveRequest{ID: 1, UserId: 2, Type: entity.Annual, Status: entity.Approved, StartDate: monday, EndDate: monday.AddDate(0, 0, 1)}
+	fullDays.SetDayPeriod(entity.SessionAM, entity.SessionPM)
+	afternoon := &entity.LeaveRequest{ID: 2, UserId: 3, Type: entity.Sick, Status: entity.WaitingApproval, StartDate: monday, EndDate: monday}
+	afternoon.SetDayPeriod(entity.SessionPM, entity.SessionPM)
+	hourly := &entity.LeaveRequest{ID: 3, UserId: 2, Type: entity.Annual, Status: entity.Approved, StartDate: monday.AddDate(0, 0, 2)}
+	hourly.SetHourPeriod(9*time.Hour, 11*time.Hour)
+
+	mockRepo := new(MockLeaveRequestRepo)
+	mockHolidayRepo := new(MockHolidayRepo)
+	uc := usecase.NewCalendarUsecase(mockRepo, mockHolidayRepo, newUserRepo(t), new(MockCalendarFeedTokenRepo))
+
+	mockRepo.On("GetAbsences", mock.MatchedBy(func(filter entity.LeaveRequestFilter) bool {
+		return filter.ManagerId == 1 && len(filter.Statuses) == 3 && filter.From.Equal(monday) && filter.To.Equal(sunday)
+	})).Return([]*entity.Absence{
+		{LeaveRequest: *fullDays, FullName: "Alice"},
+		{LeaveRequest: *afternoon, FullName: "Bob"},
+		{LeaveRequest: *hourly, FullName: "Alice"},
+	}, nil).Once()
+	mockHolidayRepo.On("GetHolidaysBetween", monday, sunday).
+		Return([]*entity.Holiday{{Date: monday.AddDate(0, 0, 3), Name: "Founders Day"}}, nil).Once()
+
+	calendar, err := uc.GetCalendar(context.Background(), 1, entity.LeaveRequestFilter{ManagerId: 1, From: monday, To: sunday}, true)
+
+	assert.Nil(t, err)
+	assert.Len(t, calendar.Days, 7)
+
+	assert.Equal(t, "2026-03-02", calendar.Days[0].Date)
+	assert.Equal(t, 2, calendar.Days[0].AbsentCount)
+	assert.Equal(t, "full", calendar.Days[0].Absences[0].Portion)
+	assert.Equal(t, "pm", calendar.Days[0].Absences[1].Portion)
+	assert.Equal(t, "waiting_approval", calendar.Days[0].Absences[1].Status)
+
+	assert.Equal(t, 1, calendar.Days[1].AbsentCount)
+
+	assert.Equal(t, 1, calendar.Days[2].AbsentCount)
+	assert.Equal(t, "hours", calendar.Days[2].Absences[0].Portion)
+	assert.Equal(t, "09:00", calendar.Days[2].Absences[0].StartTime)
+	assert.Equal(t, "11:00", calendar.Days[2].Absences[0].EndTime)
+
+	assert.False(t, calendar.Days[3].WorkingDay)
+	assert.True(t, calendar.Days[4].WorkingDay)
+	assert.Equal(t, 0, calendar.Days[4].AbsentCount)
+	assert.False(t, calendar.Days[5].WorkingDay)
+
+	mockRepo.AssertExpectations(t)
+	mockHolidayRepo.AssertExpectations(t)
+}
+
+func TestGetCalendarRejectsRequest(t *testing.T) {
+	from := time.Date(2026, time.March, 2, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		filter       entity.LeaveRequestFilter
+		setupMock    func(sqlMock sqlmock.Sqlmock)
+		expectedCode int
+	}{
+		{
+			name:         "from after to",
+			filter:       entity.LeaveRequestFilter{ManagerId: 1, From: from, To: from.AddDate(0, 0, -1)},
+			setupMock:    func(sqlMock sqlmock.Sqlmock) {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "range too long",
+			filter:       entity.LeaveRequestFilter{ManagerId: 1, From: from, To: from.AddDate(0, 0, usecase.MaxCalendarDays)},
+			setupMock:    func(sqlMock sqlmock.Sqlmock) {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:   "team outside the reporting line",
+			filter: entity.LeaveRequestFilter{ManagerId: 5, From: from, To: from},
+			setupMock: func(sqlMock sqlmock.Sqlmock) {
+				sqlMock.ExpectQuery(`SELECT u.id`).WithArgs(1).
+					WillReturnRows(userRows().AddRow(1, "Employee", "employee@example.com", "employee", nil, "", hiredOn))
+				sqlMock.ExpectQuery(`WITH RECURSIVE chain`).WithArgs(1, 5).
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+			},
+			expectedCode: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, sqlMock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("Error creating mock database: %v", err)
+			}
+			defer db.Close()
+			tt.setupMock(sqlMock)
+
+			mockRepo := new(MockLeaveRequestRepo)
+			uc := usecase.NewCalendarUsecase(mockRepo, new(MockHolidayRepo), repository.NewUserRepository(db), new(MockCalendarFeedTokenRepo))
+
+			calendar, errResp := uc.GetCalendar(context.Background(), 1, tt.filter, false)
+
+			assert.Nil(t, calendar)
+			assert.Equal(t, tt.expectedCode, errResp.Code)
+			mockRepo.AssertNotCalled(t, "GetAbsences", mock.Anything)
+			assert.NoError(t, sqlMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestGetUserFeed(t *testing.T) {
+	monday := time.Date(2026, time.March, 2, 0, 0, 0, 0, time.UTC)
+	fullDays := &entity.LeaveRequest{ID: 1, UserId: 2, Type: entity.Annual, Status: entity.Approved, StartDate: monday, EndDate: monday.AddDate(0, 0, 1), Version: 3}
+	fullDays.SetDayPeriod(entity.SessionAM, entity.SessionPM)
+	morning := &entity.LeaveRequest{ID: 2, UserId: 2, Type: entity.Sick, Status: entity.CancellationRequested, StartDate: monday.AddDate(0, 0, 3), EndDate: monday.AddDate(0, 0, 3)}
+	morning.SetDayPeriod(entity.SessionAM, entity.SessionAM)
+
+	db, sqlMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating mock database: %v", err)
+	}
+	defer db.Close()
+
+	mockRepo := new(MockLeaveRequestRepo)
+	mockFeedTokenRepo := new(MockCalendarFeedTokenRepo)
+	uc := usecase.NewCalendarUsecase(mockRepo, new(MockHolidayRepo), repository.NewUserRepository(db), mockFeedTokenRepo)
+
+	mockFeedTokenRepo.On("FindByTokenHash", util.HashFeedToken("secret")).
+		Return(&entity.CalendarFeedToken{UserId: 2}, nil).Once()
+	mockFeedTokenRepo.On("FindByTokenHash", util.HashFeedToken("revoked")).
+		Return(nil, sql.ErrNoRows).Once()
+	sqlMock.ExpectQuery(`SELECT u.id`).WithArgs(2).
+		WillReturnRows(userRows().AddRow(2, "Alice", "alice@example.com", "employee", nil, "", hiredOn))
+	mockRepo.On("GetAbsences", mock.MatchedBy(func(filter entity.LeaveRequestFilter) bool {
+		return filter.UserId == "2" && len(filter.Statuses) == 2 && filter.From.Before(filter.To)
+	})).Return([]*entity.Absence{
+		{LeaveRequest: *fullDays, FullName: "Alice"},
+		{LeaveRequest: *morning, FullName: "Alice"},
+	}, nil).Once()
+
+	feed, errResp := uc.GetUserFeed(context.Background(), "secret")
+
+	assert.Nil(t, errResp)
+	events, err := util.ParseICalEvents(bytes.NewReader(feed))
+	assert.NoError(t, err)
+	assert.Len(t, events, 2)
+	assert.Equal(t, "leave-request-1@leave-request-service", events[0].UID)
+	assert.Equal(t, "Alice (annual leave)", events[0].Summary)
+	assert.True(t, events[0].AllDay)
+	assert.Equal(t, monday.AddDate(0, 0, 2), events[0].End)
+	assert.False(t, events[1].AllDay)
+	assert.Equal(t, monday.AddDate(0, 0, 3).Add(12*time.Hour), events[1].End)
+	assert.Contains(t, string(feed), "SEQUENCE:3\r\n")
+
+	feed, errResp = uc.GetUserFeed(context.Background(), "revoked")
+
+	assert.Nil(t, feed)
+	assert.Equal(t, http.StatusNotFound, errResp.Code)
+	mockRepo.AssertExpectations(t)
+	mockFeedTokenRepo.AssertExpectations(t)
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+}
@@ -0,0 +1,132 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	models "github.com/devonLoen/leave-request-service/internal/app/rest_api/model"
+	"github.com/devonLoen/leave-request-service/internal/app/rest_api/model/dto"
+	"github.com/devonLoen/leave-request-service/internal/app/rest_api/repository"
+)
+
+type DelegationUsecase interface {
+	GetMyDelegations(ctx context.Context, userID int) (*dto.GetDelegationsResponse, *models.ErrorResponse)
+	CreateDelegation(ctx context.Context, userID int, req *dto.CreateDelegationRequest) (*dto.DelegationResponse, *models.ErrorResponse)
+	DeleteDelegation(ctx context.Context, delegationID, userID int) *models.ErrorResponse
+}
+
+type Delegation struct {
+	delegationRepo repository.DelegationRepository
+	userRepo       *repository.User
+}
+
+func NewDelegationUsecase(delegationRepo repository.DelegationRepository, userRepo *repository.User) *Delegation {
+	return &Delegation{delegationRepo: delegationRepo, userRepo: userRepo}
+}
+
+func (us *Delegation) GetMyDelegations(ctx context.Context, userID int) (*dto.GetDelegationsResponse, *models.ErrorResponse) {
+	response := &dto.GetDelegationsResponse{}
+
+	delegations, err := us.delegationRepo.GetByDelegator(ctx, userID)
+	if err != nil {
+		return nil, &models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal Server Error",
+		}
+	}
+
+	response.MapDelegationsResponse(delegations)
+
+	return response, nil
+}
+
+// CreateDelegation hands the user's approval authority to another user for a date range.
+// A user has at most one delegate on any given day.
+func (us *Delegation) CreateDelegation(ctx context.Context, userID int, createDelegationRequest *dto.CreateDelegationRequest) (*dto.DelegationResponse, *models.ErrorResponse) {
+	response := &dto.DelegationResponse{}
+	delegation := createDelegationRequest.ToDelegation(userID)
+
+	if delegation.DelegateId == userID {
+		return nil, &models.ErrorResponse{
+			Code:    http.StatusUnprocessableEntity,
+			Message: "You cannot delegate to yourself",
+		}
+	}
+
+	if delegation.StartDate.After(delegation.EndDate) {
+		return nil, &models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "startDate cannot be after endDate",
+		}
+	}
+
+	now := time.Now()
+	if delegation.EndDate.Before(time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)) {
+		return nil, &models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Delegation cannot be in the past",
+		}
+	}
+
+	_, err := us.userRepo.FindById(ctx, delegation.DelegateId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, &models.ErrorResponse{
+				Code:    http.StatusUnprocessableEntity,
+				Message: "Delegate Not Found",
+			}
+		}
+		return nil, &models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal Server Error",
+		}
+	}
+
+	overlapping, err := us.delegationRepo.OverlapExists(ctx, userID, delegation.StartDate, delegation.EndDate)
+	if err != nil {
+		return nil, &models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal Server Error",
+		}
+	}
+	if overlapping {
+		return nil, &models.ErrorResponse{
+			Code:    http.StatusConflict,
+			Message: "You already have a delegate during these dates",
+		}
+	}
+
+	err = us.delegationRepo.Create(ctx, delegation)
+	if err != nil {
+		return nil, &models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to create delegation",
+		}
+	}
+
+	response.MapDelegationResponse(delegation)
+
+	return response, nil
+}
+
+func (us *Delegation) DeleteDelegation(ctx context.Context, delegationID, userID int) *models.ErrorResponse {
+	deleted, err := us.delegationRepo.Delete(ctx, delegationID, userID)
+	if err != nil {
+		return &models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to delete delegation",
+		}
+	}
+
+	if !deleted {
+		return &models.ErrorResponse{
+			Code:    http.StatusNotFound,
+			Message: "Delegation Not Found",
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,346 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	entity "github.com/devonLoen/leave-request-service/internal/app/rest_api/entity"
+	models "github.com/devonLoen/leave-request-service/internal/app/rest_api/model"
+	"github.com/devonLoen/leave-request-service/internal/app/rest_api/model/dto"
+	"github.com/devonLoen/leave-request-service/internal/app/rest_api/pkg/util"
+	"github.com/devonLoen/leave-request-service/internal/app/rest_api/repository"
+	"github.com/lib/pq"
+)
+
+type HolidayUsecase interface {
+	GetHolidays(ctx context.Context, year int) (*dto.GetHolidaysResponse, *models.ErrorResponse)
+	CreateHoliday(ctx context.Context, req *dto.HolidayRequest) (*dto.HolidayResponse, *models.ErrorResponse)
+	UpdateHoliday(ctx context.Context, holidayID int, req *dto.HolidayRequest) (*dto.HolidayResponse, *models.ErrorResponse)
+	DeleteHoliday(ctx context.Context, holidayID int) *models.ErrorResponse
+	ImportHolidays(ctx context.Context, ics io.Reader) (*dto.ImportHolidaysResponse, *models.ErrorResponse)
+}
+
+type Holiday struct {
+	holidayRepo      repository.HolidayRepository
+	leaveRequestRepo repository.LeaveRequestRepository
+	leaveBalanceRepo repository.LeaveBalanceRepository
+	leaveTypeRepo    repository.LeaveTypeRepository
+	unitOfWork       repository.UnitOfWork
+}
+
+func NewHolidayUsecase(holidayRepo repository.HolidayRepository, leaveRequestRepo repository.LeaveRequestRepository, leaveBalanceRepo repository.LeaveBalanceRepository, leaveTypeRepo repository.LeaveTypeRepository, unitOfWork repository.UnitOfWork) *Holiday {
+	return &Holiday{holidayRepo: holidayRepo, leaveRequestRepo: leaveRequestRepo, leaveBalanceRepo: leaveBalanceRepo, leaveTypeRepo: leaveTypeRepo, unitOfWork: unitOfWork}
+}
+
+// inTx runs fn with a copy of the usecase whose repositories share one transaction, so a
+// holiday change and the recount of the leave requests it affects are stored together.
+func (us *Holiday) inTx(ctx context.Context, fn func(tx *Holiday) *models.ErrorResponse) *models.ErrorResponse {
+	var errResp *models.ErrorResponse
+	err := us.unitOfWork.Do(ctx, func(repos *repository.Repositories) error {
+		tx := *us
+		tx.holidayRepo = repos.Holiday
+		tx.leaveRequestRepo = repos.LeaveRequest
+		tx.leaveBalanceRepo = repos.LeaveBalance
+
+		errResp = fn(&tx)
+		if errResp != nil {
+			return errRolledBack
+		}
+		return nil
+	})
+	if errResp != nil {
+		return errResp
+	}
+	if err != nil {
+		return &models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal Server Error",
+		}
+	}
+
+	return nil
+}
+
+func (us *Holiday) GetHolidays(ctx context.Context, year int) (*dto.GetHolidaysResponse, *models.ErrorResponse) {
+	response := &dto.GetHolidaysResponse{}
+
+	holidays, err := us.holidayRepo.GetHolidaysBetween(ctx,
+		time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(year, time.December, 31, 0, 0, 0, 0, time.UTC),
+	)
+	if err != nil {
+		return nil, &models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal Server Error",
+		}
+	}
+
+	response.MapHolidaysResponse(holidays)
+
+	return response, nil
+}
+
+func (us *Holiday) CreateHoliday(ctx context.Context, req *dto.HolidayRequest) (*dto.HolidayResponse, *models.ErrorResponse) {
+	response := &dto.HolidayResponse{}
+	holiday := req.ToHoliday()
+
+	errCreate := us.inTx(ctx, func(tx *Holiday) *models.ErrorResponse {
+		if err := tx.holidayRepo.Create(ctx, holiday); err != nil {
+			return holidayWriteError(err, "Failed to create holiday")
+		}
+
+		return tx.recountLeaveRequests(ctx, holiday.Date, holiday.Date)
+	})
+	if errCreate != nil {
+		return nil, errCreate
+	}
+
+	response.MapHolidayResponse(holiday)
+
+	return response, nil
+}
+
+func (us *Holiday) UpdateHoliday(ctx context.Context, holidayID int, req *dto.HolidayRequest) (*dto.HolidayResponse, *models.ErrorResponse) {
+	response := &dto.HolidayResponse{}
+
+	existing, err := us.holidayRepo.FindById(ctx, holidayID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, &models.ErrorResponse{
+				Code:    http.StatusNotFound,
+				Message: "Holiday Not Found",
+			}
+		}
+		return nil, &models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal Server Error",
+		}
+	}
+
+	holiday := req.ToHoliday()
+	holiday.ID = holidayID
+
+	errUpdate := us.inTx(ctx, func(tx *Holiday) *models.ErrorResponse {
+		if err := tx.holidayRepo.Update(ctx, holiday); err != nil {
+			return holidayWriteError(err, "Failed to update holiday")
+		}
+
+		if errRecount := tx.recountLeaveRequests(ctx, existing.Date, existing.Date); errRecount != nil {
+			return errRecount
+		}
+		return tx.recountLeaveRequests(ctx, holiday.Date, holiday.Date)
+	})
+	if errUpdate != nil {
+		return nil, errUpdate
+	}
+
+	response.MapHolidayResponse(holiday)
+
+	return response, nil
+}
+
+func (us *Holiday) DeleteHoliday(ctx context.Context, holidayID int) *models.ErrorResponse {
+	errNotFound := &models.ErrorResponse{
+		Code:    http.StatusNotFound,
+		Message: "Holiday Not Found",
+	}
+
+	existing, err := us.holidayRepo.FindById(ctx, holidayID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return errNotFound
+		}
+		return &models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal Server Error",
+		}
+	}
+
+	return us.inTx(ctx, func(tx *Holiday) *models.ErrorResponse {
+		deleted, err := tx.holidayRepo.Delete(ctx, holidayID)
+		if err != nil {
+			return &models.ErrorResponse{
+				Code:    http.StatusInternalServerError,
+				Message: "Failed to delete holiday",
+			}
+		}
+		if !deleted {
+			return errNotFound
+		}
+
+		return tx.recountLeaveRequests(ctx, existing.Date, existing.Date)
+	})
+}
+
+// ImportHolidays upserts one holiday per day covered by each event of an iCalendar file.
+// Days that already exist keep their date and take the name from the file. The file is
+// imported in one transaction with the recount of the leave requests it affects.
+func (us *Holiday) ImportHolidays(ctx context.Context, ics io.Reader) (*dto.ImportHolidaysResponse, *models.ErrorResponse) {
+	events, err := util.ParseICalEvents(ics)
+	if err != nil {
+		return nil, &models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid iCalendar file: " + err.Error(),
+		}
+	}
+
+	response := &dto.ImportHolidaysResponse{}
+	errImport := us.inTx(ctx, func(tx *Holiday) *models.ErrorResponse {
+		var first, last time.Time
+		for _, event := range events {
+			name := strings.TrimSpace(event.Summary)
+			if name == "" {
+				response.Skipped++
+				continue
+			}
+
+			end := util.DateOnly(event.End)
+			if !event.AllDay {
+				end = util.DateOnly(event.End.Add(-time.Nanosecond)).AddDate(0, 0, 1)
+			}
+
+			for day := util.DateOnly(event.Start); day.Before(end); day = day.AddDate(0, 0, 1) {
+				err := tx.holidayRepo.Upsert(ctx, &entity.Holiday{Date: day, Name: name})
+				if err != nil {
+					return &models.ErrorResponse{
+						Code:    http.StatusInternalServerError,
+						Message: "Failed to import holidays",
+					}
+				}
+				response.Imported++
+
+				if first.IsZero() || day.Before(first) {
+					first = day
+				}
+				if day.After(last) {
+					last = day
+				}
+			}
+		}
+
+		if first.IsZero() {
+			return nil
+		}
+		return tx.recountLeaveRequests(ctx, first, last)
+	})
+	if errImport != nil {
+		return nil, errImport
+	}
+
+	response.Message = "Holidays imported successfully."
+
+	return response, nil
+}
+
+// recountLeaveRequests brings the open requests covering a day from from to to in line with
+// the holidays now stored for those days: their working days are counted again and, when
+// their leave type keeps a balance, the reservation of a request waiting for approval or the
+// debit of approved leave is booked again for the new count. Drafts hold nothing yet.
+func (us *Holiday) recountLeaveRequests(ctx context.Context, from, to time.Time) *models.ErrorResponse {
+	leaveRequests, err := us.leaveRequestRepo.GetOpenBetween(ctx, from, to)
+	if err != nil {
+		return &models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal Server Error",
+		}
+	}
+
+	for _, leaveRequest := range leaveRequests {
+		holidays, err := us.holidayRepo.GetHolidaysBetween(ctx, util.DateOnly(leaveRequest.StartDate), util.DateOnly(leaveRequest.EndDate))
+		if err != nil {
+			return &models.ErrorResponse{
+				Code:    http.StatusInternalServerError,
+				Message: "Internal Server Error",
+			}
+		}
+
+		holidayDates := make([]time.Time, 0, len(holidays))
+		for _, holiday := range holidays {
+			holidayDates = append(holidayDates, holiday.Date)
+		}
+
+		workingDays := chargeableDays(leaveRequest, util.NewHolidaySet(holidayDates...))
+		if workingDays == leaveRequest.WorkingDays {
+			continue
+		}
+
+		recounted, err := us.leaveRequestRepo.RecountWorkingDays(ctx, leaveRequest, workingDays)
+		if err != nil {
+			return &models.ErrorResponse{
+				Code:    http.StatusInternalServerError,
+				Message: "Failed to recount leave requests",
+			}
+		}
+		if !recounted {
+			return errLeaveRequestChanged()
+		}
+		leaveRequest.WorkingDays = workingDays
+
+		if errBalance := us.rebookBalance(ctx, leaveRequest); errBalance != nil {
+			return errBalance
+		}
+	}
+
+	return nil
+}
+
+// rebookBalance replaces what a recounted request holds in the balance ledger.
+func (us *Holiday) rebookBalance(ctx context.Context, leaveRequest *entity.LeaveRequest) *models.ErrorResponse {
+	var entryType entity.BalanceEntryType
+	var release func(ctx context.Context, leaveRequestId int) error
+	switch leaveRequest.Status {
+	case entity.WaitingApproval:
+		entryType, release = entity.BalanceReservation, us.leaveBalanceRepo.ReleaseReservation
+	case entity.Approved, entity.CancellationRequested:
+		entryType, release = entity.BalanceDebit, us.leaveBalanceRepo.ReleaseDebit
+	default:
+		return nil
+	}
+
+	leaveType, errType := findLeaveType(ctx, us.leaveTypeRepo, leaveRequest.Type)
+	if errType != nil {
+		return errType
+	}
+	if !leaveType.DeductsBalance {
+		return nil
+	}
+
+	if err := release(ctx, leaveRequest.ID); err != nil {
+		return &models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to update leave balance",
+		}
+	}
+	// A request whose only working days became holidays no longer costs anything.
+	if leaveRequest.WorkingDays == 0 {
+		return nil
+	}
+	if err := us.leaveBalanceRepo.AddEntry(ctx, newBalanceEntry(leaveRequest, entryType)); err != nil {
+		return &models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to update leave balance",
+		}
+	}
+
+	return nil
+}
+
+func holidayWriteError(err error, message string) *models.ErrorResponse {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return &models.ErrorResponse{
+			Code:    http.StatusConflict,
+			Message: "A holiday already exists on this date",
+		}
+	}
+
+	return &models.ErrorResponse{
+		Code:    http.StatusInternalServerError,
+		Message: message,
+	}
+}
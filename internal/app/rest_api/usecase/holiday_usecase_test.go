@@ -0,0 +1,98 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	entity "github.com/devonLoen/leave-request-service/internal/app/rest_api/entity"
+	"github.com/devonLoen/leave-request-service/internal/app/rest_api/model/dto"
+	"github.com/devonLoen/leave-request-service/internal/app/rest_api/repository"
+	"github.com/devonLoen/leave-request-service/internal/app/rest_api/usecase"
+)
+
+func TestCreateHolidayRecountsLeaveRequests(t *testing.T) {
+	monday := nextMonday()
+	tuesday := monday.AddDate(0, 0, 1)
+	holiday := &entity.Holiday{ID: 1, Date: monday, Name: "Founders' Day"}
+
+	waiting := &entity.LeaveRequest{ID: 7, UserId: 1, StartDate: monday, EndDate: tuesday, Type: entity.Annual, Status: entity.WaitingApproval, WorkingDays: 2}
+	waiting.SetDayPeriod(entity.SessionAM, entity.SessionPM)
+	approvedUnpaid := &entity.LeaveRequest{ID: 8, UserId: 2, StartDate: monday, EndDate: tuesday, Type: entity.Unpaid, Status: entity.Approved, WorkingDays: 2}
+	approvedUnpaid.SetDayPeriod(entity.SessionAM, entity.SessionPM)
+	draft := &entity.LeaveRequest{ID: 9, UserId: 3, StartDate: monday, EndDate: monday, Type: entity.Annual, Status: entity.Draft, WorkingDays: 1}
+	draft.SetDayPeriod(entity.SessionAM, entity.SessionPM)
+
+	mockHolidayRepo := new(MockHolidayRepo)
+	mockRepo := new(MockLeaveRequestRepo)
+	mockBalanceRepo := new(MockLeaveBalanceRepo)
+	unitOfWork := &MockUnitOfWork{repos: &repository.Repositories{Holiday: mockHolidayRepo, LeaveRequest: mockRepo, LeaveBalance: mockBalanceRepo}}
+	leaveTypeRepo := new(MockLeaveTypeRepo)
+	uc := usecase.NewHolidayUsecase(mockHolidayRepo, mockRepo, mockBalanceRepo, leaveTypeRepo, unitOfWork)
+
+	mockHolidayRepo.On("Create", mock.Anything).Return(nil).Once()
+	mockHolidayRepo.On("GetHolidaysBetween", mock.Anything, mock.Anything).Return([]*entity.Holiday{holiday}, nil)
+	mondayUTC := time.Date(monday.Year(), monday.Month(), monday.Day(), 0, 0, 0, 0, time.UTC)
+	mockRepo.On("GetOpenBetween", mondayUTC, mondayUTC).
+		Return([]*entity.LeaveRequest{waiting, approvedUnpaid, draft}, nil).Once()
+	mockRepo.On("RecountWorkingDays", 7, 1.0).Return(true, nil).Once()
+	mockRepo.On("RecountWorkingDays", 8, 1.0).Return(true, nil).Once()
+	mockRepo.On("RecountWorkingDays", 9, 0.0).Return(true, nil).Once()
+	leaveTypeRepo.On("FindByCode", entity.Annual).Return(annualLeave, nil).Once()
+	leaveTypeRepo.On("FindByCode", entity.Unpaid).Return(unpaidLeave, nil).Once()
+	mockBalanceRepo.On("ReleaseReservation", 7).Return(nil).Once()
+	mockBalanceRepo.On("AddEntry", mock.MatchedBy(func(e *entity.LeaveBalanceEntry) bool {
+		return *e.LeaveRequestId == 7 && e.EntryType == entity.BalanceReservation && e.Days == 1
+	})).Return(nil).Once()
+
+	res, errResp := uc.CreateHoliday(context.Background(), &dto.HolidayRequest{Date: monday.Format(time.DateOnly), Name: holiday.Name})
+
+	assert.Nil(t, errResp)
+	assert.NotNil(t, res)
+	assert.False(t, unitOfWork.rolledBack)
+	mockRepo.AssertExpectations(t)
+	mockBalanceRepo.AssertExpectations(t)
+	mockHolidayRepo.AssertExpectations(t)
+	leaveTypeRepo.AssertExpectations(t)
+}
+
+func TestCreateHolidayOnTheOnlyDayOfLeaveReleasesBalance(t *testing.T) {
+	monday := nextMonday()
+	holiday := &entity.Holiday{ID: 1, Date: monday, Name: "Founders' Day"}
+
+	waiting := &entity.LeaveRequest{ID: 7, UserId: 1, StartDate: monday, EndDate: monday, Type: entity.Annual, Status: entity.WaitingApproval, WorkingDays: 1}
+	waiting.SetDayPeriod(entity.SessionAM, entity.SessionPM)
+	approved := &entity.LeaveRequest{ID: 8, UserId: 2, StartDate: monday, EndDate: monday, Type: entity.Annual, Status: entity.Approved, WorkingDays: 1}
+	approved.SetDayPeriod(entity.SessionAM, entity.SessionPM)
+
+	mockHolidayRepo := new(MockHolidayRepo)
+	mockRepo := new(MockLeaveRequestRepo)
+	mockBalanceRepo := new(MockLeaveBalanceRepo)
+	unitOfWork := &MockUnitOfWork{repos: &repository.Repositories{Holiday: mockHolidayRepo, LeaveRequest: mockRepo, LeaveBalance: mockBalanceRepo}}
+	leaveTypeRepo := new(MockLeaveTypeRepo)
+	uc := usecase.NewHolidayUsecase(mockHolidayRepo, mockRepo, mockBalanceRepo, leaveTypeRepo, unitOfWork)
+
+	mockHolidayRepo.On("Create", mock.Anything).Return(nil).Once()
+	mockHolidayRepo.On("GetHolidaysBetween", mock.Anything, mock.Anything).Return([]*entity.Holiday{holiday}, nil)
+	mockRepo.On("GetOpenBetween", mock.Anything, mock.Anything).
+		Return([]*entity.LeaveRequest{waiting, approved}, nil).Once()
+	mockRepo.On("RecountWorkingDays", 7, 0.0).Return(true, nil).Once()
+	mockRepo.On("RecountWorkingDays", 8, 0.0).Return(true, nil).Once()
+	leaveTypeRepo.On("FindByCode", entity.Annual).Return(annualLeave, nil).Twice()
+	mockBalanceRepo.On("ReleaseReservation", 7).Return(nil).Once()
+	mockBalanceRepo.On("ReleaseDebit", 8).Return(nil).Once()
+
+	res, errResp := uc.CreateHoliday(context.Background(), &dto.HolidayRequest{Date: monday.Format(time.DateOnly), Name: holiday.Name})
+
+	assert.Nil(t, errResp)
+	assert.NotNil(t, res)
+	assert.False(t, unitOfWork.rolledBack)
+	mockBalanceRepo.AssertNotCalled(t, "AddEntry", mock.Anything)
+	mockRepo.AssertExpectations(t)
+	mockBalanceRepo.AssertExpectations(t)
+	mockHolidayRepo.AssertExpectations(t)
+	leaveTypeRepo.AssertExpectations(t)
+}
@@ -0,0 +1,85 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+
+	models "github.com/devonLoen/leave-request-service/internal/app/rest_api/model"
+	"github.com/devonLoen/leave-request-service/internal/app/rest_api/model/dto"
+	"github.com/devonLoen/leave-request-service/internal/app/rest_api/repository"
+)
+
+type LeaveBalanceUsecase interface {
+	GetBalances(ctx context.Context, userID, leaveYear int) (*dto.GetBalancesResponse, *models.ErrorResponse)
+	CreateBalanceEntry(ctx context.Context, userID int, req *dto.CreateBalanceEntryRequest) (*dto.CreateBalanceEntryResponse, *models.ErrorResponse)
+}
+
+type LeaveBalance struct {
+	leaveBalanceRepo repository.LeaveBalanceRepository
+	leaveTypeRepo    repository.LeaveTypeRepository
+	userRepo         *repository.User
+}
+
+func NewLeaveBalanceUsecase(leaveBalanceRepo repository.LeaveBalanceRepository, leaveTypeRepo repository.LeaveTypeRepository, userRepo *repository.User) *LeaveBalance {
+	return &LeaveBalance{leaveBalanceRepo: leaveBalanceRepo, leaveTypeRepo: leaveTypeRepo, userRepo: userRepo}
+}
+
+func (us *LeaveBalance) GetBalances(ctx context.Context, userID, leaveYear int) (*dto.GetBalancesResponse, *models.ErrorResponse) {
+	response := &dto.GetBalancesResponse{}
+
+	balances, err := us.leaveBalanceRepo.GetBalances(ctx, userID, leaveYear)
+	if err != nil {
+		return nil, &models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal Server Error",
+		}
+	}
+
+	response.MapBalancesResponse(balances)
+
+	return response, nil
+}
+
+func (us *LeaveBalance) CreateBalanceEntry(ctx context.Context, userID int, req *dto.CreateBalanceEntryRequest) (*dto.CreateBalanceEntryResponse, *models.ErrorResponse) {
+	response := &dto.CreateBalanceEntryResponse{}
+
+	_, err := us.userRepo.FindById(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, &models.ErrorResponse{
+				Code:    http.StatusNotFound,
+				Message: "User Not Found",
+			}
+		}
+		return nil, &models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal Server Error",
+		}
+	}
+
+	entry := req.ToLeaveBalanceEntry(userID)
+
+	leaveType, errType := findLeaveType(ctx, us.leaveTypeRepo, entry.Type)
+	if errType != nil {
+		return nil, errType
+	}
+
+	if !leaveType.DeductsBalance {
+		return nil, &models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Leave type does not keep a balance",
+		}
+	}
+
+	err = us.leaveBalanceRepo.AddEntry(ctx, entry)
+	if err != nil {
+		return nil, &models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to create balance entry",
+		}
+	}
+
+	return response.FromLeaveBalanceEntry(entry), nil
+}
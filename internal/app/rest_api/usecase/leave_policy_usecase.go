@@ -0,0 +1,140 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+
+	entity "github.com/devonLoen/leave-request-service/internal/app/rest_api/entity"
+	models "github.com/devonLoen/leave-request-service/internal/app/rest_api/model"
+	"github.com/devonLoen/leave-request-service/internal/app/rest_api/model/dto"
+	"github.com/devonLoen/leave-request-service/internal/app/rest_api/repository"
+)
+
+type LeavePolicyUsecase interface {
+	GetLeavePolicies(ctx context.Context) (*dto.GetLeavePoliciesResponse, *models.ErrorResponse)
+	CreateLeavePolicy(ctx context.Context, req *dto.LeavePolicyRequest) (*dto.LeavePolicyResponse, *models.ErrorResponse)
+	UpdateLeavePolicy(ctx context.Context, leavePolicyID int, req *dto.LeavePolicyRequest) (*dto.LeavePolicyResponse, *models.ErrorResponse)
+	DeleteLeavePolicy(ctx context.Context, leavePolicyID int) *models.ErrorResponse
+}
+
+type LeavePolicy struct {
+	leavePolicyRepo repository.LeavePolicyRepository
+	leaveTypeRepo   repository.LeaveTypeRepository
+}
+
+func NewLeavePolicyUsecase(leavePolicyRepo repository.LeavePolicyRepository, leaveTypeRepo repository.LeaveTypeRepository) *LeavePolicy {
+	return &LeavePolicy{leavePolicyRepo: leavePolicyRepo, leaveTypeRepo: leaveTypeRepo}
+}
+
+func (us *LeavePolicy) GetLeavePolicies(ctx context.Context) (*dto.GetLeavePoliciesResponse, *models.ErrorResponse) {
+	response := &dto.GetLeavePoliciesResponse{}
+
+	policies, err := us.leavePolicyRepo.GetAll(ctx)
+	if err != nil {
+		return nil, &models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal Server Error",
+		}
+	}
+
+	response.MapLeavePoliciesResponse(policies)
+
+	return response, nil
+}
+
+func (us *LeavePolicy) CreateLeavePolicy(ctx context.Context, req *dto.LeavePolicyRequest) (*dto.LeavePolicyResponse, *models.ErrorResponse) {
+	response := &dto.LeavePolicyResponse{}
+	policy := req.ToLeavePolicy()
+
+	if errResponse := us.checkLeaveType(ctx, policy); errResponse != nil {
+		return nil, errResponse
+	}
+
+	err := us.leavePolicyRepo.Create(ctx, policy)
+	if err != nil {
+		return nil, &models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to create leave policy",
+		}
+	}
+
+	response.MapLeavePolicyResponse(policy)
+
+	return response, nil
+}
+
+func (us *LeavePolicy) UpdateLeavePolicy(ctx context.Context, leavePolicyID int, req *dto.LeavePolicyRequest) (*dto.LeavePolicyResponse, *models.ErrorResponse) {
+	response := &dto.LeavePolicyResponse{}
+
+	_, err := us.leavePolicyRepo.FindById(ctx, leavePolicyID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, &models.ErrorResponse{
+				Code:    http.StatusNotFound,
+				Message: "Leave Policy Not Found",
+			}
+		}
+		return nil, &models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal Server Error",
+		}
+	}
+
+	policy := req.ToLeavePolicy()
+	policy.ID = leavePolicyID
+
+	if errResponse := us.checkLeaveType(ctx, policy); errResponse != nil {
+		return nil, errResponse
+	}
+
+	err = us.leavePolicyRepo.Update(ctx, policy)
+	if err != nil {
+		return nil, &models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to update leave policy",
+		}
+	}
+
+	response.MapLeavePolicyResponse(policy)
+
+	return response, nil
+}
+
+func (us *LeavePolicy) DeleteLeavePolicy(ctx context.Context, leavePolicyID int) *models.ErrorResponse {
+	deleted, err := us.leavePolicyRepo.Delete(ctx, leavePolicyID)
+	if err != nil {
+		return &models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to delete leave policy",
+		}
+	}
+
+	if !deleted {
+		return &models.ErrorResponse{
+			Code:    http.StatusNotFound,
+			Message: "Leave Policy Not Found",
+		}
+	}
+
+	return nil
+}
+
+// checkLeaveType makes sure the policy refers to an existing leave type, and only sets an
+// accrual for one that keeps a balance.
+func (us *LeavePolicy) checkLeaveType(ctx context.Context, policy *entity.LeavePolicy) *models.ErrorResponse {
+	leaveType, errResponse := findLeaveType(ctx, us.leaveTypeRepo, policy.LeaveType)
+	if errResponse != nil {
+		return errResponse
+	}
+
+	if policy.DaysPerYear != nil && !leaveType.DeductsBalance {
+		return &models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Leave type does not accrue balance",
+		}
+	}
+
+	return nil
+}
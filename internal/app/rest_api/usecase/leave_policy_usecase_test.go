@@ -0,0 +1,208 @@
+package usecase_test
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	entity "github.com/devonLoen/leave-request-service/internal/app/rest_api/entity"
+	"github.com/devonLoen/leave-request-service/internal/app/rest_api/model/dto"
+	"github.com/devonLoen/leave-request-service/internal/app/rest_api/repository"
+	"github.com/devonLoen/leave-request-service/internal/app/rest_api/usecase"
+)
+
+type MockLeavePolicyRepo struct {
+	mock.Mock
+}
+
+func (m *MockLeavePolicyRepo) GetAll(ctx context.Context) ([]*entity.LeavePolicy, error) {
+	args := m.Called()
+	if args.Get(0) != nil {
+		return args.Get(0).([]*entity.LeavePolicy), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockLeavePolicyRepo) FindById(ctx context.Context, id int) (*entity.LeavePolicy, error) {
+	args := m.Called(id)
+	if args.Get(0) != nil {
+		return args.Get(0).(*entity.LeavePolicy), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockLeavePolicyRepo) FindApplicable(ctx context.Context, userId int, leaveType entity.LeaveRequestType, on time.Time) (*entity.LeavePolicy, error) {
+	args := m.Called(userId, leaveType, on)
+	if args.Get(0) != nil {
+		return args.Get(0).(*entity.LeavePolicy), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockLeavePolicyRepo) Create(ctx context.Context, policy *entity.LeavePolicy) error {
+	return m.Called(policy).Error(0)
+}
+
+func (m *MockLeavePolicyRepo) Update(ctx context.Context, policy *entity.LeavePolicy) error {
+	return m.Called(policy).Error(0)
+}
+
+func (m *MockLeavePolicyRepo) Delete(ctx context.Context, id int) (bool, error) {
+	args := m.Called(id)
+	return args.Bool(0), args.Error(1)
+}
+
+func TestCreateLeavePolicy(t *testing.T) {
+	entitled := true
+	daysPerYear := 18.0
+
+	tests := []struct {
+		name        string
+		leaveType   string
+		daysPerYear *float64
+		found       *entity.LeaveType
+		wantCode    int
+		wantMsg     string
+	}{
+		{name: "Unknown leave type", leaveType: "sabbatical", wantCode: http.StatusBadRequest, wantMsg: "Leave type not valid"},
+		{name: "Accrual on a type without balance", leaveType: "unpaid", daysPerYear: &daysPerYear, found: unpaidLeave, wantCode: http.StatusBadRequest, wantMsg: "Leave type does not accrue balance"},
+		{name: "Success", leaveType: "annual", daysPerYear: &daysPerYear, found: annualLeave, wantCode: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			leaveTypeRepo := new(MockLeaveTypeRepo)
+			if tt.found != nil {
+				leaveTypeRepo.On("FindByCode", tt.found.Code).Return(tt.found, nil).Once()
+			} else {
+				leaveTypeRepo.On("FindByCode", entity.LeaveRequestType(tt.leaveType)).Return(nil, sql.ErrNoRows).Once()
+			}
+			policyRepo := new(MockLeavePolicyRepo)
+			if tt.wantCode == 0 {
+				policyRepo.On("Create", mock.AnythingOfType("*entity.LeavePolicy")).Return(nil).Run(func(args mock.Arguments) {
+					args.Get(0).(*entity.LeavePolicy).ID = 1
+				}).Once()
+			}
+			uc := usecase.NewLeavePolicyUsecase(policyRepo, leaveTypeRepo)
+
+			res, err := uc.CreateLeavePolicy(context.Background(), &dto.LeavePolicyRequest{
+				Name:            "Senior staff",
+				LeaveType:       tt.leaveType,
+				Role:            "employee",
+				MinTenureMonths: 60,
+				EffectiveFrom:   "2026-01-01",
+				Entitled:        &entitled,
+				DaysPerYear:     tt.daysPerYear,
+			})
+
+			if tt.wantCode != 0 {
+				assert.Nil(t, res)
+				assert.Equal(t, tt.wantCode, err.Code)
+				assert.Equal(t, tt.wantMsg, err.Message)
+				policyRepo.AssertNotCalled(t, "Create", mock.Anything)
+			} else {
+				assert.Nil(t, err)
+				assert.Equal(t, 1, res.ID)
+				assert.Equal(t, "employee", *res.Role)
+				assert.Equal(t, "2026-01-01", res.EffectiveFrom)
+				assert.Nil(t, res.EffectiveTo)
+			}
+			policyRepo.AssertExpectations(t)
+			leaveTypeRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCreateLeaveRequestLeavePolicyRules(t *testing.T) {
+	monday := nextMonday()
+	maxDays := 1.0
+	noNotice := 0
+
+	tests := []struct {
+		name          string
+		leaveType     *entity.LeaveType
+		policy        *entity.LeavePolicy
+		req           dto.CreateLeaveRequestRequest
+		lookups       int
+		checksOverlap bool
+		wantCode      int
+		wantMsg       string
+	}{
+		{
+			name:          "Not entitled under the policy",
+			leaveType:     annualLeave,
+			policy:        &entity.LeavePolicy{Name: "Interns probation", LeaveType: entity.Annual},
+			req:           dto.CreateLeaveRequestRequest{StartDate: monday, EndDate: monday, Type: "annual", Status: "draft"},
+			lookups:       1,
+			checksOverlap: true,
+			wantCode:      http.StatusUnprocessableEntity,
+			wantMsg:       "Annual Leave is not available to you under the Interns probation policy.",
+		},
+		{
+			name:      "Policy limits the duration",
+			leaveType: unpaidLeave,
+			policy:    &entity.LeavePolicy{Name: "Interns", LeaveType: entity.Unpaid, Entitled: true, MaxConsecutiveDays: &maxDays},
+			req:       dto.CreateLeaveRequestRequest{StartDate: monday, EndDate: monday.AddDate(0, 0, 1), Type: "unpaid", Status: "draft"},
+			lookups:   1,
+			wantCode:  http.StatusUnprocessableEntity,
+			wantMsg:   "Unpaid Leave is limited to 1.0 consecutive working day(s), 2.0 requested.",
+		},
+		{
+			name:          "Policy waives the notice period",
+			leaveType:     &entity.LeaveType{Code: "study", Name: "Study Leave", MinNoticeDays: 30, Active: true},
+			policy:        &entity.LeavePolicy{Name: "Seniors", LeaveType: "study", Entitled: true, MinNoticeDays: &noNotice},
+			req:           dto.CreateLeaveRequestRequest{StartDate: monday, EndDate: monday, Type: "study", Status: "waiting_approval"},
+			lookups:       2,
+			checksOverlap: true,
+			wantCode:      0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			leaveTypeRepo := new(MockLeaveTypeRepo)
+			leaveTypeRepo.On("FindByCode", tt.leaveType.Code).Return(tt.leaveType, nil).Times(tt.lookups)
+			leavePolicyRepo := new(MockLeavePolicyRepo)
+			leavePolicyRepo.On("FindApplicable", 1, tt.leaveType.Code, mock.Anything).Return(tt.policy, nil).Once()
+
+			mockRepo := new(MockLeaveRequestRepo)
+			mockHolidayRepo := new(MockHolidayRepo)
+			mockHolidayRepo.On("GetHolidaysBetween", mock.Anything, mock.Anything).Return([]*entity.Holiday{}, nil)
+			if tt.checksOverlap {
+				mockRepo.On("OverlapApprovedLeaveExists", 1, mock.Anything, mock.Anything).Return(false, nil).Once()
+			}
+			mockApprovalRepo := new(MockApprovalRepo)
+			ruleRepo := new(MockAutoApprovalRuleRepo)
+			if tt.wantCode == 0 {
+				ruleRepo.On("GetActive").Return([]*entity.AutoApprovalRule{}, nil).Once()
+				mockRepo.On("Create", mock.Anything, 1).Return(nil).Once()
+				mockApprovalRepo.On("FindChainFor", mock.Anything, mock.Anything).Return(nil, sql.ErrNoRows)
+				mockApprovalRepo.On("CreateSteps", mock.Anything, entity.DefaultApprovalSteps).Return(nil)
+			}
+
+			userRepo := newUserRepo(t)
+			uc := usecase.NewLeaveRequestUsecase(mockRepo, new(MockLeaveBalanceRepo), mockHolidayRepo, leaveTypeRepo, leavePolicyRepo, ruleRepo, userRepo, mockApprovalRepo, new(MockDelegationRepo), new(MockAttachmentRepo), new(MockStorage), &MockUnitOfWork{repos: &repository.Repositories{User: userRepo, LeaveRequest: mockRepo, Approval: mockApprovalRepo}})
+
+			tt.req.Reason = "Preparing for the certification exam"
+			res, errCreate := uc.CreateLeaveRequest(context.Background(), &tt.req, 1)
+
+			if tt.wantCode != 0 {
+				assert.Nil(t, res)
+				assert.Equal(t, tt.wantCode, errCreate.Code)
+				assert.Equal(t, tt.wantMsg, errCreate.Message)
+			} else {
+				assert.Nil(t, errCreate)
+				assert.NotNil(t, res)
+			}
+			mockRepo.AssertExpectations(t)
+			leaveTypeRepo.AssertExpectations(t)
+			leavePolicyRepo.AssertExpectations(t)
+			ruleRepo.AssertExpectations(t)
+		})
+	}
+}
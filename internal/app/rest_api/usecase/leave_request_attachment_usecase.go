@@ -0,0 +1,371 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	entity "github.com/devonLoen/leave-request-service/internal/app/rest_api/entity"
+	models "github.com/devonLoen/leave-request-service/internal/app/rest_api/model"
+	"github.com/devonLoen/leave-request-service/internal/app/rest_api/model/dto"
+	"github.com/devonLoen/leave-request-service/internal/app/rest_api/pkg/storage"
+)
+
+// MaxAttachmentSize is the largest supporting document that can be uploaded, in bytes.
+const MaxAttachmentSize = 5 << 20
+
+// attachmentContentTypes are the file types accepted as supporting documents. The type is
+// sniffed from the content; the name and the type the client sent are not trusted.
+var attachmentContentTypes = map[string]bool{
+	"application/pdf": true,
+	"image/jpeg":      true,
+	"image/png":       true,
+}
+
+// UploadAttachment stores a supporting document for the owner's request while it is a draft
+// or waiting for approval.
+func (us *LeaveRequest) UploadAttachment(ctx context.Context, leaveRequestID, userID int, fileName string, size int64, file io.Reader) (*dto.AttachmentResponse, *models.ErrorResponse) {
+	response := &dto.AttachmentResponse{}
+
+	leaveRequest, errFind := us.findAttachmentLeaveRequest(ctx, leaveRequestID)
+	if errFind != nil {
+		return nil, errFind
+	}
+
+	errOwner := authorizeAttachmentOwner(leaveRequest, userID)
+	if errOwner != nil {
+		return nil, errOwner
+	}
+
+	if leaveRequest.Status != entity.Draft && leaveRequest.Status != entity.WaitingApproval {
+		return nil, &models.ErrorResponse{
+			Code:    http.StatusUnprocessableEntity,
+			Message: "Attachments can only be added while the leave request is a draft or waiting for approval",
+		}
+	}
+
+	if size > MaxAttachmentSize {
+		return nil, errAttachmentTooLarge()
+	}
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return nil, &models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Unable to read uploaded file",
+		}
+	}
+	if n == 0 {
+		return nil, &models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Attachment is empty",
+		}
+	}
+
+	contentType, _, _ := strings.Cut(http.DetectContentType(head[:n]), ";")
+	if !attachmentContentTypes[contentType] {
+		return nil, &models.ErrorResponse{
+			Code:    http.StatusUnsupportedMediaType,
+			Message: "Attachments must be PDF, JPEG or PNG files",
+		}
+	}
+
+	key, err := newAttachmentKey(leaveRequest.ID)
+	if err != nil {
+		return nil, &models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal Server Error",
+		}
+	}
+
+	// The size the client declared is only a hint: the stored file is cut off one byte past
+	// the limit so an oversized upload can be told apart and removed.
+	content := &io.LimitedReader{R: io.MultiReader(bytes.NewReader(head[:n]), file), N: MaxAttachmentSize + 1}
+	err = us.attachmentStorage.Put(ctx, key, content, size, contentType)
+	if err != nil {
+		return nil, &models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to store attachment",
+		}
+	}
+
+	stored := MaxAttachmentSize + 1 - content.N
+	if stored > MaxAttachmentSize {
+		_ = us.attachmentStorage.Delete(ctx, key)
+		return nil, errAttachmentTooLarge()
+	}
+
+	attachment := &entity.LeaveRequestAttachment{
+		LeaveRequestId: leaveRequest.ID,
+		UploadedBy:     userID,
+		FileName:       attachmentFileName(fileName),
+		ContentType:    contentType,
+		Size:           stored,
+		StorageKey:     key,
+	}
+
+	err = us.attachmentRepo.Create(ctx, attachment)
+	if err != nil {
+		_ = us.attachmentStorage.Delete(ctx, key)
+		return nil, &models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to save attachment",
+		}
+	}
+
+	response.MapAttachmentResponse(attachment)
+
+	return response, nil
+}
+
+// GetAttachments lists the supporting documents of a request to its owner and its approvers.
+func (us *LeaveRequest) GetAttachments(ctx context.Context, leaveRequestID, userID int) (*dto.GetAttachmentsResponse, *models.ErrorResponse) {
+	response := &dto.GetAttachmentsResponse{}
+
+	leaveRequest, errFind := us.findAttachmentLeaveRequest(ctx, leaveRequestID)
+	if errFind != nil {
+		return nil, errFind
+	}
+
+	errAuthorize := us.authorizeAttachmentReader(ctx, leaveRequest, userID)
+	if errAuthorize != nil {
+		return nil, errAuthorize
+	}
+
+	attachments, err := us.attachmentRepo.GetByLeaveRequest(ctx, leaveRequest.ID)
+	if err != nil {
+		return nil, &models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal Server Error",
+		}
+	}
+
+	response.MapAttachmentsResponse(attachments)
+
+	return response, nil
+}
+
+// DownloadAttachment opens a supporting document for the request's owner or approvers. The
+// caller closes the returned reader.
+func (us *LeaveRequest) DownloadAttachment(ctx context.Context, leaveRequestID, attachmentID, userID int) (*entity.LeaveRequestAttachment, io.ReadCloser, *models.ErrorResponse) {
+	leaveRequest, errFind := us.findAttachmentLeaveRequest(ctx, leaveRequestID)
+	if errFind != nil {
+		return nil, nil, errFind
+	}
+
+	errAuthorize := us.authorizeAttachmentReader(ctx, leaveRequest, userID)
+	if errAuthorize != nil {
+		return nil, nil, errAuthorize
+	}
+
+	attachment, errAttachment := us.findAttachment(ctx, leaveRequest.ID, attachmentID)
+	if errAttachment != nil {
+		return nil, nil, errAttachment
+	}
+
+	content, err := us.attachmentStorage.Open(ctx, attachment.StorageKey)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, nil, errAttachmentNotFound()
+		}
+		return nil, nil, &models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal Server Error",
+		}
+	}
+
+	return attachment, content, nil
+}
+
+// DeleteAttachment removes a supporting document from the owner's draft. Once a request is
+// submitted its documents stay, so the approver sees what it was submitted with.
+func (us *LeaveRequest) DeleteAttachment(ctx context.Context, leaveRequestID, attachmentID, userID int) *models.ErrorResponse {
+	leaveRequest, errFind := us.findAttachmentLeaveRequest(ctx, leaveRequestID)
+	if errFind != nil {
+		return errFind
+	}
+
+	errOwner := authorizeAttachmentOwner(leaveRequest, userID)
+	if errOwner != nil {
+		return errOwner
+	}
+
+	if leaveRequest.Status != entity.Draft {
+		return &models.ErrorResponse{
+			Code:    http.StatusUnprocessableEntity,
+			Message: "Attachments can only be removed while the leave request is a draft",
+		}
+	}
+
+	attachment, errAttachment := us.findAttachment(ctx, leaveRequest.ID, attachmentID)
+	if errAttachment != nil {
+		return errAttachment
+	}
+
+	deleted, err := us.attachmentRepo.Delete(ctx, attachment.ID)
+	if err != nil {
+		return &models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to delete attachment",
+		}
+	}
+	if !deleted {
+		return errAttachmentNotFound()
+	}
+
+	// The row is gone, so a file left behind by a failed delete is never served again.
+	_ = us.attachmentStorage.Delete(ctx, attachment.StorageKey)
+
+	return nil
+}
+
+// checkAttachments refuses to put a request that needs a supporting document up for
+// approval without one.
+func (us *LeaveRequest) checkAttachments(ctx context.Context, leaveRequest *entity.LeaveRequest, leaveType *entity.LeaveType) *models.ErrorResponse {
+	if !leaveType.RequiresAttachmentFor(leaveRequest.WorkingDays) {
+		return nil
+	}
+
+	count := 0
+	if leaveRequest.ID != 0 {
+		var err error
+		count, err = us.attachmentRepo.CountByLeaveRequest(ctx, leaveRequest.ID)
+		if err != nil {
+			return &models.ErrorResponse{
+				Code:    http.StatusInternalServerError,
+				Message: "Internal Server Error",
+			}
+		}
+	}
+
+	if count == 0 {
+		return &models.ErrorResponse{
+			Code:    http.StatusUnprocessableEntity,
+			Message: fmt.Sprintf("%s of more than %.1f working day(s) needs a supporting document. Save the request as a draft, attach the document and submit it.", leaveType.Name, leaveType.AttachmentAfterDays),
+		}
+	}
+
+	return nil
+}
+
+// deleteStoredAttachments removes the files of attachments whose rows are already gone.
+func (us *LeaveRequest) deleteStoredAttachments(ctx context.Context, attachments []*entity.LeaveRequestAttachment) {
+	for _, attachment := range attachments {
+		_ = us.attachmentStorage.Delete(ctx, attachment.StorageKey)
+	}
+}
+
+func (us *LeaveRequest) findAttachmentLeaveRequest(ctx context.Context, leaveRequestID int) (*entity.LeaveRequest, *models.ErrorResponse) {
+	leaveRequest, err := us.leaveRequestRepo.FindById(ctx, leaveRequestID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, &models.ErrorResponse{
+				Code:    http.StatusNotFound,
+				Message: "Leave Request Not Found",
+			}
+		}
+		return nil, &models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal Server Error",
+		}
+	}
+
+	return leaveRequest, nil
+}
+
+func (us *LeaveRequest) findAttachment(ctx context.Context, leaveRequestID, attachmentID int) (*entity.LeaveRequestAttachment, *models.ErrorResponse) {
+	attachment, err := us.attachmentRepo.FindById(ctx, leaveRequestID, attachmentID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errAttachmentNotFound()
+		}
+		return nil, &models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal Server Error",
+		}
+	}
+
+	return attachment, nil
+}
+
+func authorizeAttachmentOwner(leaveRequest *entity.LeaveRequest, userID int) *models.ErrorResponse {
+	if leaveRequest.UserId != userID {
+		return &models.ErrorResponse{
+			Code:    http.StatusForbidden,
+			Message: "The specified leave request belongs to another user.",
+		}
+	}
+
+	return nil
+}
+
+// authorizeAttachmentReader lets the owner read the documents of their request, and anyone
+// who may decide a manager or admin step of it, delegates included.
+func (us *LeaveRequest) authorizeAttachmentReader(ctx context.Context, leaveRequest *entity.LeaveRequest, userID int) *models.ErrorResponse {
+	if leaveRequest.UserId == userID {
+		return nil
+	}
+
+	for _, kind := range []entity.ApproverKind{entity.ApproverManager, entity.ApproverAdmin} {
+		_, errAuthorize := us.authorizeDecision(ctx, leaveRequest, kind, userID)
+		if errAuthorize == nil {
+			return nil
+		}
+		if errAuthorize.Code != http.StatusForbidden {
+			return errAuthorize
+		}
+	}
+
+	return &models.ErrorResponse{
+		Code:    http.StatusForbidden,
+		Message: "Only the owner and the approvers of the leave request can read its attachments.",
+	}
+}
+
+func errAttachmentNotFound() *models.ErrorResponse {
+	return &models.ErrorResponse{
+		Code:    http.StatusNotFound,
+		Message: "Attachment Not Found",
+	}
+}
+
+func errAttachmentTooLarge() *models.ErrorResponse {
+	return &models.ErrorResponse{
+		Code:    http.StatusRequestEntityTooLarge,
+		Message: fmt.Sprintf("Attachments cannot be larger than %d MB", MaxAttachmentSize>>20),
+	}
+}
+
+// newAttachmentKey picks a random storage key below the request's prefix.
+func newAttachmentKey(leaveRequestID int) (string, error) {
+	random := make([]byte, 16)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("leave-requests/%d/%s", leaveRequestID, hex.EncodeToString(random)), nil
+}
+
+// attachmentFileName keeps the base name of an uploaded file, shortened to fit the column.
+func attachmentFileName(fileName string) string {
+	name := strings.TrimSpace(filepath.Base(strings.ReplaceAll(fileName, "\\", "/")))
+	if name == "" || name == "." || name == "/" {
+		return "attachment"
+	}
+
+	name = strings.ToValidUTF8(name, "")
+	if runes := []rune(name); len(runes) > 255 {
+		name = string(runes[:255])
+	}
+
+	return name
+}
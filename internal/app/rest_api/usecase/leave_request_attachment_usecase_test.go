@@ -0,0 +1,253 @@
+package usecase_test
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	entity "github.com/devonLoen/leave-request-service/internal/app/rest_api/entity"
+	"github.com/devonLoen/leave-request-service/internal/app/rest_api/repository"
+	"github.com/devonLoen/leave-request-service/internal/app/rest_api/usecase"
+)
+
+type MockAttachmentRepo struct {
+	mock.Mock
+}
+
+func (m *MockAttachmentRepo) Create(ctx context.Context, attachment *entity.LeaveRequestAttachment) error {
+	args := m.Called(attachment)
+	return args.Error(0)
+}
+
+func (m *MockAttachmentRepo) FindById(ctx context.Context, leaveRequestId, id int) (*entity.LeaveRequestAttachment, error) {
+	args := m.Called(leaveRequestId, id)
+	if args.Get(0) != nil {
+		return args.Get(0).(*entity.LeaveRequestAttachment), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockAttachmentRepo) GetByLeaveRequest(ctx context.Context, leaveRequestId int) ([]*entity.LeaveRequestAttachment, error) {
+	args := m.Called(leaveRequestId)
+	if args.Get(0) != nil {
+		return args.Get(0).([]*entity.LeaveRequestAttachment), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockAttachmentRepo) CountByLeaveRequest(ctx context.Context, leaveRequestId int) (int, error) {
+	args := m.Called(leaveRequestId)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockAttachmentRepo) Delete(ctx context.Context, id int) (bool, error) {
+	args := m.Called(id)
+	return args.Bool(0), args.Error(1)
+}
+
+// MockStorage records what is stored; Put drains the reader so the usecase sees the size.
+type MockStorage struct {
+	mock.Mock
+	stored []byte
+}
+
+func (m *MockStorage) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	m.stored = content
+
+	args := m.Called(key, contentType)
+	return args.Error(0)
+}
+
+func (m *MockStorage) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	args := m.Called(key)
+	if args.Get(0) != nil {
+		return args.Get(0).(io.ReadCloser), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockStorage) Delete(ctx context.Context, key string) error {
+	args := m.Called(key)
+	return args.Error(0)
+}
+
+func TestUploadAttachment(t *testing.T) {
+	pdf := []byte("%PDF-1.7\n1 0 obj << /Type /Catalog >> endobj\n")
+
+	tests := []struct {
+		name      string
+		userID    int
+		status    entity.LeaveRequestStatus
+		content   []byte
+		size      int64
+		setupMock func(mockAttachmentRepo *MockAttachmentRepo, mockStorage *MockStorage)
+		wantCode  int
+	}{
+		{
+			name:      "Another user's request",
+			userID:    2,
+			status:    entity.Draft,
+			content:   pdf,
+			setupMock: func(mockAttachmentRepo *MockAttachmentRepo, mockStorage *MockStorage) {},
+			wantCode:  http.StatusForbidden,
+		},
+		{
+			name:      "Decided request",
+			userID:    1,
+			status:    entity.Approved,
+			content:   pdf,
+			setupMock: func(mockAttachmentRepo *MockAttachmentRepo, mockStorage *MockStorage) {},
+			wantCode:  http.StatusUnprocessableEntity,
+		},
+		{
+			name:      "Declared size over the limit",
+			userID:    1,
+			status:    entity.Draft,
+			content:   pdf,
+			size:      usecase.MaxAttachmentSize + 1,
+			setupMock: func(mockAttachmentRepo *MockAttachmentRepo, mockStorage *MockStorage) {},
+			wantCode:  http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:      "Content sniffed as text",
+			userID:    1,
+			status:    entity.Draft,
+			content:   []byte("%PDF is only in the name of this file"),
+			setupMock: func(mockAttachmentRepo *MockAttachmentRepo, mockStorage *MockStorage) {},
+			wantCode:  http.StatusUnsupportedMediaType,
+		},
+		{
+			name:    "Content larger than declared",
+			userID:  1,
+			status:  entity.WaitingApproval,
+			content: append(append([]byte{}, pdf...), make([]byte, usecase.MaxAttachmentSize)...),
+			size:    int64(len(pdf)),
+			setupMock: func(mockAttachmentRepo *MockAttachmentRepo, mockStorage *MockStorage) {
+				mockStorage.On("Put", mock.Anything, "application/pdf").Return(nil).Once()
+				mockStorage.On("Delete", mock.Anything).Return(nil).Once()
+			},
+			wantCode: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:    "PDF is stored",
+			userID:  1,
+			status:  entity.WaitingApproval,
+			content: pdf,
+			setupMock: func(mockAttachmentRepo *MockAttachmentRepo, mockStorage *MockStorage) {
+				mockStorage.On("Put", mock.MatchedBy(func(key string) bool {
+					return strings.HasPrefix(key, "leave-requests/7/")
+				}), "application/pdf").Return(nil).Once()
+				mockAttachmentRepo.On("Create", mock.MatchedBy(func(attachment *entity.LeaveRequestAttachment) bool {
+					return attachment.LeaveRequestId == 7 && attachment.UploadedBy == 1 &&
+						attachment.FileName == "certificate.pdf" && attachment.Size == int64(len(pdf))
+				})).Return(nil).Once()
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockLeaveRequestRepo)
+			mockAttachmentRepo := new(MockAttachmentRepo)
+			mockStorage := new(MockStorage)
+			uc := usecase.NewLeaveRequestUsecase(mockRepo, new(MockLeaveBalanceRepo), new(MockHolidayRepo), new(MockLeaveTypeRepo), new(MockLeavePolicyRepo), new(MockAutoApprovalRuleRepo), newUserRepo(t), new(MockApprovalRepo), new(MockDelegationRepo), mockAttachmentRepo, mockStorage, &MockUnitOfWork{})
+
+			mockRepo.On("FindById", 7).
+				Return(&entity.LeaveRequest{ID: 7, UserId: 1, Type: entity.Sick, Status: tt.status}, nil).Once()
+			tt.setupMock(mockAttachmentRepo, mockStorage)
+
+			size := tt.size
+			if size == 0 {
+				size = int64(len(tt.content))
+			}
+
+			attachment, errResp := uc.UploadAttachment(context.Background(), 7, tt.userID, `C:\Users\me\certificate.pdf`, size, bytes.NewReader(tt.content))
+
+			if tt.wantCode != 0 {
+				assert.Nil(t, attachment)
+				assert.Equal(t, tt.wantCode, errResp.Code)
+			} else {
+				assert.Nil(t, errResp)
+				assert.Equal(t, "application/pdf", attachment.ContentType)
+				assert.Equal(t, pdf, mockStorage.stored)
+			}
+			mockAttachmentRepo.AssertExpectations(t)
+			mockStorage.AssertExpectations(t)
+		})
+	}
+}
+
+func TestSubmitRequiresAttachment(t *testing.T) {
+	monday := nextMonday()
+
+	tests := []struct {
+		name        string
+		workingDays float64
+		attachments int
+		wantCode    int
+	}{
+		{name: "Short sick leave needs no document", workingDays: 2, wantCode: 0},
+		{name: "Long sick leave without a document", workingDays: 3, attachments: 0, wantCode: http.StatusUnprocessableEntity},
+		{name: "Long sick leave with a document", workingDays: 3, attachments: 1, wantCode: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockLeaveRequestRepo)
+			mockBalanceRepo := new(MockLeaveBalanceRepo)
+			mockAttachmentRepo := new(MockAttachmentRepo)
+			mockApprovalRepo := new(MockApprovalRepo)
+			leaveTypeRepo := new(MockLeaveTypeRepo)
+			leaveTypeRepo.On("FindByCode", entity.Sick).Return(sickLeave, nil).Once()
+			leavePolicyRepo := new(MockLeavePolicyRepo)
+			leavePolicyRepo.On("FindApplicable", 1, entity.Sick, mock.Anything).Return(nil, sql.ErrNoRows).Once()
+			ruleRepo := new(MockAutoApprovalRuleRepo)
+			if tt.wantCode == 0 {
+				ruleRepo.On("GetActive").Return([]*entity.AutoApprovalRule{}, nil).Once()
+			}
+			unitOfWork := &MockUnitOfWork{repos: &repository.Repositories{LeaveRequest: mockRepo, LeaveBalance: mockBalanceRepo, Approval: mockApprovalRepo}}
+			uc := usecase.NewLeaveRequestUsecase(mockRepo, mockBalanceRepo, new(MockHolidayRepo), leaveTypeRepo, leavePolicyRepo, ruleRepo, newUserRepo(t), mockApprovalRepo, new(MockDelegationRepo), mockAttachmentRepo, new(MockStorage), unitOfWork)
+
+			mockRepo.On("FindById", 7).
+				Return(&entity.LeaveRequest{ID: 7, UserId: 1, StartDate: monday, Type: entity.Sick, Status: entity.Draft, WorkingDays: tt.workingDays}, nil).Once()
+			if tt.workingDays > 2 {
+				mockAttachmentRepo.On("CountByLeaveRequest", 7).Return(tt.attachments, nil).Once()
+			}
+			// A submission that passes the checks fails on its first write, which is all this
+			// test needs to tell it apart from one refused for a missing document.
+			if tt.wantCode == 0 {
+				mockBalanceRepo.On("LockUser", 1).Return(nil).Once()
+				mockBalanceRepo.On("GetBalance", 1, monday.Year(), entity.Sick, 7).
+					Return(&entity.LeaveBalance{Entitled: 12}, nil).Once()
+				mockRepo.On("Submit", 7, 1).Return(false, nil).Once()
+			}
+
+			_, errResp := uc.Submit(context.Background(), 7, 1, 0)
+
+			assert.NotNil(t, errResp)
+			if tt.wantCode != 0 {
+				assert.Equal(t, tt.wantCode, errResp.Code)
+				mockRepo.AssertNotCalled(t, "Submit", 7, 1)
+			} else {
+				assert.Equal(t, http.StatusConflict, errResp.Code)
+			}
+			mockRepo.AssertExpectations(t)
+			mockBalanceRepo.AssertExpectations(t)
+			mockAttachmentRepo.AssertExpectations(t)
+			leaveTypeRepo.AssertExpectations(t)
+			leavePolicyRepo.AssertExpectations(t)
+			ruleRepo.AssertExpectations(t)
+		})
+	}
+}
@@ -1,8 +1,12 @@
 package usecase
 
 import (
+	"context"
 	"database/sql"
 	"errors"
+	"fmt"
+	"io"
+	"math"
 	"net/http"
 	"strings"
 	"time"
@@ -10,44 +14,109 @@ import (
 	entity "github.com/devonLoen/leave-request-service/internal/app/rest_api/entity"
 	models "github.com/devonLoen/leave-request-service/internal/app/rest_api/model"
 	"github.com/devonLoen/leave-request-service/internal/app/rest_api/model/dto"
+	"github.com/devonLoen/leave-request-service/internal/app/rest_api/pkg/storage"
+	"github.com/devonLoen/leave-request-service/internal/app/rest_api/pkg/util"
 	"github.com/devonLoen/leave-request-service/internal/app/rest_api/repository"
+	"github.com/lib/pq"
 )
 
 type LeaveRequestUsecase interface {
-	CreateLeaveRequest(*dto.CreateLeaveRequestRequest, int) (*dto.CreateLeaveRequestResponse, *models.ErrorResponse)
-	GetAllLeaveRequests(limit, offset int, sortBy, orderBy, search string, filter entity.LeaveRequestFilter) (*dto.GetAllLeaveRequestsResponse, *models.ErrorResponse)
-	GetLeaveRequest(leaveRequestID int) (*dto.LeaveRequestResponse, *models.ErrorResponse)
-	Approve(leaveRequestID int) *models.ErrorResponse
-	Reject(leaveRequestID int) *models.ErrorResponse
-	Submit(leaveRequestID, userID int) *models.ErrorResponse
+	CreateLeaveRequest(ctx context.Context, req *dto.CreateLeaveRequestRequest, userID int) (*dto.CreateLeaveRequestResponse, *models.ErrorResponse)
+	UpdateLeaveRequest(ctx context.Context, leaveRequestID int, req *dto.CreateLeaveRequestRequest, userID, version int) (*dto.LeaveRequestResponse, *models.ErrorResponse)
+	DeleteLeaveRequest(ctx context.Context, leaveRequestID, userID, version int) *models.ErrorResponse
+	GetAllLeaveRequests(ctx context.Context, limit, offset int, sortBy, orderBy, search string, filter entity.LeaveRequestFilter) (*dto.GetAllLeaveRequestsResponse, *models.ErrorResponse)
+	ExportLeaveRequests(ctx context.Context, w io.Writer, format util.ExportFormat, sortBy, orderBy, search string, filter entity.LeaveRequestFilter) *models.ErrorResponse
+	GetLeaveRequest(ctx context.Context, leaveRequestID int) (*dto.LeaveRequestResponse, *models.ErrorResponse)
+	GetLeaveRequestHistory(ctx context.Context, leaveRequestID, userID int) (*dto.GetLeaveRequestHistoryResponse, *models.ErrorResponse)
+	GetLeaveRequestActions(ctx context.Context, leaveRequestID, userID int) (*dto.LeaveRequestActionsResponse, *models.ErrorResponse)
+	Approve(ctx context.Context, leaveRequestID, approverID, version int, comment string) (entity.LeaveRequestStatus, *models.ErrorResponse)
+	Reject(ctx context.Context, leaveRequestID, approverID, version int, reason string) *models.ErrorResponse
+	BulkDecide(ctx context.Context, approverID int, bulkDecisionRequest *dto.BulkDecisionRequest) (*dto.BulkDecisionResponse, *models.ErrorResponse)
+	Submit(ctx context.Context, leaveRequestID, userID, version int) (entity.LeaveRequestStatus, *models.ErrorResponse)
+	Cancel(ctx context.Context, leaveRequestID, userID, version int) (entity.LeaveRequestStatus, *models.ErrorResponse)
+	ApproveCancellation(ctx context.Context, leaveRequestID, approverID, version int) *models.ErrorResponse
+	RejectCancellation(ctx context.Context, leaveRequestID, approverID, version int) *models.ErrorResponse
+	UploadAttachment(ctx context.Context, leaveRequestID, userID int, fileName string, size int64, file io.Reader) (*dto.AttachmentResponse, *models.ErrorResponse)
+	GetAttachments(ctx context.Context, leaveRequestID, userID int) (*dto.GetAttachmentsResponse, *models.ErrorResponse)
+	DownloadAttachment(ctx context.Context, leaveRequestID, attachmentID, userID int) (*entity.LeaveRequestAttachment, io.ReadCloser, *models.ErrorResponse)
+	DeleteAttachment(ctx context.Context, leaveRequestID, attachmentID, userID int) *models.ErrorResponse
 }
 
+// The version parameters carry the version of the leave request the caller last read (the
+// If-Match header). When it is not zero and the request has moved on since, the call fails
+// with 412 Precondition Failed. A request that changes while the call is running fails it
+// with 409 Conflict.
+
 type LeaveRequest struct {
-	leaveRequestRepo repository.LeaveRequestRepository
+	leaveRequestRepo  repository.LeaveRequestRepository
+	leaveBalanceRepo  repository.LeaveBalanceRepository
+	holidayRepo       repository.HolidayRepository
+	leaveTypeRepo     repository.LeaveTypeRepository
+	leavePolicyRepo   repository.LeavePolicyRepository
+	autoApprovalRepo  repository.AutoApprovalRuleRepository
+	userRepo          *repository.User
+	approvalRepo      repository.ApprovalRepository
+	delegationRepo    repository.DelegationRepository
+	attachmentRepo    repository.AttachmentRepository
+	attachmentStorage storage.Storage
+	unitOfWork        repository.UnitOfWork
 }
 
-func NewLeaveRequestUsecase(leaveRequestRepo repository.LeaveRequestRepository) *LeaveRequest {
-	return &LeaveRequest{leaveRequestRepo: leaveRequestRepo}
+func NewLeaveRequestUsecase(leaveRequestRepo repository.LeaveRequestRepository, leaveBalanceRepo repository.LeaveBalanceRepository, holidayRepo repository.HolidayRepository, leaveTypeRepo repository.LeaveTypeRepository, leavePolicyRepo repository.LeavePolicyRepository, autoApprovalRepo repository.AutoApprovalRuleRepository, userRepo *repository.User, approvalRepo repository.ApprovalRepository, delegationRepo repository.DelegationRepository, attachmentRepo repository.AttachmentRepository, attachmentStorage storage.Storage, unitOfWork repository.UnitOfWork) *LeaveRequest {
+	return &LeaveRequest{leaveRequestRepo: leaveRequestRepo, leaveBalanceRepo: leaveBalanceRepo, holidayRepo: holidayRepo, leaveTypeRepo: leaveTypeRepo, leavePolicyRepo: leavePolicyRepo, autoApprovalRepo: autoApprovalRepo, userRepo: userRepo, approvalRepo: approvalRepo, delegationRepo: delegationRepo, attachmentRepo: attachmentRepo, attachmentStorage: attachmentStorage, unitOfWork: unitOfWork}
 }
 
-func (us *LeaveRequest) GetAllLeaveRequests(limit, offset int, sortBy, orderBy, search string, filter entity.LeaveRequestFilter) (*dto.GetAllLeaveRequestsResponse, *models.ErrorResponse) {
-	response := &dto.GetAllLeaveRequestsResponse{}
+// errRolledBack makes the unit of work roll back a change that failed with an error response.
+var errRolledBack = errors.New("leave request change rolled back")
 
-	allowedSorts := map[string]bool{
-		"id":        true,
-		"userId":    true,
-		"startDate": true,
-		"endDate":   true,
-		"type":      true,
-		"status":    true,
-		"reason":    true,
+// inTx runs fn with a copy of the usecase whose repositories share one transaction, so the
+// status change, its history and its effects on the approval chain and balance are stored
+// together or not at all.
+func (us *LeaveRequest) inTx(ctx context.Context, fn func(tx *LeaveRequest) *models.ErrorResponse) *models.ErrorResponse {
+	var errResp *models.ErrorResponse
+	err := us.unitOfWork.Do(ctx, func(repos *repository.Repositories) error {
+		tx := *us
+		tx.userRepo = repos.User
+		tx.leaveRequestRepo = repos.LeaveRequest
+		tx.leaveBalanceRepo = repos.LeaveBalance
+		tx.approvalRepo = repos.Approval
+
+		errResp = fn(&tx)
+		if errResp != nil {
+			return errRolledBack
+		}
+		return nil
+	})
+	if errResp != nil {
+		return errResp
+	}
+	if err != nil {
+		return &models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal Server Error",
+		}
 	}
 
-	safeSortBy := "id"
-	if allowedSorts[sortBy] {
-		safeSortBy = sortBy
-	} else {
-		return nil, &models.ErrorResponse{
+	return nil
+}
+
+// leaveRequestSortColumns maps the sort parameters of the leave request listings to columns.
+var leaveRequestSortColumns = map[string]string{
+	"id":        "id",
+	"userId":    "user_id",
+	"startDate": "start_date",
+	"endDate":   "end_date",
+	"type":      "type",
+	"status":    "status",
+	"reason":    "reason",
+}
+
+// validateListing checks the sort, order and status filter of a leave request listing and
+// returns the column and direction to sort by.
+func validateListing(sortBy, orderBy string, filter entity.LeaveRequestFilter) (string, string, *models.ErrorResponse) {
+	sortColumn, ok := leaveRequestSortColumns[sortBy]
+	if !ok {
+		return "", "", &models.ErrorResponse{
 			Code:    http.StatusBadRequest,
 			Message: "Invalid sort parameter",
 		}
@@ -57,7 +126,7 @@ func (us *LeaveRequest) GetAllLeaveRequests(limit, offset int, sortBy, orderBy,
 	if strings.ToUpper(orderBy) == "DESC" {
 		safeOrderBy = "DESC"
 	} else if strings.ToUpper(orderBy) != "ASC" {
-		return nil, &models.ErrorResponse{
+		return "", "", &models.ErrorResponse{
 			Code:    http.StatusBadRequest,
 			Message: "Invalid order parameter",
 		}
@@ -66,14 +135,25 @@ func (us *LeaveRequest) GetAllLeaveRequests(limit, offset int, sortBy, orderBy,
 	if filter.Status != "" {
 		statusEnum := entity.LeaveRequestStatus(filter.Status)
 		if !statusEnum.IsValidStatus() {
-			return nil, &models.ErrorResponse{
+			return "", "", &models.ErrorResponse{
 				Code:    http.StatusBadRequest,
 				Message: "Invalid Status Filter parameter",
 			}
 		}
 	}
 
-	queriedLeaveRequests, err := us.leaveRequestRepo.GetAllLeaveRequests(limit, offset, safeSortBy, safeOrderBy, search, filter)
+	return sortColumn, safeOrderBy, nil
+}
+
+func (us *LeaveRequest) GetAllLeaveRequests(ctx context.Context, limit, offset int, sortBy, orderBy, search string, filter entity.LeaveRequestFilter) (*dto.GetAllLeaveRequestsResponse, *models.ErrorResponse) {
+	response := &dto.GetAllLeaveRequestsResponse{}
+
+	safeSortBy, safeOrderBy, errValidate := validateListing(sortBy, orderBy, filter)
+	if errValidate != nil {
+		return nil, errValidate
+	}
+
+	queriedLeaveRequests, err := us.leaveRequestRepo.GetAllLeaveRequests(ctx, limit, offset, safeSortBy, safeOrderBy, search, filter)
 	if err != nil {
 		return nil, &models.ErrorResponse{
 			Code:    http.StatusInternalServerError,
@@ -86,10 +166,70 @@ func (us *LeaveRequest) GetAllLeaveRequests(limit, offset int, sortBy, orderBy,
 	return response, nil
 }
 
-func (us *LeaveRequest) GetLeaveRequest(leaveRequestID int) (*dto.LeaveRequestResponse, *models.ErrorResponse) {
+// leaveRequestExportHeader names the columns written by ExportLeaveRequests.
+var leaveRequestExportHeader = []any{
+	"ID", "Employee", "Email", "Type", "Status", "Start Date", "End Date", "Start Session", "End Session",
+	"Hours", "Working Days", "Reason", "Decided At",
+}
+
+// ExportLeaveRequests writes every request GetAllLeaveRequests would list, without paging, to
+// w as a spreadsheet. Rows are written as they are read from the database. Nothing is written
+// when the parameters are invalid; a failure after that leaves the file incomplete.
+func (us *LeaveRequest) ExportLeaveRequests(ctx context.Context, w io.Writer, format util.ExportFormat, sortBy, orderBy, search string, filter entity.LeaveRequestFilter) *models.ErrorResponse {
+	safeSortBy, safeOrderBy, errValidate := validateListing(sortBy, orderBy, filter)
+	if errValidate != nil {
+		return errValidate
+	}
+
+	rowWriter, err := util.NewRowWriter(w, format, "Leave Requests")
+	if err != nil {
+		return &models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid format parameter",
+		}
+	}
+
+	err = rowWriter.WriteRow(leaveRequestExportHeader...)
+	if err == nil {
+		err = us.leaveRequestRepo.ExportLeaveRequests(ctx, safeSortBy, safeOrderBy, search, filter, func(lr *entity.LeaveRequestExport) error {
+			return rowWriter.WriteRow(leaveRequestExportRow(lr)...)
+		})
+	}
+	if err == nil {
+		err = rowWriter.Close()
+	}
+	if err != nil {
+		return &models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to export leave requests",
+		}
+	}
+
+	return nil
+}
+
+func leaveRequestExportRow(lr *entity.LeaveRequestExport) []any {
+	var hours any = ""
+	if lr.DurationUnit == entity.DurationHour {
+		hours = lr.Hours()
+	}
+
+	decidedAt := ""
+	if lr.DecidedAt != nil {
+		decidedAt = lr.DecidedAt.Format(time.RFC3339)
+	}
+
+	return []any{
+		lr.ID, lr.FullName, lr.Email, string(lr.Type), string(lr.Status),
+		lr.StartDate.Format(time.DateOnly), lr.EndDate.Format(time.DateOnly), string(lr.StartSession), string(lr.EndSession),
+		hours, lr.WorkingDays, lr.Reason, decidedAt,
+	}
+}
+
+func (us *LeaveRequest) GetLeaveRequest(ctx context.Context, leaveRequestID int) (*dto.LeaveRequestResponse, *models.ErrorResponse) {
 	response := &dto.LeaveRequestResponse{}
 
-	leaveRequest, err := us.leaveRequestRepo.FindById(leaveRequestID)
+	leaveRequest, err := us.leaveRequestRepo.FindById(ctx, leaveRequestID)
 
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -104,166 +244,1522 @@ func (us *LeaveRequest) GetLeaveRequest(leaveRequestID int) (*dto.LeaveRequestRe
 		}
 	}
 
+	steps, err := us.approvalRepo.GetSteps(ctx, leaveRequest.ID)
+	if err != nil {
+		return nil, &models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal Server Error",
+		}
+	}
+
 	response.MapLeaveRequestResponse(leaveRequest)
+	response.MapApprovalStepsResponse(steps)
 
 	return response, nil
 }
 
-func (us *LeaveRequest) CreateLeaveRequest(createLeaveRequestRequest *dto.CreateLeaveRequestRequest, userId int) (*dto.CreateLeaveRequestResponse, *models.ErrorResponse) {
-	leaveRequestResponse := &dto.CreateLeaveRequestResponse{}
-	errCheckExist := us.OverlapApprovedLeaveExists(userId, createLeaveRequestRequest.StartDate, createLeaveRequestRequest.EndDate)
-	if errCheckExist != nil {
-		return nil, errCheckExist
+// GetLeaveRequestHistory lists the changes made to a request, oldest first. Only the owner
+// and admins may read it. The history of a deleted request is left to admins.
+func (us *LeaveRequest) GetLeaveRequestHistory(ctx context.Context, leaveRequestID, userID int) (*dto.GetLeaveRequestHistoryResponse, *models.ErrorResponse) {
+	response := &dto.GetLeaveRequestHistoryResponse{}
+
+	leaveRequest, err := us.leaveRequestRepo.FindById(ctx, leaveRequestID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return us.getDeletedLeaveRequestHistory(ctx, leaveRequestID, userID)
+		}
+		return nil, &models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal Server Error",
+		}
 	}
 
-	leaveRequest := createLeaveRequestRequest.ToLeaveRequest(userId)
+	if leaveRequest.UserId != userID {
+		user, err := us.userRepo.FindById(ctx, userID)
+		if err != nil {
+			return nil, &models.ErrorResponse{
+				Code:    http.StatusInternalServerError,
+				Message: "Internal Server Error",
+			}
+		}
+
+		if !user.Role.IsAdmin() {
+			return nil, &models.ErrorResponse{
+				Code:    http.StatusForbidden,
+				Message: "The specified leave request belongs to another user.",
+			}
+		}
+	}
+
+	events, err := us.leaveRequestRepo.GetEvents(ctx, leaveRequest.ID)
+	if err != nil {
+		return nil, &models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal Server Error",
+		}
+	}
+
+	response.MapLeaveRequestEventsResponse(events)
+
+	return response, nil
+}
+
+func (us *LeaveRequest) getDeletedLeaveRequestHistory(ctx context.Context, leaveRequestID, userID int) (*dto.GetLeaveRequestHistoryResponse, *models.ErrorResponse) {
+	response := &dto.GetLeaveRequestHistoryResponse{}
+	errNotFound := &models.ErrorResponse{
+		Code:    http.StatusNotFound,
+		Message: "Leave Request Not Found",
+	}
 
-	err := us.leaveRequestRepo.Create(leaveRequest)
+	user, err := us.userRepo.FindById(ctx, userID)
 	if err != nil {
 		return nil, &models.ErrorResponse{
 			Code:    http.StatusInternalServerError,
-			Message: "Failed to create leave Request",
+			Message: "Internal Server Error",
 		}
 	}
+	if !user.Role.IsAdmin() {
+		return nil, errNotFound
+	}
 
-	return leaveRequestResponse.FromLeaveRequest(leaveRequest), nil
+	events, err := us.leaveRequestRepo.GetEvents(ctx, leaveRequestID)
+	if err != nil {
+		return nil, &models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal Server Error",
+		}
+	}
+	if len(events) == 0 {
+		return nil, errNotFound
+	}
+
+	response.MapLeaveRequestEventsResponse(events)
+
+	return response, nil
 }
 
-func (us *LeaveRequest) Approve(leaveRequestID int) *models.ErrorResponse {
-	existingLeaveRequest, err := us.leaveRequestRepo.FindById(leaveRequestID)
+// GetLeaveRequestActions lists the transitions the user may perform on the request right
+// now: the owner's for the owner, the approver's for whoever may decide it. Anyone else gets
+// an empty list.
+func (us *LeaveRequest) GetLeaveRequestActions(ctx context.Context, leaveRequestID, userID int) (*dto.LeaveRequestActionsResponse, *models.ErrorResponse) {
+	response := &dto.LeaveRequestActionsResponse{}
+
+	leaveRequest, err := us.leaveRequestRepo.FindById(ctx, leaveRequestID)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return &models.ErrorResponse{
+			return nil, &models.ErrorResponse{
 				Code:    http.StatusNotFound,
-				Message: "Leave Request not found",
+				Message: "Leave Request Not Found",
 			}
 		}
-		return &models.ErrorResponse{
+		return nil, &models.ErrorResponse{
 			Code:    http.StatusInternalServerError,
 			Message: "Internal Server Error",
 		}
 	}
 
-	if existingLeaveRequest.Status == "draft" {
-		return &models.ErrorResponse{
-			Code:    http.StatusUnprocessableEntity,
-			Message: "Leave Request status is still draft",
+	var mayDecide *bool
+	transitions := []entity.LeaveRequestTransition{}
+	for _, rule := range entity.AvailableTransitions(leaveRequest.Status) {
+		allowed := leaveRequest.UserId == userID
+		if rule.Actor == entity.ActorApprover {
+			if mayDecide == nil {
+				decide, errDecide := us.mayDecideNow(ctx, leaveRequest, userID)
+				if errDecide != nil {
+					return nil, errDecide
+				}
+				mayDecide = &decide
+			}
+			allowed = *mayDecide
+		}
+
+		if allowed {
+			transitions = append(transitions, rule.Transition)
 		}
 	}
 
-	if existingLeaveRequest.Status == "approved" {
-		return &models.ErrorResponse{
-			Code:    http.StatusConflict,
-			Message: "Leave Request has been approved",
+	response.MapLeaveRequestActionsResponse(leaveRequest, transitions)
+
+	return response, nil
+}
+
+// mayDecideNow reports whether the user may take the decision the request is waiting for:
+// its pending approval step, or a pending cancellation, which only admins confirm.
+func (us *LeaveRequest) mayDecideNow(ctx context.Context, leaveRequest *entity.LeaveRequest, userID int) (bool, *models.ErrorResponse) {
+	kind := entity.ApproverAdmin
+	if leaveRequest.Status == entity.WaitingApproval {
+		kind = entity.ApproverManager
+		steps, err := us.approvalRepo.GetSteps(ctx, leaveRequest.ID)
+		if err != nil {
+			return false, &models.ErrorResponse{
+				Code:    http.StatusInternalServerError,
+				Message: "Internal Server Error",
+			}
+		}
+
+		pending := len(steps) == 0
+		for _, step := range steps {
+			if step.Status == entity.StepPending {
+				kind, pending = step.ApproverKind, true
+				break
+			}
+		}
+		if !pending {
+			return false, nil
 		}
 	}
 
-	errCheckExist := us.OverlapApprovedLeaveExists(existingLeaveRequest.UserId, existingLeaveRequest.StartDate, existingLeaveRequest.EndDate)
+	_, errAuthorize := us.authorizeDecision(ctx, leaveRequest, kind, userID)
+	if errAuthorize != nil {
+		if errAuthorize.Code == http.StatusForbidden {
+			return false, nil
+		}
+		return false, errAuthorize
+	}
+
+	return true, nil
+}
+
+func (us *LeaveRequest) CreateLeaveRequest(ctx context.Context, createLeaveRequestRequest *dto.CreateLeaveRequestRequest, userId int) (*dto.CreateLeaveRequestResponse, *models.ErrorResponse) {
+	leaveRequestResponse := &dto.CreateLeaveRequestResponse{}
+	leaveRequest := createLeaveRequestRequest.ToLeaveRequest(userId)
+
+	workingDays, errDays := us.countWorkingDays(ctx, leaveRequest)
+	if errDays != nil {
+		return nil, errDays
+	}
+	leaveRequest.WorkingDays = workingDays
+
+	rules, errType := us.checkLeaveType(ctx, leaveRequest, leaveRequest.Status == entity.WaitingApproval)
+	if errType != nil {
+		return nil, errType
+	}
+
+	errCheckExist := us.OverlapApprovedLeaveExists(ctx, userId, leaveRequest.PeriodStart, leaveRequest.PeriodEnd)
 	if errCheckExist != nil {
-		return errCheckExist
+		return nil, errCheckExist
 	}
 
-	err = us.leaveRequestRepo.Approve(existingLeaveRequest.ID)
+	var autoRule *entity.AutoApprovalRule
+	if leaveRequest.Status == entity.WaitingApproval {
+		errAttachment := us.checkAttachments(ctx, leaveRequest, rules.Type)
+		if errAttachment != nil {
+			return nil, errAttachment
+		}
 
-	if err != nil {
-		return &models.ErrorResponse{
-			Code:    http.StatusInternalServerError,
-			Message: "Failed to Approve Leave Request",
+		var errRule *models.ErrorResponse
+		autoRule, errRule = us.findAutoApprovalRule(ctx, leaveRequest, rules)
+		if errRule != nil {
+			return nil, errRule
 		}
 	}
 
-	return nil
-}
+	errCreate := us.inTx(ctx, func(tx *LeaveRequest) *models.ErrorResponse {
+		errBalance := tx.checkBalance(ctx, leaveRequest, rules)
+		if errBalance != nil {
+			return errBalance
+		}
 
-func (us *LeaveRequest) Reject(leaveRequestID int) *models.ErrorResponse {
-	existingLeaveRequest, err := us.leaveRequestRepo.FindById(leaveRequestID)
-	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
+		err := tx.leaveRequestRepo.Create(ctx, leaveRequest, userId)
+		if err != nil {
 			return &models.ErrorResponse{
-				Code:    http.StatusNotFound,
-				Message: "Leave Request not found",
+				Code:    http.StatusInternalServerError,
+				Message: "Failed to create leave Request",
 			}
 		}
-		return &models.ErrorResponse{
-			Code:    http.StatusInternalServerError,
-			Message: "Internal Server Error",
+
+		if leaveRequest.Status != entity.WaitingApproval {
+			return nil
+		}
+
+		if errStart := tx.startApproval(ctx, leaveRequest); errStart != nil {
+			return errStart
 		}
+
+		return tx.autoApprove(ctx, leaveRequest, autoRule)
+	})
+	if errCreate != nil {
+		return nil, errCreate
 	}
 
-	if existingLeaveRequest.Status == "draft" {
-		return &models.ErrorResponse{
-			Code:    http.StatusUnprocessableEntity,
-			Message: "Leave Request status is still draft",
+	return leaveRequestResponse.FromLeaveRequest(leaveRequest), nil
+}
+
+// Approve records the approver's decision on the step the request is waiting for. The
+// request itself only becomes approved, and its days are only consumed, once the last step
+// of its approval chain approves. The returned status is the one the request ended up in.
+// The optional comment is kept on the step and, for the last step, on the request.
+func (us *LeaveRequest) Approve(ctx context.Context, leaveRequestID, approverID, version int, comment string) (entity.LeaveRequestStatus, *models.ErrorResponse) {
+	existingLeaveRequest, rule, errFind := us.findTransition(ctx, leaveRequestID, version, entity.TransitionApprove, "")
+	if errFind != nil {
+		return "", errFind
+	}
+
+	step, isLastStep, errStep := us.findPendingStep(ctx, existingLeaveRequest)
+	if errStep != nil {
+		return "", errStep
+	}
+
+	onBehalfOf, errAuthorize := us.authorizeTransition(ctx, existingLeaveRequest, rule, approverID, step.ApproverKind)
+	if errAuthorize != nil {
+		return "", errAuthorize
+	}
+
+	var rules *entity.LeaveRules
+	if isLastStep {
+		errCheckExist := us.OverlapApprovedLeaveExists(ctx, existingLeaveRequest.UserId, existingLeaveRequest.PeriodStart, existingLeaveRequest.PeriodEnd)
+		if errCheckExist != nil {
+			return "", errCheckExist
+		}
+
+		var errType *models.ErrorResponse
+		rules, errType = us.findLeaveRules(ctx, existingLeaveRequest)
+		if errType != nil {
+			return "", errType
 		}
 	}
 
-	err = us.leaveRequestRepo.Reject(existingLeaveRequest.ID)
+	comment = strings.TrimSpace(comment)
+	errApprove := us.inTx(ctx, func(tx *LeaveRequest) *models.ErrorResponse {
+		if isLastStep {
+			errBalance := tx.checkBalance(ctx, existingLeaveRequest, rules)
+			if errBalance != nil {
+				return errBalance
+			}
+		}
 
-	if err != nil {
-		return &models.ErrorResponse{
-			Code:    http.StatusInternalServerError,
-			Message: "Failed to Reject Leave Request",
+		storedStep, errStep := tx.storePendingStep(ctx, existingLeaveRequest, step)
+		if errStep != nil {
+			return errStep
+		}
+
+		errDecide := tx.decideStep(ctx, storedStep, approverID, onBehalfOf, entity.StepApproved, comment)
+		if errDecide != nil || !isLastStep {
+			return errDecide
+		}
+
+		approved, err := tx.leaveRequestRepo.Approve(ctx, existingLeaveRequest, approverID, comment)
+		if isApprovedOverlapViolation(err) {
+			return errApprovedLeaveOverlap()
+		}
+		if err != nil {
+			return &models.ErrorResponse{
+				Code:    http.StatusInternalServerError,
+				Message: "Failed to Approve Leave Request",
+			}
+		}
+		if !approved {
+			return errLeaveRequestChanged()
 		}
+
+		return tx.applyEffects(ctx, rule, existingLeaveRequest, existingLeaveRequest)
+	})
+	if errApprove != nil {
+		return "", errApprove
 	}
 
-	return nil
+	if !isLastStep {
+		return entity.WaitingApproval, nil
+	}
+
+	return rule.To, nil
 }
 
-func (lr *LeaveRequest) OverlapApprovedLeaveExists(userId int, startDate, endDate time.Time) *models.ErrorResponse {
-	isOverlapping, err := lr.leaveRequestRepo.OverlapApprovedLeaveExists(userId, startDate, endDate)
-	if err != nil {
+// Reject records the approver's decision on the step the request is waiting for. Any
+// rejection ends the chain: the remaining steps are skipped and the request is rejected.
+// The reason is shown to the employee and cannot be blank.
+func (us *LeaveRequest) Reject(ctx context.Context, leaveRequestID, approverID, version int, reason string) *models.ErrorResponse {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
 		return &models.ErrorResponse{
-			Code:    http.StatusInternalServerError,
-			Message: "Internal Server Error",
+			Code:    http.StatusBadRequest,
+			Message: "A rejection reason is required",
 		}
 	}
 
-	if isOverlapping {
-		return &models.ErrorResponse{
-			Code:    http.StatusInternalServerError,
-			Message: "The requested leave dates overlap with an already approved leave request.",
-		}
+	existingLeaveRequest, rule, errFind := us.findTransition(ctx, leaveRequestID, version, entity.TransitionReject, "")
+	if errFind != nil {
+		return errFind
 	}
-	return nil
-}
 
-func (us *LeaveRequest) Submit(leaveRequestID, userId int) *models.ErrorResponse {
-	existingLeaveRequest, err := us.leaveRequestRepo.FindById(leaveRequestID)
-	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
+	step, _, errStep := us.findPendingStep(ctx, existingLeaveRequest)
+	if errStep != nil {
+		return errStep
+	}
+
+	onBehalfOf, errAuthorize := us.authorizeTransition(ctx, existingLeaveRequest, rule, approverID, step.ApproverKind)
+	if errAuthorize != nil {
+		return errAuthorize
+	}
+
+	return us.inTx(ctx, func(tx *LeaveRequest) *models.ErrorResponse {
+		storedStep, errStep := tx.storePendingStep(ctx, existingLeaveRequest, step)
+		if errStep != nil {
+			return errStep
+		}
+
+		errDecide := tx.decideStep(ctx, storedStep, approverID, onBehalfOf, entity.StepRejected, reason)
+		if errDecide != nil {
+			return errDecide
+		}
+
+		rejected, err := tx.leaveRequestRepo.Reject(ctx, existingLeaveRequest, approverID, reason)
+		if err != nil {
 			return &models.ErrorResponse{
-				Code:    http.StatusNotFound,
-				Message: "Leave Request not found",
+				Code:    http.StatusInternalServerError,
+				Message: "Failed to Reject Leave Request",
 			}
 		}
-		return &models.ErrorResponse{
+		if !rejected {
+			return errLeaveRequestChanged()
+		}
+
+		return tx.applyEffects(ctx, rule, existingLeaveRequest, existingLeaveRequest)
+	})
+}
+
+// BulkDecide approves or rejects every request of the list in turn, exactly as Approve and
+// Reject would without an If-Match version. Each request is decided in its own transaction,
+// so one that fails is reported in its result and does not stop the others. Repeated ids
+// are decided once.
+func (us *LeaveRequest) BulkDecide(ctx context.Context, approverID int, bulkDecisionRequest *dto.BulkDecisionRequest) (*dto.BulkDecisionResponse, *models.ErrorResponse) {
+	reject := bulkDecisionRequest.Decision == "reject"
+	if reject && strings.TrimSpace(bulkDecisionRequest.Comment) == "" {
+		return nil, &models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "A rejection reason is required",
+		}
+	}
+
+	response := &dto.BulkDecisionResponse{Results: []*dto.BulkDecisionResult{}}
+	decided := make(map[int]bool)
+	for _, leaveRequestID := range bulkDecisionRequest.Ids {
+		if decided[leaveRequestID] {
+			continue
+		}
+		decided[leaveRequestID] = true
+
+		var status entity.LeaveRequestStatus
+		var errDecide *models.ErrorResponse
+		if reject {
+			status, errDecide = entity.Rejected, us.Reject(ctx, leaveRequestID, approverID, 0, bulkDecisionRequest.Comment)
+		} else {
+			status, errDecide = us.Approve(ctx, leaveRequestID, approverID, 0, bulkDecisionRequest.Comment)
+		}
+
+		result := &dto.BulkDecisionResult{ID: leaveRequestID, Result: bulkDecisionResult(errDecide)}
+		if errDecide != nil {
+			result.Message = errDecide.Message
+			response.Failed++
+		} else {
+			result.Status = string(status)
+			response.Succeeded++
+		}
+		response.Results = append(response.Results, result)
+	}
+
+	return response, nil
+}
+
+// bulkDecisionResult classifies the outcome of one decision of a bulk decision.
+func bulkDecisionResult(errDecide *models.ErrorResponse) string {
+	switch {
+	case errDecide == nil:
+		return dto.BulkDecisionOK
+	case errDecide.Code == http.StatusNotFound:
+		return dto.BulkDecisionNotFound
+	case errDecide.Code == http.StatusForbidden:
+		return dto.BulkDecisionForbidden
+	case errDecide.Reason == reasonApprovedLeaveOverlap:
+		return dto.BulkDecisionOverlap
+	case errDecide.Code == http.StatusConflict:
+		return dto.BulkDecisionConflict
+	case errDecide.Code >= http.StatusInternalServerError:
+		return dto.BulkDecisionError
+	default:
+		return dto.BulkDecisionInvalid
+	}
+}
+
+// findPendingStep returns the first step of the request's approval chain that has not been
+// decided yet, and whether that step is the last one. Requests submitted before approval
+// chains existed have no steps; they get the first step of the chain that applies to them,
+// unsaved, and storePendingStep creates the chain in the transaction of the decision.
+func (us *LeaveRequest) findPendingStep(ctx context.Context, leaveRequest *entity.LeaveRequest) (*entity.ApprovalStep, bool, *models.ErrorResponse) {
+	steps, err := us.approvalRepo.GetSteps(ctx, leaveRequest.ID)
+	if err != nil {
+		return nil, false, &models.ErrorResponse{
 			Code:    http.StatusInternalServerError,
 			Message: "Internal Server Error",
 		}
 	}
 
-	if existingLeaveRequest.UserId != userId {
-		return &models.ErrorResponse{
-			Code:    http.StatusForbidden,
-			Message: "The specified leave request belongs to another user.",
+	if len(steps) == 0 {
+		approverKinds, errChain := us.findApprovalChain(ctx, leaveRequest)
+		if errChain != nil {
+			return nil, false, errChain
+		}
+		if len(approverKinds) > 0 {
+			step := &entity.ApprovalStep{LeaveRequestId: leaveRequest.ID, StepOrder: 1, ApproverKind: approverKinds[0], Status: entity.StepPending}
+			return step, len(approverKinds) == 1, nil
 		}
 	}
 
-	if existingLeaveRequest.Status != "draft" {
-		return &models.ErrorResponse{
-			Code:    http.StatusUnprocessableEntity,
-			Message: "Only Draft leave Request can be submitted",
+	for i, step := range steps {
+		if step.Status == entity.StepPending {
+			return step, i == len(steps)-1, nil
 		}
 	}
 
-	err = us.leaveRequestRepo.Submit(existingLeaveRequest.ID)
+	return nil, false, &models.ErrorResponse{
+		Code:    http.StatusConflict,
+		Message: "Leave Request has no pending approval step",
+	}
+}
+
+// storePendingStep returns step as stored, creating the approval chain findPendingStep planned
+// it from when the request had none.
+func (us *LeaveRequest) storePendingStep(ctx context.Context, leaveRequest *entity.LeaveRequest, step *entity.ApprovalStep) (*entity.ApprovalStep, *models.ErrorResponse) {
+	if step.ID != 0 {
+		return step, nil
+	}
+
+	if errStart := us.startApprovalChain(ctx, leaveRequest); errStart != nil {
+		return nil, errStart
+	}
+
+	storedStep, _, errStep := us.findPendingStep(ctx, leaveRequest)
+	if errStep != nil {
+		return nil, errStep
+	}
+	if storedStep.ID == 0 || storedStep.ApproverKind != step.ApproverKind {
+		return nil, errLeaveRequestChanged()
+	}
+
+	return storedStep, nil
+}
 
+func (us *LeaveRequest) decideStep(ctx context.Context, step *entity.ApprovalStep, approverID int, onBehalfOf *int, status entity.ApprovalStepStatus, comment string) *models.ErrorResponse {
+	decided, err := us.approvalRepo.DecideStep(ctx, step.ID, approverID, onBehalfOf, status, comment)
 	if err != nil {
 		return &models.ErrorResponse{
 			Code:    http.StatusInternalServerError,
-			Message: "Failed to Submit Leave Request",
+			Message: "Failed to record approval decision",
 		}
 	}
 
-	return nil
+	if !decided {
+		return &models.ErrorResponse{
+			Code:    http.StatusConflict,
+			Message: "This approval step has already been decided",
+		}
+	}
+
+	return nil
+}
+
+func (lr *LeaveRequest) OverlapApprovedLeaveExists(ctx context.Context, userId int, periodStart, periodEnd time.Time) *models.ErrorResponse {
+	isOverlapping, err := lr.leaveRequestRepo.OverlapApprovedLeaveExists(ctx, userId, periodStart, periodEnd)
+	if err != nil {
+		return &models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal Server Error",
+		}
+	}
+
+	if isOverlapping {
+		return errApprovedLeaveOverlap()
+	}
+	return nil
+}
+
+// reasonApprovedLeaveOverlap marks the conflict of a request clashing with approved leave.
+const reasonApprovedLeaveOverlap = "approved_leave_overlap"
+
+func errApprovedLeaveOverlap() *models.ErrorResponse {
+	return &models.ErrorResponse{
+		Code:    http.StatusConflict,
+		Message: "The requested leave dates overlap with an already approved leave request.",
+		Reason:  reasonApprovedLeaveOverlap,
+	}
+}
+
+// isApprovedOverlapViolation reports whether the database refused a write because it would
+// leave two approved requests of the same user overlapping. The check above runs before the
+// write, so this is what catches two overlapping requests approved at the same time.
+func isApprovedOverlapViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23P01"
+}
+
+// Submit puts a draft up for approval. When an auto-approval rule covers it, the request is
+// approved straight away and the returned status is approved.
+func (us *LeaveRequest) Submit(ctx context.Context, leaveRequestID, userId, version int) (entity.LeaveRequestStatus, *models.ErrorResponse) {
+	existingLeaveRequest, rule, errFind := us.findTransition(ctx, leaveRequestID, version, entity.TransitionSubmit, "")
+	if errFind != nil {
+		return "", errFind
+	}
+
+	if _, errAuthorize := us.authorizeTransition(ctx, existingLeaveRequest, rule, userId, ""); errAuthorize != nil {
+		return "", errAuthorize
+	}
+
+	rules, errType := us.checkLeaveType(ctx, existingLeaveRequest, true)
+	if errType != nil {
+		return "", errType
+	}
+
+	errAttachment := us.checkAttachments(ctx, existingLeaveRequest, rules.Type)
+	if errAttachment != nil {
+		return "", errAttachment
+	}
+
+	autoRule, errRule := us.findAutoApprovalRule(ctx, existingLeaveRequest, rules)
+	if errRule != nil {
+		return "", errRule
+	}
+
+	errSubmit := us.inTx(ctx, func(tx *LeaveRequest) *models.ErrorResponse {
+		errBalance := tx.checkBalance(ctx, existingLeaveRequest, rules)
+		if errBalance != nil {
+			return errBalance
+		}
+
+		submitted, err := tx.leaveRequestRepo.Submit(ctx, existingLeaveRequest, userId)
+		if err != nil {
+			return &models.ErrorResponse{
+				Code:    http.StatusInternalServerError,
+				Message: "Failed to Submit Leave Request",
+			}
+		}
+		if !submitted {
+			return errLeaveRequestChanged()
+		}
+
+		if errEffects := tx.applyEffects(ctx, rule, existingLeaveRequest, existingLeaveRequest); errEffects != nil {
+			return errEffects
+		}
+
+		existingLeaveRequest.Status = rule.To
+		return tx.autoApprove(ctx, existingLeaveRequest, autoRule)
+	})
+	if errSubmit != nil {
+		return "", errSubmit
+	}
+
+	return existingLeaveRequest.Status, nil
+}
+
+// UpdateLeaveRequest replaces the dates, type and reason of a request its owner has not had
+// decided yet. Editing a request that is waiting for approval resets it: its reservation is
+// released and it is reserved again only if the new status asks for approval.
+func (us *LeaveRequest) UpdateLeaveRequest(ctx context.Context, leaveRequestID int, updateLeaveRequestRequest *dto.CreateLeaveRequestRequest, userId, version int) (*dto.LeaveRequestResponse, *models.ErrorResponse) {
+	response := &dto.LeaveRequestResponse{}
+
+	leaveRequest := updateLeaveRequestRequest.ToLeaveRequest(userId)
+
+	existingLeaveRequest, rule, errFind := us.findTransition(ctx, leaveRequestID, version, entity.TransitionEdit, leaveRequest.Status)
+	if errFind != nil {
+		return nil, errFind
+	}
+
+	if _, errAuthorize := us.authorizeTransition(ctx, existingLeaveRequest, rule, userId, ""); errAuthorize != nil {
+		return nil, errAuthorize
+	}
+
+	leaveRequest.ID = existingLeaveRequest.ID
+
+	workingDays, errDays := us.countWorkingDays(ctx, leaveRequest)
+	if errDays != nil {
+		return nil, errDays
+	}
+	leaveRequest.WorkingDays = workingDays
+
+	rules, errType := us.checkLeaveType(ctx, leaveRequest, leaveRequest.Status == entity.WaitingApproval)
+	if errType != nil {
+		return nil, errType
+	}
+
+	errCheckExist := us.OverlapApprovedLeaveExists(ctx, userId, leaveRequest.PeriodStart, leaveRequest.PeriodEnd)
+	if errCheckExist != nil {
+		return nil, errCheckExist
+	}
+
+	var autoRule *entity.AutoApprovalRule
+	if leaveRequest.Status == entity.WaitingApproval {
+		errAttachment := us.checkAttachments(ctx, leaveRequest, rules.Type)
+		if errAttachment != nil {
+			return nil, errAttachment
+		}
+
+		var errRule *models.ErrorResponse
+		autoRule, errRule = us.findAutoApprovalRule(ctx, leaveRequest, rules)
+		if errRule != nil {
+			return nil, errRule
+		}
+	}
+
+	errUpdate := us.inTx(ctx, func(tx *LeaveRequest) *models.ErrorResponse {
+		errBalance := tx.checkBalance(ctx, leaveRequest, rules)
+		if errBalance != nil {
+			return errBalance
+		}
+
+		updated, err := tx.leaveRequestRepo.Update(ctx, existingLeaveRequest, leaveRequest, userId)
+		if err != nil {
+			return &models.ErrorResponse{
+				Code:    http.StatusInternalServerError,
+				Message: "Failed to update leave Request",
+			}
+		}
+		if !updated {
+			return errLeaveRequestChanged()
+		}
+
+		if errEffects := tx.applyEffects(ctx, rule, existingLeaveRequest, leaveRequest); errEffects != nil {
+			return errEffects
+		}
+
+		return tx.autoApprove(ctx, leaveRequest, autoRule)
+	})
+	if errUpdate != nil {
+		return nil, errUpdate
+	}
+
+	response.MapLeaveRequestResponse(leaveRequest)
+
+	return response, nil
+}
+
+// DeleteLeaveRequest removes a request its owner has not had decided yet. Its reservation,
+// approval steps and attachments go with it in one transaction; its history is kept and ends
+// with the deletion. Stored attachment files are only removed once that has committed.
+func (us *LeaveRequest) DeleteLeaveRequest(ctx context.Context, leaveRequestID, userId, version int) *models.ErrorResponse {
+	existingLeaveRequest, rule, errFind := us.findTransition(ctx, leaveRequestID, version, entity.TransitionDelete, "")
+	if errFind != nil {
+		return errFind
+	}
+
+	if _, errAuthorize := us.authorizeTransition(ctx, existingLeaveRequest, rule, userId, ""); errAuthorize != nil {
+		return errAuthorize
+	}
+
+	attachments, err := us.attachmentRepo.GetByLeaveRequest(ctx, existingLeaveRequest.ID)
+	if err != nil {
+		return &models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal Server Error",
+		}
+	}
+
+	errDelete := us.inTx(ctx, func(tx *LeaveRequest) *models.ErrorResponse {
+		// The effects run first, while the steps and ledger rows still refer to the request.
+		if errEffects := tx.applyEffects(ctx, rule, existingLeaveRequest, existingLeaveRequest); errEffects != nil {
+			return errEffects
+		}
+
+		deleted, err := tx.leaveRequestRepo.Delete(ctx, existingLeaveRequest, userId)
+		if err != nil {
+			return &models.ErrorResponse{
+				Code:    http.StatusInternalServerError,
+				Message: "Failed to delete leave Request",
+			}
+		}
+		if !deleted {
+			return errLeaveRequestChanged()
+		}
+
+		return nil
+	})
+	if errDelete != nil {
+		return errDelete
+	}
+
+	us.deleteStoredAttachments(ctx, attachments)
+
+	return nil
+}
+
+// findTransition loads a request and looks up the state machine rule that lets the
+// transition move it to status to, or to wherever the transition leads when to is empty.
+// It fails with 412 when the caller read an older version, and with 422 when the current
+// status does not allow the transition.
+func (us *LeaveRequest) findTransition(ctx context.Context, leaveRequestID, version int, transition entity.LeaveRequestTransition, to entity.LeaveRequestStatus) (*entity.LeaveRequest, *entity.TransitionRule, *models.ErrorResponse) {
+	existingLeaveRequest, err := us.leaveRequestRepo.FindById(ctx, leaveRequestID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil, &models.ErrorResponse{
+				Code:    http.StatusNotFound,
+				Message: "Leave Request not found",
+			}
+		}
+		return nil, nil, &models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal Server Error",
+		}
+	}
+
+	if errVersion := checkVersion(existingLeaveRequest, version); errVersion != nil {
+		return nil, nil, errVersion
+	}
+
+	rule, ok := entity.FindTransitionRule(transition, existingLeaveRequest.Status, to)
+	if !ok {
+		return nil, nil, &models.ErrorResponse{
+			Code:    http.StatusUnprocessableEntity,
+			Message: fmt.Sprintf("A %s leave Request does not allow %s", existingLeaveRequest.Status, strings.ReplaceAll(string(transition), "_", " ")),
+		}
+	}
+
+	return existingLeaveRequest, rule, nil
+}
+
+// authorizeTransition checks the guard of a rule. Owner transitions are only open to the
+// employee who made the request; approver transitions to whoever may decide a step of the
+// given kind, see authorizeDecision.
+func (us *LeaveRequest) authorizeTransition(ctx context.Context, leaveRequest *entity.LeaveRequest, rule *entity.TransitionRule, userID int, kind entity.ApproverKind) (*int, *models.ErrorResponse) {
+	if rule.Actor == entity.ActorApprover {
+		return us.authorizeDecision(ctx, leaveRequest, kind, userID)
+	}
+
+	if leaveRequest.UserId != userID {
+		return nil, &models.ErrorResponse{
+			Code:    http.StatusForbidden,
+			Message: "The specified leave request belongs to another user.",
+		}
+	}
+
+	return nil, nil
+}
+
+// applyEffects runs the side effects of a transition once the new status is stored. previous
+// is the request as it was before the transition and current as it is now; only edits tell
+// them apart.
+func (us *LeaveRequest) applyEffects(ctx context.Context, rule *entity.TransitionRule, previous, current *entity.LeaveRequest) *models.ErrorResponse {
+	for _, effect := range rule.Effects {
+		var errEffect *models.ErrorResponse
+		switch effect {
+		case entity.EffectResetApproval:
+			errEffect = us.resetApproval(ctx, previous)
+		case entity.EffectStartApproval:
+			errEffect = us.startApproval(ctx, current)
+		case entity.EffectEndApproval:
+			errEffect = us.endApproval(ctx, current)
+		case entity.EffectConsumeBalance:
+			errEffect = us.consumeBalance(ctx, current)
+		case entity.EffectRefundBalance:
+			errEffect = us.refundBalance(ctx, current)
+		}
+		if errEffect != nil {
+			return errEffect
+		}
+	}
+
+	return nil
+}
+
+// Cancel withdraws a request on behalf of its owner. Drafts and requests still waiting for
+// approval are cancelled straight away; approved leave needs an admin to confirm the
+// cancellation first. The returned status is the one the request ended up in.
+func (us *LeaveRequest) Cancel(ctx context.Context, leaveRequestID, userId, version int) (entity.LeaveRequestStatus, *models.ErrorResponse) {
+	existingLeaveRequest, rule, errFind := us.findTransition(ctx, leaveRequestID, version, entity.TransitionCancel, "")
+	if errFind != nil {
+		return "", errFind
+	}
+
+	if _, errAuthorize := us.authorizeTransition(ctx, existingLeaveRequest, rule, userId, ""); errAuthorize != nil {
+		return "", errAuthorize
+	}
+
+	errCancel := us.inTx(ctx, func(tx *LeaveRequest) *models.ErrorResponse {
+		cancel := tx.leaveRequestRepo.Cancel
+		if rule.To == entity.CancellationRequested {
+			cancel = tx.leaveRequestRepo.RequestCancellation
+		}
+		if errCancel := tx.checkCancelled(cancel(ctx, existingLeaveRequest, userId)); errCancel != nil {
+			return errCancel
+		}
+
+		return tx.applyEffects(ctx, rule, existingLeaveRequest, existingLeaveRequest)
+	})
+	if errCancel != nil {
+		return "", errCancel
+	}
+
+	return rule.To, nil
+}
+
+// ApproveCancellation confirms the cancellation of approved leave and gives the consumed
+// days back to the ledger. Only admins confirm cancellations.
+func (us *LeaveRequest) ApproveCancellation(ctx context.Context, leaveRequestID, approverID, version int) *models.ErrorResponse {
+	existingLeaveRequest, rule, errFind := us.findTransition(ctx, leaveRequestID, version, entity.TransitionApproveCancellation, "")
+	if errFind != nil {
+		return errFind
+	}
+
+	_, errAuthorize := us.authorizeTransition(ctx, existingLeaveRequest, rule, approverID, entity.ApproverAdmin)
+	if errAuthorize != nil {
+		return errAuthorize
+	}
+
+	return us.inTx(ctx, func(tx *LeaveRequest) *models.ErrorResponse {
+		cancelled, err := tx.leaveRequestRepo.Cancel(ctx, existingLeaveRequest, approverID)
+		if err != nil {
+			return &models.ErrorResponse{
+				Code:    http.StatusInternalServerError,
+				Message: "Failed to Approve Leave Request Cancellation",
+			}
+		}
+		if !cancelled {
+			return errLeaveRequestChanged()
+		}
+
+		return tx.applyEffects(ctx, rule, existingLeaveRequest, existingLeaveRequest)
+	})
+}
+
+// RejectCancellation keeps the leave approved. Like the confirmation, it is left to admins.
+func (us *LeaveRequest) RejectCancellation(ctx context.Context, leaveRequestID, approverID, version int) *models.ErrorResponse {
+	existingLeaveRequest, rule, errFind := us.findTransition(ctx, leaveRequestID, version, entity.TransitionRejectCancellation, "")
+	if errFind != nil {
+		return errFind
+	}
+
+	_, errAuthorize := us.authorizeTransition(ctx, existingLeaveRequest, rule, approverID, entity.ApproverAdmin)
+	if errAuthorize != nil {
+		return errAuthorize
+	}
+
+	kept, err := us.leaveRequestRepo.RejectCancellation(ctx, existingLeaveRequest, approverID)
+	if err != nil {
+		return &models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to Reject Leave Request Cancellation",
+		}
+	}
+	if !kept {
+		return errLeaveRequestChanged()
+	}
+
+	return nil
+}
+
+// authorizeDecision checks that approverID may decide a step of the given kind, either in
+// their own right or as the delegate of someone who may. For a delegated decision it returns
+// the id of the user the approver acts for. Nobody decides on their own leave.
+func (us *LeaveRequest) authorizeDecision(ctx context.Context, leaveRequest *entity.LeaveRequest, kind entity.ApproverKind, approverID int) (*int, *models.ErrorResponse) {
+	if leaveRequest.UserId == approverID {
+		return nil, &models.ErrorResponse{
+			Code:    http.StatusForbidden,
+			Message: "You cannot decide on your own leave request.",
+		}
+	}
+
+	approver, err := us.userRepo.FindById(ctx, approverID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, &models.ErrorResponse{
+				Code:    http.StatusForbidden,
+				Message: "Approver not found",
+			}
+		}
+		return nil, &models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal Server Error",
+		}
+	}
+
+	allowed, errCheck := us.mayDecide(ctx, leaveRequest, kind, approver)
+	if errCheck != nil {
+		return nil, errCheck
+	}
+	if allowed {
+		return nil, nil
+	}
+
+	delegations, err := us.delegationRepo.GetActiveForDelegate(ctx, approverID, time.Now())
+	if err != nil {
+		return nil, &models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal Server Error",
+		}
+	}
+
+	for _, delegation := range delegations {
+		if delegation.DelegatorId == leaveRequest.UserId {
+			continue
+		}
+
+		delegator, err := us.userRepo.FindById(ctx, delegation.DelegatorId)
+		if err != nil {
+			return nil, &models.ErrorResponse{
+				Code:    http.StatusInternalServerError,
+				Message: "Internal Server Error",
+			}
+		}
+
+		allowed, errCheck = us.mayDecide(ctx, leaveRequest, kind, delegator)
+		if errCheck != nil {
+			return nil, errCheck
+		}
+		if allowed {
+			return &delegator.ID, nil
+		}
+	}
+
+	if kind == entity.ApproverAdmin {
+		return nil, &models.ErrorResponse{
+			Code:    http.StatusForbidden,
+			Message: "This approval step must be decided by an admin.",
+		}
+	}
+
+	return nil, &models.ErrorResponse{
+		Code:    http.StatusForbidden,
+		Message: "Only a manager of the employee can decide on this leave request.",
+	}
+}
+
+// mayDecide reports whether user holds the authority for a step of the given kind. Manager
+// steps belong to the managers above the employee in the reporting line, with admins
+// standing in for employees without a manager; admin steps belong to admins. Superadmins
+// may always step in.
+func (us *LeaveRequest) mayDecide(ctx context.Context, leaveRequest *entity.LeaveRequest, kind entity.ApproverKind, user *entity.User) (bool, *models.ErrorResponse) {
+	if user.Role == entity.RoleSuperAdmin {
+		return true, nil
+	}
+
+	if kind == entity.ApproverAdmin {
+		return user.Role.IsAdmin(), nil
+	}
+
+	isManager, err := us.userRepo.IsManagerOf(ctx, user.ID, leaveRequest.UserId)
+	if err != nil {
+		return false, &models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal Server Error",
+		}
+	}
+	if isManager || !user.Role.IsAdmin() {
+		return isManager, nil
+	}
+
+	employee, err := us.userRepo.FindById(ctx, leaveRequest.UserId)
+	if err != nil {
+		return false, &models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal Server Error",
+		}
+	}
+
+	return employee.ManagerId == nil, nil
+}
+
+// checkVersion fails with 412 when the caller last read an older version of the request than
+// the one just loaded. A zero version skips the check.
+func checkVersion(leaveRequest *entity.LeaveRequest, version int) *models.ErrorResponse {
+	if version == 0 || version == leaveRequest.Version {
+		return nil
+	}
+
+	return &models.ErrorResponse{
+		Code:    http.StatusPreconditionFailed,
+		Message: "Leave Request has changed since it was last read",
+	}
+}
+
+// checkCancelled turns the result of cancelling, or asking to cancel, a request into an
+// error response.
+func (us *LeaveRequest) checkCancelled(changed bool, err error) *models.ErrorResponse {
+	if err != nil {
+		return &models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to Cancel Leave Request",
+		}
+	}
+	if !changed {
+		return errLeaveRequestChanged()
+	}
+
+	return nil
+}
+
+// errLeaveRequestChanged reports a conditional update that lost the race against another change.
+func errLeaveRequestChanged() *models.ErrorResponse {
+	return &models.ErrorResponse{
+		Code:    http.StatusConflict,
+		Message: "Leave Request was changed by someone else, reload it and try again",
+	}
+}
+
+// startApproval puts a request that has just entered waiting_approval on hold: its days
+// are reserved and the steps of its approval chain are recorded.
+func (us *LeaveRequest) startApproval(ctx context.Context, leaveRequest *entity.LeaveRequest) *models.ErrorResponse {
+	if errBalance := us.reserveBalance(ctx, leaveRequest); errBalance != nil {
+		return errBalance
+	}
+
+	return us.startApprovalChain(ctx, leaveRequest)
+}
+
+// endApproval releases a request that left waiting_approval without being approved and
+// skips the steps nobody has to decide any more.
+func (us *LeaveRequest) endApproval(ctx context.Context, leaveRequest *entity.LeaveRequest) *models.ErrorResponse {
+	if err := us.approvalRepo.SkipPendingSteps(ctx, leaveRequest.ID); err != nil {
+		return &models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to close approval chain",
+		}
+	}
+
+	return us.releaseBalance(ctx, leaveRequest)
+}
+
+// resetApproval undoes startApproval for a request that is edited while it waits, so it
+// starts over with a fresh chain.
+func (us *LeaveRequest) resetApproval(ctx context.Context, leaveRequest *entity.LeaveRequest) *models.ErrorResponse {
+	if err := us.approvalRepo.DeleteSteps(ctx, leaveRequest.ID); err != nil {
+		return &models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to reset approval chain",
+		}
+	}
+
+	return us.releaseBalance(ctx, leaveRequest)
+}
+
+// findApprovalChain returns who has to approve the request, in order.
+func (us *LeaveRequest) findApprovalChain(ctx context.Context, leaveRequest *entity.LeaveRequest) ([]entity.ApproverKind, *models.ErrorResponse) {
+	chain, err := us.approvalRepo.FindChainFor(ctx, leaveRequest.Type, leaveRequest.WorkingDays)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, &models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal Server Error",
+		}
+	}
+	if chain == nil {
+		return entity.DefaultApprovalSteps, nil
+	}
+
+	return chain.Steps, nil
+}
+
+func (us *LeaveRequest) startApprovalChain(ctx context.Context, leaveRequest *entity.LeaveRequest) *models.ErrorResponse {
+	steps, errChain := us.findApprovalChain(ctx, leaveRequest)
+	if errChain != nil {
+		return errChain
+	}
+
+	err := us.approvalRepo.CreateSteps(ctx, leaveRequest.ID, steps)
+	if err != nil {
+		return &models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to start approval chain",
+		}
+	}
+
+	return nil
+}
+
+// findAutoApprovalRule returns the first active auto-approval rule whose conditions the
+// request, which is about to be submitted, meets. Only the facts some rule asks about are
+// looked up. A request clashing with approved leave is left to an approver.
+func (us *LeaveRequest) findAutoApprovalRule(ctx context.Context, leaveRequest *entity.LeaveRequest, rules *entity.LeaveRules) (*entity.AutoApprovalRule, *models.ErrorResponse) {
+	autoRules, err := us.autoApprovalRepo.GetActive(ctx)
+	if err != nil {
+		return nil, &models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal Server Error",
+		}
+	}
+	if len(autoRules) == 0 {
+		return nil, nil
+	}
+
+	facts, errFacts := us.autoApprovalFacts(ctx, leaveRequest, rules, autoRules)
+	if errFacts != nil {
+		return nil, errFacts
+	}
+
+	for _, autoRule := range autoRules {
+		if !autoRule.Matches(facts) {
+			continue
+		}
+
+		overlapping, err := us.leaveRequestRepo.OverlapApprovedLeaveExists(ctx, leaveRequest.UserId, leaveRequest.PeriodStart, leaveRequest.PeriodEnd)
+		if err != nil {
+			return nil, &models.ErrorResponse{
+				Code:    http.StatusInternalServerError,
+				Message: "Internal Server Error",
+			}
+		}
+		if overlapping {
+			return nil, nil
+		}
+
+		return autoRule, nil
+	}
+
+	return nil, nil
+}
+
+func (us *LeaveRequest) autoApprovalFacts(ctx context.Context, leaveRequest *entity.LeaveRequest, rules *entity.LeaveRules, autoRules []*entity.AutoApprovalRule) (*entity.AutoApprovalFacts, *models.ErrorResponse) {
+	user, err := us.userRepo.FindById(ctx, leaveRequest.UserId)
+	if err != nil {
+		return nil, &models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal Server Error",
+		}
+	}
+
+	facts := &entity.AutoApprovalFacts{
+		LeaveType:   leaveRequest.Type,
+		Role:        user.Role,
+		Department:  user.Department,
+		WorkingDays: leaveRequest.WorkingDays,
+		NoticeDays:  int(util.DateOnly(leaveRequest.StartDate).Sub(util.DateOnly(time.Now())).Hours() / 24),
+	}
+
+	var needsBalance, needsTeam bool
+	for _, autoRule := range autoRules {
+		needsBalance = needsBalance || autoRule.MinBalanceAfter != nil
+		needsTeam = needsTeam || autoRule.MaxTeamAbsent != nil
+	}
+
+	if needsBalance && rules.Type.DeductsBalance {
+		balance, err := us.leaveBalanceRepo.GetBalance(ctx, leaveRequest.UserId, leaveRequest.LeaveYear(), leaveRequest.Type, leaveRequest.ID)
+		if err != nil {
+			return nil, &models.ErrorResponse{
+				Code:    http.StatusInternalServerError,
+				Message: "Internal Server Error",
+			}
+		}
+		balanceAfter := balance.Available() - leaveRequest.WorkingDays
+		facts.BalanceAfter = &balanceAfter
+	}
+
+	if needsTeam {
+		teamAbsent, err := us.leaveRequestRepo.CountTeamAbsences(ctx, leaveRequest.UserId, leaveRequest.PeriodStart, leaveRequest.PeriodEnd)
+		if err != nil {
+			return nil, &models.ErrorResponse{
+				Code:    http.StatusInternalServerError,
+				Message: "Internal Server Error",
+			}
+		}
+		facts.TeamAbsent = teamAbsent
+	}
+
+	return facts, nil
+}
+
+// autoApprove takes a request that has just entered waiting_approval through the approve
+// transition on behalf of autoRule: every step of its chain is approved by the rule and its
+// days are consumed, as when the last approver approves. It does nothing without a rule.
+func (us *LeaveRequest) autoApprove(ctx context.Context, leaveRequest *entity.LeaveRequest, autoRule *entity.AutoApprovalRule) *models.ErrorResponse {
+	if autoRule == nil {
+		return nil
+	}
+
+	rule, _ := entity.FindTransitionRule(entity.TransitionApprove, leaveRequest.Status, "")
+	comment := fmt.Sprintf("Approved automatically by rule %q", autoRule.Name)
+
+	if err := us.approvalRepo.ApprovePendingSteps(ctx, leaveRequest.ID, autoRule.ID, comment); err != nil {
+		return &models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to record approval decision",
+		}
+	}
+
+	approved, err := us.leaveRequestRepo.AutoApprove(ctx, leaveRequest, autoRule, comment)
+	if isApprovedOverlapViolation(err) {
+		return errApprovedLeaveOverlap()
+	}
+	if err != nil {
+		return &models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to Approve Leave Request",
+		}
+	}
+	if !approved {
+		return errLeaveRequestChanged()
+	}
+
+	if errEffects := us.applyEffects(ctx, rule, leaveRequest, leaveRequest); errEffects != nil {
+		return errEffects
+	}
+
+	leaveRequest.Status = rule.To
+	return nil
+}
+
+// countWorkingDays returns how many working days a request costs once weekends and public
+// holidays are left out. Half-day sessions count for 0.5 and hourly leave for its share of a
+// working day. A request without any working time cannot be made.
+func (us *LeaveRequest) countWorkingDays(ctx context.Context, leaveRequest *entity.LeaveRequest) (float64, *models.ErrorResponse) {
+	holidays, err := us.holidayRepo.GetHolidaysBetween(ctx, util.DateOnly(leaveRequest.StartDate), util.DateOnly(leaveRequest.EndDate))
+	if err != nil {
+		return 0, &models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal Server Error",
+		}
+	}
+
+	holidayDates := make([]time.Time, 0, len(holidays))
+	for _, holiday := range holidays {
+		holidayDates = append(holidayDates, holiday.Date)
+	}
+
+	workingDays := chargeableDays(leaveRequest, util.NewHolidaySet(holidayDates...))
+	if workingDays == 0 {
+		return 0, &models.ErrorResponse{
+			Code:    http.StatusUnprocessableEntity,
+			Message: "The requested leave dates do not include any working day.",
+		}
+	}
+
+	return workingDays, nil
+}
+
+func chargeableDays(leaveRequest *entity.LeaveRequest, holidays util.HolidaySet) float64 {
+	startDay := util.DateOnly(leaveRequest.StartDate)
+	endDay := util.DateOnly(leaveRequest.EndDate)
+
+	if leaveRequest.DurationUnit == entity.DurationHour {
+		if !util.IsWorkingDay(startDay, holidays) {
+			return 0
+		}
+		// A request is validated to fit in a working day; anything longer still costs one day.
+		return math.Min(leaveRequest.Hours()/entity.WorkingHoursPerDay, 1)
+	}
+
+	days := util.WorkingDays(startDay, endDay, holidays)
+	if leaveRequest.StartSession == entity.SessionPM && util.IsWorkingDay(startDay, holidays) {
+		days -= 0.5
+	}
+	if leaveRequest.EndSession == entity.SessionAM && util.IsWorkingDay(endDay, holidays) {
+		days -= 0.5
+	}
+
+	return days
+}
+
+// findLeaveRules loads the request's leave type together with the leave policy that applies
+// to its owner on the first day of leave, if any.
+func (us *LeaveRequest) findLeaveRules(ctx context.Context, leaveRequest *entity.LeaveRequest) (*entity.LeaveRules, *models.ErrorResponse) {
+	leaveType, errType := findLeaveType(ctx, us.leaveTypeRepo, leaveRequest.Type)
+	if errType != nil {
+		return nil, errType
+	}
+
+	policy, err := us.leavePolicyRepo.FindApplicable(ctx, leaveRequest.UserId, leaveRequest.Type, util.DateOnly(leaveRequest.StartDate))
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, &models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal Server Error",
+		}
+	}
+
+	return &entity.LeaveRules{Type: leaveType, Policy: policy}, nil
+}
+
+// checkLeaveType applies the rules of the request's leave type, as adjusted by the owner's
+// leave policy: the type must still be offered and open to the employee's role, and the
+// request must not be longer than allowed. Requests being put up for approval must also give
+// the minimum notice.
+func (us *LeaveRequest) checkLeaveType(ctx context.Context, leaveRequest *entity.LeaveRequest, submitting bool) (*entity.LeaveRules, *models.ErrorResponse) {
+	rules, errRules := us.findLeaveRules(ctx, leaveRequest)
+	if errRules != nil {
+		return nil, errRules
+	}
+	leaveType := rules.Type
+
+	if !leaveType.Active {
+		return nil, &models.ErrorResponse{
+			Code:    http.StatusUnprocessableEntity,
+			Message: fmt.Sprintf("%s can no longer be requested.", leaveType.Name),
+		}
+	}
+
+	if len(leaveType.AllowedRoles) > 0 {
+		user, err := us.userRepo.FindById(ctx, leaveRequest.UserId)
+		if err != nil {
+			return nil, &models.ErrorResponse{
+				Code:    http.StatusInternalServerError,
+				Message: "Internal Server Error",
+			}
+		}
+		if !leaveType.AllowsRole(user.Role) {
+			return nil, &models.ErrorResponse{
+				Code:    http.StatusForbidden,
+				Message: fmt.Sprintf("%s is not available to your role.", leaveType.Name),
+			}
+		}
+	}
+
+	if rules.ExceedsMaxConsecutiveDays(leaveRequest.WorkingDays) {
+		return nil, &models.ErrorResponse{
+			Code: http.StatusUnprocessableEntity,
+			Message: fmt.Sprintf(
+				"%s is limited to %.1f consecutive working day(s), %.1f requested.",
+				leaveType.Name, *rules.MaxConsecutiveDays(), leaveRequest.WorkingDays,
+			),
+		}
+	}
+
+	minNoticeDays := rules.MinNoticeDays()
+	if submitting && minNoticeDays > 0 {
+		earliest := util.DateOnly(time.Now()).AddDate(0, 0, minNoticeDays)
+		if util.DateOnly(leaveRequest.StartDate).Before(earliest) {
+			return nil, &models.ErrorResponse{
+				Code:    http.StatusUnprocessableEntity,
+				Message: fmt.Sprintf("%s must be requested at least %d day(s) in advance.", leaveType.Name, minNoticeDays),
+			}
+		}
+	}
+
+	return rules, nil
+}
+
+// deductsBalance reports whether the request's leave type is charged against the ledger.
+func (us *LeaveRequest) deductsBalance(ctx context.Context, leaveRequest *entity.LeaveRequest) (bool, *models.ErrorResponse) {
+	leaveType, errType := findLeaveType(ctx, us.leaveTypeRepo, leaveRequest.Type)
+	if errType != nil {
+		return false, errType
+	}
+
+	return leaveType.DeductsBalance, nil
+}
+
+// checkBalance rejects a request its owner's leave policy does not entitle them to, or whose
+// day count exceeds what is left in the ledger, ignoring any reservation the request itself
+// already holds. It runs inside inTx and locks the owner's ledger there, so the reservation or
+// debit written later in the same transaction is based on a balance nobody else can spend.
+func (us *LeaveRequest) checkBalance(ctx context.Context, leaveRequest *entity.LeaveRequest, rules *entity.LeaveRules) *models.ErrorResponse {
+	if !rules.Entitled() {
+		return &models.ErrorResponse{
+			Code:    http.StatusUnprocessableEntity,
+			Message: fmt.Sprintf("%s is not available to you under the %s policy.", rules.Type.Name, rules.Policy.Name),
+		}
+	}
+
+	if !rules.Type.DeductsBalance {
+		return nil
+	}
+
+	if err := us.leaveBalanceRepo.LockUser(ctx, leaveRequest.UserId); err != nil {
+		return &models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal Server Error",
+		}
+	}
+
+	balance, err := us.leaveBalanceRepo.GetBalance(ctx, leaveRequest.UserId, leaveRequest.LeaveYear(), leaveRequest.Type, leaveRequest.ID)
+	if err != nil {
+		return &models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal Server Error",
+		}
+	}
+
+	if balance.Available() < leaveRequest.WorkingDays {
+		return &models.ErrorResponse{
+			Code: http.StatusUnprocessableEntity,
+			Message: fmt.Sprintf(
+				"Insufficient %s leave balance: %.1f day(s) available, %.1f requested.",
+				leaveRequest.Type, balance.Available(), leaveRequest.WorkingDays,
+			),
+		}
+	}
+
+	return nil
+}
+
+func (us *LeaveRequest) reserveBalance(ctx context.Context, leaveRequest *entity.LeaveRequest) *models.ErrorResponse {
+	deducts, errType := us.deductsBalance(ctx, leaveRequest)
+	if errType != nil || !deducts {
+		return errType
+	}
+
+	err := us.leaveBalanceRepo.AddEntry(ctx, newBalanceEntry(leaveRequest, entity.BalanceReservation))
+	if err != nil {
+		return &models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to reserve leave balance",
+		}
+	}
+
+	return nil
+}
+
+func (us *LeaveRequest) releaseBalance(ctx context.Context, leaveRequest *entity.LeaveRequest) *models.ErrorResponse {
+	deducts, errType := us.deductsBalance(ctx, leaveRequest)
+	if errType != nil || !deducts {
+		return errType
+	}
+
+	err := us.leaveBalanceRepo.ReleaseReservation(ctx, leaveRequest.ID)
+	if err != nil {
+		return &models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to release leave balance",
+		}
+	}
+
+	return nil
+}
+
+func (us *LeaveRequest) consumeBalance(ctx context.Context, leaveRequest *entity.LeaveRequest) *models.ErrorResponse {
+	deducts, errType := us.deductsBalance(ctx, leaveRequest)
+	if errType != nil || !deducts {
+		return errType
+	}
+
+	if errRelease := us.releaseBalance(ctx, leaveRequest); errRelease != nil {
+		return errRelease
+	}
+	// Holidays added after submission can leave a request without any working day to debit.
+	if leaveRequest.WorkingDays == 0 {
+		return nil
+	}
+
+	err := us.leaveBalanceRepo.AddEntry(ctx, newBalanceEntry(leaveRequest, entity.BalanceDebit))
+	if err != nil {
+		return &models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to debit leave balance",
+		}
+	}
+
+	return nil
+}
+
+// refundBalance gives the days consumed by approved leave back to the ledger.
+func (us *LeaveRequest) refundBalance(ctx context.Context, leaveRequest *entity.LeaveRequest) *models.ErrorResponse {
+	deducts, errType := us.deductsBalance(ctx, leaveRequest)
+	if errType != nil || !deducts {
+		return errType
+	}
+
+	err := us.leaveBalanceRepo.ReleaseDebit(ctx, leaveRequest.ID)
+	if err != nil {
+		return &models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to release leave balance",
+		}
+	}
+
+	return nil
+}
+
+func newBalanceEntry(leaveRequest *entity.LeaveRequest, entryType entity.BalanceEntryType) *entity.LeaveBalanceEntry {
+	leaveRequestId := leaveRequest.ID
+
+	return &entity.LeaveBalanceEntry{
+		UserId:         leaveRequest.UserId,
+		LeaveYear:      leaveRequest.LeaveYear(),
+		Type:           leaveRequest.Type,
+		EntryType:      entryType,
+		Days:           leaveRequest.WorkingDays,
+		LeaveRequestId: &leaveRequestId,
+	}
 }
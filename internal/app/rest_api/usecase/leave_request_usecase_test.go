@@ -1,15 +1,25 @@
 package usecase_test
 
 import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
 	"errors"
+	"net/http"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 
 	entity "github.com/devonLoen/leave-request-service/internal/app/rest_api/entity"
 	"github.com/devonLoen/leave-request-service/internal/app/rest_api/model/dto"
+	"github.com/devonLoen/leave-request-service/internal/app/rest_api/pkg/util"
+	"github.com/devonLoen/leave-request-service/internal/app/rest_api/repository"
 	"github.com/devonLoen/leave-request-service/internal/app/rest_api/usecase"
 )
 
@@ -17,16 +27,26 @@ type MockLeaveRequestRepo struct {
 	mock.Mock
 }
 
-func (m *MockLeaveRequestRepo) OverlapApprovedLeaveExists(userId int, startDate, endDate time.Time) (bool, error) {
+func (m *MockLeaveRequestRepo) OverlapApprovedLeaveExists(ctx context.Context, userId int, startDate, endDate time.Time) (bool, error) {
 	args := m.Called(userId, startDate, endDate)
 	return args.Bool(0), args.Error(1)
 }
 
-func (m *MockLeaveRequestRepo) Create(lr *entity.LeaveRequest) error {
-	return m.Called(lr).Error(0)
+func (m *MockLeaveRequestRepo) Create(ctx context.Context, lr *entity.LeaveRequest, actorId int) error {
+	return m.Called(lr, actorId).Error(0)
 }
 
-func (m *MockLeaveRequestRepo) FindById(id int) (*entity.LeaveRequest, error) {
+func (m *MockLeaveRequestRepo) Update(ctx context.Context, current, updated *entity.LeaveRequest, actorId int) (bool, error) {
+	args := m.Called(current, updated, actorId)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockLeaveRequestRepo) Delete(ctx context.Context, lr *entity.LeaveRequest, actorId int) (bool, error) {
+	args := m.Called(lr, actorId)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockLeaveRequestRepo) FindById(ctx context.Context, id int) (*entity.LeaveRequest, error) {
 	args := m.Called(id)
 	if args.Get(0) != nil {
 		return args.Get(0).(*entity.LeaveRequest), args.Error(1)
@@ -34,7 +54,27 @@ func (m *MockLeaveRequestRepo) FindById(id int) (*entity.LeaveRequest, error) {
 	return nil, args.Error(1)
 }
 
-func (m *MockLeaveRequestRepo) GetAllLeaveRequests(limit, offset int, sortBy, orderBy, search string, filter entity.LeaveRequestFilter) ([]*entity.LeaveRequest, error) {
+func (m *MockLeaveRequestRepo) GetAbsences(ctx context.Context, filter entity.LeaveRequestFilter) ([]*entity.Absence, error) {
+	args := m.Called(filter)
+	if args.Get(0) != nil {
+		return args.Get(0).([]*entity.Absence), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockLeaveRequestRepo) ExportLeaveRequests(ctx context.Context, sortBy, orderBy, search string, filter entity.LeaveRequestFilter, fn func(*entity.LeaveRequestExport) error) error {
+	args := m.Called(sortBy, orderBy, search, filter)
+	if rows, ok := args.Get(0).([]*entity.LeaveRequestExport); ok {
+		for _, row := range rows {
+			if err := fn(row); err != nil {
+				return err
+			}
+		}
+	}
+	return args.Error(1)
+}
+
+func (m *MockLeaveRequestRepo) GetAllLeaveRequests(ctx context.Context, limit, offset int, sortBy, orderBy, search string, filter entity.LeaveRequestFilter) ([]*entity.LeaveRequest, error) {
 	args := m.Called(limit, offset, sortBy, orderBy, search, filter)
 	if args.Get(0) != nil {
 		return args.Get(0).([]*entity.LeaveRequest), args.Error(1)
@@ -42,24 +82,269 @@ func (m *MockLeaveRequestRepo) GetAllLeaveRequests(limit, offset int, sortBy, or
 	return nil, args.Error(1)
 }
 
-func (m *MockLeaveRequestRepo) Approve(leaveRequestID int) error {
-	return m.Called(leaveRequestID).Error(0)
+func (m *MockLeaveRequestRepo) GetEvents(ctx context.Context, leaveRequestId int) ([]*entity.LeaveRequestEvent, error) {
+	args := m.Called(leaveRequestId)
+	if args.Get(0) != nil {
+		return args.Get(0).([]*entity.LeaveRequestEvent), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockLeaveRequestRepo) Approve(ctx context.Context, lr *entity.LeaveRequest, decidedBy int, comment string) (bool, error) {
+	args := m.Called(lr.ID, decidedBy, comment)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockLeaveRequestRepo) Reject(ctx context.Context, lr *entity.LeaveRequest, decidedBy int, reason string) (bool, error) {
+	args := m.Called(lr.ID, decidedBy, reason)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockLeaveRequestRepo) Submit(ctx context.Context, lr *entity.LeaveRequest, actorId int) (bool, error) {
+	args := m.Called(lr.ID, actorId)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockLeaveRequestRepo) GetOpenBetween(ctx context.Context, from, to time.Time) ([]*entity.LeaveRequest, error) {
+	args := m.Called(from, to)
+	if args.Get(0) != nil {
+		return args.Get(0).([]*entity.LeaveRequest), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockLeaveRequestRepo) RecountWorkingDays(ctx context.Context, lr *entity.LeaveRequest, workingDays float64) (bool, error) {
+	args := m.Called(lr.ID, workingDays)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockLeaveRequestRepo) AutoApprove(ctx context.Context, lr *entity.LeaveRequest, rule *entity.AutoApprovalRule, comment string) (bool, error) {
+	args := m.Called(lr.ID, rule.ID, comment)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockLeaveRequestRepo) CountTeamAbsences(ctx context.Context, userId int, periodStart, periodEnd time.Time) (int, error) {
+	args := m.Called(userId, periodStart, periodEnd)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockLeaveRequestRepo) Cancel(ctx context.Context, lr *entity.LeaveRequest, actorId int) (bool, error) {
+	args := m.Called(lr.ID, actorId)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockLeaveRequestRepo) RequestCancellation(ctx context.Context, lr *entity.LeaveRequest, actorId int) (bool, error) {
+	args := m.Called(lr.ID, actorId)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockLeaveRequestRepo) RejectCancellation(ctx context.Context, lr *entity.LeaveRequest, actorId int) (bool, error) {
+	args := m.Called(lr.ID, actorId)
+	return args.Bool(0), args.Error(1)
+}
+
+type MockLeaveBalanceRepo struct {
+	mock.Mock
+}
+
+func (m *MockLeaveBalanceRepo) AddEntry(ctx context.Context, entry *entity.LeaveBalanceEntry) error {
+	return m.Called(entry).Error(0)
+}
+
+func (m *MockLeaveBalanceRepo) AddPeriodEntry(ctx context.Context, entry *entity.LeaveBalanceEntry) (bool, error) {
+	args := m.Called(entry)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockLeaveBalanceRepo) SumEntries(ctx context.Context, userId, leaveYear int, leaveType entity.LeaveRequestType, entryType entity.BalanceEntryType) (float64, error) {
+	args := m.Called(userId, leaveYear, leaveType, entryType)
+	return args.Get(0).(float64), args.Error(1)
+}
+
+func (m *MockLeaveBalanceRepo) GetUsedBefore(ctx context.Context, userId, leaveYear int, leaveType entity.LeaveRequestType, before time.Time) (float64, error) {
+	args := m.Called(userId, leaveYear, leaveType, before)
+	return args.Get(0).(float64), args.Error(1)
+}
+
+func (m *MockLeaveBalanceRepo) GetBalance(ctx context.Context, userId, leaveYear int, leaveType entity.LeaveRequestType, excludeLeaveRequestId int) (*entity.LeaveBalance, error) {
+	args := m.Called(userId, leaveYear, leaveType, excludeLeaveRequestId)
+	if args.Get(0) != nil {
+		return args.Get(0).(*entity.LeaveBalance), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockLeaveBalanceRepo) GetBalances(ctx context.Context, userId, leaveYear int) ([]*entity.LeaveBalance, error) {
+	args := m.Called(userId, leaveYear)
+	if args.Get(0) != nil {
+		return args.Get(0).([]*entity.LeaveBalance), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockLeaveBalanceRepo) ReleaseReservation(ctx context.Context, leaveRequestId int) error {
+	return m.Called(leaveRequestId).Error(0)
+}
+
+func (m *MockLeaveBalanceRepo) ReleaseDebit(ctx context.Context, leaveRequestId int) error {
+	return m.Called(leaveRequestId).Error(0)
+}
+
+func (m *MockLeaveBalanceRepo) LockUser(ctx context.Context, userId int) error {
+	return m.Called(userId).Error(0)
+}
+
+type MockApprovalRepo struct {
+	mock.Mock
+}
+
+func (m *MockApprovalRepo) GetAllChains(ctx context.Context) ([]*entity.ApprovalChain, error) {
+	args := m.Called()
+	if args.Get(0) != nil {
+		return args.Get(0).([]*entity.ApprovalChain), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockApprovalRepo) FindChainFor(ctx context.Context, leaveType entity.LeaveRequestType, workingDays float64) (*entity.ApprovalChain, error) {
+	args := m.Called(leaveType, workingDays)
+	if args.Get(0) != nil {
+		return args.Get(0).(*entity.ApprovalChain), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockApprovalRepo) UpsertChain(ctx context.Context, chain *entity.ApprovalChain) error {
+	return m.Called(chain).Error(0)
+}
+
+func (m *MockApprovalRepo) DeleteChain(ctx context.Context, id int) (bool, error) {
+	args := m.Called(id)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockApprovalRepo) CreateSteps(ctx context.Context, leaveRequestId int, steps []entity.ApproverKind) error {
+	return m.Called(leaveRequestId, steps).Error(0)
+}
+
+func (m *MockApprovalRepo) GetSteps(ctx context.Context, leaveRequestId int) ([]*entity.ApprovalStep, error) {
+	args := m.Called(leaveRequestId)
+	if args.Get(0) != nil {
+		return args.Get(0).([]*entity.ApprovalStep), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockApprovalRepo) DecideStep(ctx context.Context, stepId, approverId int, onBehalfOfId *int, status entity.ApprovalStepStatus, comment string) (bool, error) {
+	args := m.Called(stepId, approverId, onBehalfOfId, status, comment)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockApprovalRepo) SkipPendingSteps(ctx context.Context, leaveRequestId int) error {
+	return m.Called(leaveRequestId).Error(0)
+}
+
+func (m *MockApprovalRepo) ApprovePendingSteps(ctx context.Context, leaveRequestId, ruleId int, comment string) error {
+	return m.Called(leaveRequestId, ruleId, comment).Error(0)
+}
+
+func (m *MockApprovalRepo) DeleteSteps(ctx context.Context, leaveRequestId int) error {
+	return m.Called(leaveRequestId).Error(0)
+}
+
+type MockDelegationRepo struct {
+	mock.Mock
+}
+
+func (m *MockDelegationRepo) Create(ctx context.Context, delegation *entity.ApprovalDelegation) error {
+	return m.Called(delegation).Error(0)
+}
+
+func (m *MockDelegationRepo) Delete(ctx context.Context, id, delegatorId int) (bool, error) {
+	args := m.Called(id, delegatorId)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockDelegationRepo) GetByDelegator(ctx context.Context, delegatorId int) ([]*entity.ApprovalDelegation, error) {
+	args := m.Called(delegatorId)
+	if args.Get(0) != nil {
+		return args.Get(0).([]*entity.ApprovalDelegation), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockDelegationRepo) GetActiveForDelegate(ctx context.Context, delegateId int, on time.Time) ([]*entity.ApprovalDelegation, error) {
+	args := m.Called(delegateId, on)
+	if args.Get(0) != nil {
+		return args.Get(0).([]*entity.ApprovalDelegation), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockDelegationRepo) OverlapExists(ctx context.Context, delegatorId int, startDate, endDate time.Time) (bool, error) {
+	args := m.Called(delegatorId, startDate, endDate)
+	return args.Bool(0), args.Error(1)
+}
+
+type MockHolidayRepo struct {
+	mock.Mock
+}
+
+func (m *MockHolidayRepo) Create(ctx context.Context, holiday *entity.Holiday) error {
+	return m.Called(holiday).Error(0)
+}
+
+func (m *MockHolidayRepo) Update(ctx context.Context, holiday *entity.Holiday) error {
+	return m.Called(holiday).Error(0)
+}
+
+func (m *MockHolidayRepo) Upsert(ctx context.Context, holiday *entity.Holiday) error {
+	return m.Called(holiday).Error(0)
+}
+
+func (m *MockHolidayRepo) Delete(ctx context.Context, id int) (bool, error) {
+	args := m.Called(id)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockHolidayRepo) FindById(ctx context.Context, id int) (*entity.Holiday, error) {
+	args := m.Called(id)
+	if args.Get(0) != nil {
+		return args.Get(0).(*entity.Holiday), args.Error(1)
+	}
+	return nil, args.Error(1)
 }
 
-func (m *MockLeaveRequestRepo) Reject(leaveRequestID int) error {
-	return m.Called(leaveRequestID).Error(0)
+func (m *MockHolidayRepo) GetHolidaysBetween(ctx context.Context, start, end time.Time) ([]*entity.Holiday, error) {
+	args := m.Called(start, end)
+	if args.Get(0) != nil {
+		return args.Get(0).([]*entity.Holiday), args.Error(1)
+	}
+	return nil, args.Error(1)
 }
 
-func (m *MockLeaveRequestRepo) Submit(leaveRequestID int) error {
-	return m.Called(leaveRequestID).Error(0)
+// nextMonday keeps the requested ranges on working days whatever day the tests run.
+func nextMonday() time.Time {
+	day := time.Now().Truncate(24*time.Hour).AddDate(0, 0, 1)
+	for day.Weekday() != time.Monday {
+		day = day.AddDate(0, 0, 1)
+	}
+	return day
 }
 
 func TestCreateLeaveRequest(t *testing.T) {
 
 	mockRepo := new(MockLeaveRequestRepo)
-	uc := usecase.NewLeaveRequestUsecase(mockRepo)
+	mockBalanceRepo := new(MockLeaveBalanceRepo)
+	mockHolidayRepo := new(MockHolidayRepo)
+	mockApprovalRepo := new(MockApprovalRepo)
+	mockLeaveTypeRepo := new(MockLeaveTypeRepo)
+	mockLeavePolicyRepo := new(MockLeavePolicyRepo)
+	mockRuleRepo := new(MockAutoApprovalRuleRepo)
+	uc := newLeaveRequestUsecase(mockRepo, mockBalanceRepo, mockHolidayRepo, mockLeaveTypeRepo, mockLeavePolicyRepo, mockRuleRepo, newUserRepo(t), mockApprovalRepo, new(MockDelegationRepo))
 
-	today := time.Now().Truncate(24 * time.Hour)
+	monday := nextMonday()
+	mondayUTC := time.Date(monday.Year(), monday.Month(), monday.Day(), 0, 0, 0, 0, time.UTC)
 
 	tests := []struct {
 		name       string
@@ -68,13 +353,26 @@ func TestCreateLeaveRequest(t *testing.T) {
 		wantErr    bool
 		errMessage string
 	}{
+		{
+			name: "Weekend only",
+			req: dto.CreateLeaveRequestRequest{
+				StartDate: monday.AddDate(0, 0, -2),
+				EndDate:   monday.AddDate(0, 0, -1),
+			},
+			setupMock:  func() {},
+			wantErr:    true,
+			errMessage: "do not include any working day",
+		},
 		{
 			name: "Overlap detected",
 			req: dto.CreateLeaveRequestRequest{
-				StartDate: today.Add(24 * time.Hour),
-				EndDate:   today.Add(48 * time.Hour),
+				StartDate: monday,
+				EndDate:   monday.AddDate(0, 0, 1),
+				Type:      "unpaid",
 			},
 			setupMock: func() {
+				mockLeaveTypeRepo.On("FindByCode", entity.Unpaid).Return(unpaidLeave, nil).Once()
+				mockLeavePolicyRepo.On("FindApplicable", 1, entity.Unpaid, mock.Anything).Return(nil, sql.ErrNoRows).Once()
 				mockRepo.On("OverlapApprovedLeaveExists", 1, mock.Anything, mock.Anything).
 					Return(true, nil).Once()
 			},
@@ -84,28 +382,156 @@ func TestCreateLeaveRequest(t *testing.T) {
 		{
 			name: "Repo create error",
 			req: dto.CreateLeaveRequestRequest{
-				StartDate: today.Add(24 * time.Hour),
-				EndDate:   today.Add(48 * time.Hour),
+				StartDate: monday,
+				EndDate:   monday.AddDate(0, 0, 1),
+				Type:      "unpaid",
 			},
 			setupMock: func() {
+				mockLeaveTypeRepo.On("FindByCode", entity.Unpaid).Return(unpaidLeave, nil).Once()
+				mockLeavePolicyRepo.On("FindApplicable", 1, entity.Unpaid, mock.Anything).Return(nil, sql.ErrNoRows).Once()
 				mockRepo.On("OverlapApprovedLeaveExists", 1, mock.Anything, mock.Anything).
 					Return(false, nil).Once()
-				mockRepo.On("Create", mock.Anything).
+				mockRepo.On("Create", mock.Anything, 1).
 					Return(errors.New("db error")).Once()
 			},
 			wantErr:    true,
 			errMessage: "Failed to create leave Request",
 		},
+		{
+			name: "Insufficient balance",
+			req: dto.CreateLeaveRequestRequest{
+				StartDate: monday,
+				EndDate:   monday.AddDate(0, 0, 2),
+				Type:      "annual",
+				Status:    "waiting_approval",
+			},
+			setupMock: func() {
+				mockLeaveTypeRepo.On("FindByCode", entity.Annual).Return(annualLeave, nil).Once()
+				mockLeavePolicyRepo.On("FindApplicable", 1, entity.Annual, mock.Anything).Return(nil, sql.ErrNoRows).Once()
+				mockRuleRepo.On("GetActive").Return([]*entity.AutoApprovalRule{}, nil).Once()
+				mockRepo.On("OverlapApprovedLeaveExists", 1, mock.Anything, mock.Anything).
+					Return(false, nil).Once()
+				mockBalanceRepo.On("LockUser", 1).Return(nil).Once()
+				mockBalanceRepo.On("GetBalance", 1, mock.Anything, entity.Annual, 0).
+					Return(&entity.LeaveBalance{Entitled: 12, Used: 9, Reserved: 1}, nil).Once()
+			},
+			wantErr:    true,
+			errMessage: "Insufficient annual leave balance",
+		},
+		{
+			name: "Success create with reservation",
+			req: dto.CreateLeaveRequestRequest{
+				StartDate: monday,
+				EndDate:   monday.AddDate(0, 0, 2),
+				Type:      "annual",
+				Status:    "waiting_approval",
+			},
+			setupMock: func() {
+				mockLeaveTypeRepo.On("FindByCode", entity.Annual).Return(annualLeave, nil).Twice()
+				mockLeavePolicyRepo.On("FindApplicable", 1, entity.Annual, mock.Anything).Return(nil, sql.ErrNoRows).Once()
+				mockRuleRepo.On("GetActive").Return([]*entity.AutoApprovalRule{}, nil).Once()
+				mockRepo.On("OverlapApprovedLeaveExists", 1, mock.Anything, mock.Anything).
+					Return(false, nil).Once()
+				mockBalanceRepo.On("LockUser", 1).Return(nil).Once()
+				mockBalanceRepo.On("GetBalance", 1, mock.Anything, entity.Annual, 0).
+					Return(&entity.LeaveBalance{Entitled: 12}, nil).Once()
+				mockRepo.On("Create", mock.Anything, 1).
+					Return(nil).Once()
+				mockBalanceRepo.On("AddEntry", mock.MatchedBy(func(e *entity.LeaveBalanceEntry) bool {
+					return e.EntryType == entity.BalanceReservation && e.Days == 3
+				})).Return(nil).Once()
+			},
+			wantErr: false,
+		},
+		{
+			name: "Half days are charged half",
+			req: dto.CreateLeaveRequestRequest{
+				StartDate:    monday,
+				EndDate:      monday.AddDate(0, 0, 1),
+				StartSession: "pm",
+				EndSession:   "am",
+				Type:         "annual",
+				Status:       "waiting_approval",
+			},
+			setupMock: func() {
+				mockLeaveTypeRepo.On("FindByCode", entity.Annual).Return(annualLeave, nil).Twice()
+				mockLeavePolicyRepo.On("FindApplicable", 1, entity.Annual, mock.Anything).Return(nil, sql.ErrNoRows).Once()
+				mockRuleRepo.On("GetActive").Return([]*entity.AutoApprovalRule{}, nil).Once()
+				mockRepo.On("OverlapApprovedLeaveExists", 1, mondayUTC.Add(12*time.Hour), mondayUTC.AddDate(0, 0, 1).Add(12*time.Hour)).
+					Return(false, nil).Once()
+				mockBalanceRepo.On("LockUser", 1).Return(nil).Once()
+				mockBalanceRepo.On("GetBalance", 1, mock.Anything, entity.Annual, 0).
+					Return(&entity.LeaveBalance{Entitled: 12}, nil).Once()
+				mockRepo.On("Create", mock.MatchedBy(func(lr *entity.LeaveRequest) bool {
+					return lr.WorkingDays == 1
+				}), 1).Return(nil).Once()
+				mockBalanceRepo.On("AddEntry", mock.Anything).Return(nil).Once()
+			},
+			wantErr: false,
+		},
+		{
+			name: "Hourly leave is charged per working hour",
+			req: dto.CreateLeaveRequestRequest{
+				StartDate:    monday,
+				EndDate:      monday,
+				DurationUnit: "hour",
+				StartTime:    "13:00",
+				EndTime:      "15:00",
+				Type:         "annual",
+				Status:       "draft",
+			},
+			setupMock: func() {
+				mockLeaveTypeRepo.On("FindByCode", entity.Annual).Return(annualLeave, nil).Once()
+				mockLeavePolicyRepo.On("FindApplicable", 1, entity.Annual, mock.Anything).Return(nil, sql.ErrNoRows).Once()
+				mockRepo.On("OverlapApprovedLeaveExists", 1, mondayUTC.Add(13*time.Hour), mondayUTC.Add(15*time.Hour)).
+					Return(false, nil).Once()
+				mockBalanceRepo.On("LockUser", 1).Return(nil).Once()
+				mockBalanceRepo.On("GetBalance", 1, mock.Anything, entity.Annual, 0).
+					Return(&entity.LeaveBalance{Entitled: 12}, nil).Once()
+				mockRepo.On("Create", mock.MatchedBy(func(lr *entity.LeaveRequest) bool {
+					return lr.WorkingDays == 0.25
+				}), 1).Return(nil).Once()
+			},
+			wantErr: false,
+		},
+		{
+			name: "Hourly leave costs at most one working day",
+			req: dto.CreateLeaveRequestRequest{
+				StartDate:    monday,
+				EndDate:      monday,
+				DurationUnit: "hour",
+				StartTime:    "08:00",
+				EndTime:      "20:00",
+				Type:         "annual",
+				Status:       "draft",
+			},
+			setupMock: func() {
+				mockLeaveTypeRepo.On("FindByCode", entity.Annual).Return(annualLeave, nil).Once()
+				mockLeavePolicyRepo.On("FindApplicable", 1, entity.Annual, mock.Anything).Return(nil, sql.ErrNoRows).Once()
+				mockRepo.On("OverlapApprovedLeaveExists", 1, mondayUTC.Add(8*time.Hour), mondayUTC.Add(20*time.Hour)).
+					Return(false, nil).Once()
+				mockBalanceRepo.On("LockUser", 1).Return(nil).Once()
+				mockBalanceRepo.On("GetBalance", 1, mock.Anything, entity.Annual, 0).
+					Return(&entity.LeaveBalance{Entitled: 12}, nil).Once()
+				mockRepo.On("Create", mock.MatchedBy(func(lr *entity.LeaveRequest) bool {
+					return lr.WorkingDays == 1
+				}), 1).Return(nil).Once()
+			},
+			wantErr: false,
+		},
 		{
 			name: "Success create",
 			req: dto.CreateLeaveRequestRequest{
-				StartDate: today.Add(24 * time.Hour),
-				EndDate:   today.Add(48 * time.Hour),
+				StartDate: monday,
+				EndDate:   monday.AddDate(0, 0, 1),
+				Type:      "unpaid",
 			},
 			setupMock: func() {
+				mockLeaveTypeRepo.On("FindByCode", entity.Unpaid).Return(unpaidLeave, nil).Once()
+				mockLeavePolicyRepo.On("FindApplicable", 1, entity.Unpaid, mock.Anything).Return(nil, sql.ErrNoRows).Once()
 				mockRepo.On("OverlapApprovedLeaveExists", 1, mock.Anything, mock.Anything).
 					Return(false, nil).Once()
-				mockRepo.On("Create", mock.Anything).
+				mockRepo.On("Create", mock.Anything, 1).
 					Return(nil).Once()
 			},
 			wantErr: false,
@@ -115,10 +541,19 @@ func TestCreateLeaveRequest(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			mockRepo.Mock.ExpectedCalls = nil
+			mockBalanceRepo.Mock.ExpectedCalls = nil
+			mockHolidayRepo.Mock.ExpectedCalls = nil
+			mockHolidayRepo.On("GetHolidaysBetween", mock.Anything, mock.Anything).Return([]*entity.Holiday{}, nil)
+			mockApprovalRepo.Mock.ExpectedCalls = nil
+			mockLeaveTypeRepo.Mock.ExpectedCalls = nil
+			mockLeavePolicyRepo.Mock.ExpectedCalls = nil
+			mockRuleRepo.Mock.ExpectedCalls = nil
+			mockApprovalRepo.On("FindChainFor", mock.Anything, mock.Anything).Return(nil, sql.ErrNoRows)
+			mockApprovalRepo.On("CreateSteps", mock.Anything, entity.DefaultApprovalSteps).Return(nil)
 
 			tt.setupMock()
 
-			res, errResp := uc.CreateLeaveRequest(&tt.req, 1)
+			res, errResp := uc.CreateLeaveRequest(context.Background(), &tt.req, 1)
 
 			if tt.wantErr {
 				assert.Nil(t, res)
@@ -130,6 +565,1075 @@ func TestCreateLeaveRequest(t *testing.T) {
 			}
 
 			mockRepo.AssertExpectations(t)
+			mockBalanceRepo.AssertExpectations(t)
+			mockLeaveTypeRepo.AssertExpectations(t)
+			mockLeavePolicyRepo.AssertExpectations(t)
+			mockRuleRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCancelLeaveRequest(t *testing.T) {
+	mockRepo := new(MockLeaveRequestRepo)
+	mockBalanceRepo := new(MockLeaveBalanceRepo)
+	mockApprovalRepo := new(MockApprovalRepo)
+	mockLeaveTypeRepo := new(MockLeaveTypeRepo)
+	uc := newLeaveRequestUsecase(mockRepo, mockBalanceRepo, new(MockHolidayRepo), mockLeaveTypeRepo, new(MockLeavePolicyRepo), new(MockAutoApprovalRuleRepo), newUserRepo(t), mockApprovalRepo, new(MockDelegationRepo))
+
+	tests := []struct {
+		name       string
+		existing   *entity.LeaveRequest
+		setupMock  func()
+		wantStatus entity.LeaveRequestStatus
+		wantCode   int
+	}{
+		{
+			name:     "Other user's request",
+			existing: &entity.LeaveRequest{ID: 7, UserId: 2, Type: entity.Annual, Status: entity.Draft},
+			setupMock: func() {
+			},
+			wantCode: http.StatusForbidden,
+		},
+		{
+			name:     "Draft is cancelled immediately",
+			existing: &entity.LeaveRequest{ID: 7, UserId: 1, Type: entity.Annual, Status: entity.Draft},
+			setupMock: func() {
+				mockRepo.On("Cancel", 7, 1).Return(true, nil).Once()
+			},
+			wantStatus: entity.Cancelled,
+		},
+		{
+			name:     "Waiting request is cancelled and its reservation released",
+			existing: &entity.LeaveRequest{ID: 7, UserId: 1, Type: entity.Annual, Status: entity.WaitingApproval},
+			setupMock: func() {
+				mockRepo.On("Cancel", 7, 1).Return(true, nil).Once()
+				mockApprovalRepo.On("SkipPendingSteps", 7).Return(nil).Once()
+				mockLeaveTypeRepo.On("FindByCode", entity.Annual).Return(annualLeave, nil).Once()
+				mockBalanceRepo.On("ReleaseReservation", 7).Return(nil).Once()
+			},
+			wantStatus: entity.Cancelled,
+		},
+		{
+			name:     "Approved request needs confirmation",
+			existing: &entity.LeaveRequest{ID: 7, UserId: 1, Type: entity.Annual, Status: entity.Approved},
+			setupMock: func() {
+				mockRepo.On("RequestCancellation", 7, 1).Return(true, nil).Once()
+			},
+			wantStatus: entity.CancellationRequested,
+		},
+		{
+			name:     "Rejected request cannot be cancelled",
+			existing: &entity.LeaveRequest{ID: 7, UserId: 1, Type: entity.Annual, Status: entity.Rejected},
+			setupMock: func() {
+			},
+			wantCode: http.StatusUnprocessableEntity,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo.Mock.ExpectedCalls = nil
+			mockBalanceRepo.Mock.ExpectedCalls = nil
+			mockApprovalRepo.Mock.ExpectedCalls = nil
+			mockLeaveTypeRepo.Mock.ExpectedCalls = nil
+			mockRepo.On("FindById", 7).Return(tt.existing, nil).Once()
+
+			tt.setupMock()
+
+			status, errResp := uc.Cancel(context.Background(), 7, 1, 0)
+
+			if tt.wantCode != 0 {
+				assert.NotNil(t, errResp)
+				assert.Equal(t, tt.wantCode, errResp.Code)
+			} else {
+				assert.Nil(t, errResp)
+				assert.Equal(t, tt.wantStatus, status)
+			}
+
+			mockRepo.AssertExpectations(t)
+			mockBalanceRepo.AssertExpectations(t)
+			mockApprovalRepo.AssertExpectations(t)
+			mockLeaveTypeRepo.AssertExpectations(t)
 		})
 	}
 }
+
+func TestApproveCancellationReleasesDebit(t *testing.T) {
+	mockRepo := new(MockLeaveRequestRepo)
+	mockBalanceRepo := new(MockLeaveBalanceRepo)
+	mockLeaveTypeRepo := new(MockLeaveTypeRepo)
+	db, sqlMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating mock database: %v", err)
+	}
+	defer db.Close()
+	uc := newLeaveRequestUsecase(mockRepo, mockBalanceRepo, new(MockHolidayRepo), mockLeaveTypeRepo, new(MockLeavePolicyRepo), new(MockAutoApprovalRuleRepo), repository.NewUserRepository(db), new(MockApprovalRepo), new(MockDelegationRepo))
+
+	sqlMock.ExpectQuery(`SELECT u.id`).WithArgs(9).
+		WillReturnRows(userRows().AddRow(9, "Super Admin", "root@example.com", "superadmin", nil, "", hiredOn))
+
+	mockRepo.On("FindById", 7).
+		Return(&entity.LeaveRequest{ID: 7, UserId: 1, Type: entity.Sick, Status: entity.CancellationRequested}, nil).Once()
+	mockRepo.On("Cancel", 7, 9).Return(true, nil).Once()
+	mockLeaveTypeRepo.On("FindByCode", entity.Sick).Return(sickLeave, nil).Once()
+	mockBalanceRepo.On("ReleaseDebit", 7).Return(nil).Once()
+
+	assert.Nil(t, uc.ApproveCancellation(context.Background(), 7, 9, 0))
+
+	mockRepo.AssertExpectations(t)
+	mockBalanceRepo.AssertExpectations(t)
+	mockLeaveTypeRepo.AssertExpectations(t)
+}
+
+func TestApproveCancellationRequiresAdmin(t *testing.T) {
+	tests := []struct {
+		name       string
+		approverID int
+		approver   []driver.Value
+		wantCode   int
+	}{
+		{
+			name:       "Line manager is refused",
+			approverID: 5,
+			approver:   []driver.Value{5, "Mia Manager", "mia@example.com", "employee", nil, "", hiredOn},
+			wantCode:   http.StatusForbidden,
+		},
+		{
+			name:       "Admin confirms it for an employee with a manager",
+			approverID: 2,
+			approver:   []driver.Value{2, "Ada Admin", "ada@example.com", "admin", nil, "", hiredOn},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, sqlMock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("Error creating mock database: %v", err)
+			}
+			defer db.Close()
+
+			mockRepo := new(MockLeaveRequestRepo)
+			mockBalanceRepo := new(MockLeaveBalanceRepo)
+			mockDelegationRepo := new(MockDelegationRepo)
+			mockLeaveTypeRepo := new(MockLeaveTypeRepo)
+			uc := newLeaveRequestUsecase(mockRepo, mockBalanceRepo, new(MockHolidayRepo), mockLeaveTypeRepo, new(MockLeavePolicyRepo), new(MockAutoApprovalRuleRepo), repository.NewUserRepository(db), new(MockApprovalRepo), mockDelegationRepo)
+
+			sqlMock.ExpectQuery(`SELECT u.id`).WithArgs(tt.approverID).
+				WillReturnRows(userRows().AddRow(tt.approver...))
+			mockRepo.On("FindById", 7).
+				Return(&entity.LeaveRequest{ID: 7, UserId: 1, Type: entity.Unpaid, Status: entity.CancellationRequested}, nil).Once()
+			if tt.wantCode != 0 {
+				mockDelegationRepo.On("GetActiveForDelegate", tt.approverID, mock.Anything).Return([]*entity.ApprovalDelegation{}, nil).Once()
+			} else {
+				mockRepo.On("Cancel", 7, tt.approverID).Return(true, nil).Once()
+				mockLeaveTypeRepo.On("FindByCode", entity.Unpaid).Return(unpaidLeave, nil).Once()
+			}
+
+			errResp := uc.ApproveCancellation(context.Background(), 7, tt.approverID, 0)
+
+			if tt.wantCode != 0 {
+				assert.Equal(t, tt.wantCode, errResp.Code)
+				mockRepo.AssertNotCalled(t, "Cancel", 7, tt.approverID)
+			} else {
+				assert.Nil(t, errResp)
+			}
+			mockRepo.AssertExpectations(t)
+			mockLeaveTypeRepo.AssertExpectations(t)
+			mockDelegationRepo.AssertExpectations(t)
+			assert.NoError(t, sqlMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestUpdateLeaveRequest(t *testing.T) {
+	mockRepo := new(MockLeaveRequestRepo)
+	mockBalanceRepo := new(MockLeaveBalanceRepo)
+	mockHolidayRepo := new(MockHolidayRepo)
+	mockApprovalRepo := new(MockApprovalRepo)
+	mockLeaveTypeRepo := new(MockLeaveTypeRepo)
+	mockLeavePolicyRepo := new(MockLeavePolicyRepo)
+	uc := newLeaveRequestUsecase(mockRepo, mockBalanceRepo, mockHolidayRepo, mockLeaveTypeRepo, mockLeavePolicyRepo, new(MockAutoApprovalRuleRepo), newUserRepo(t), mockApprovalRepo, new(MockDelegationRepo))
+	monday := nextMonday()
+
+	req := dto.CreateLeaveRequestRequest{
+		StartDate: monday,
+		EndDate:   monday.AddDate(0, 0, 1),
+		Type:      "annual",
+		Reason:    "Family trip to the mountains",
+		Status:    "draft",
+	}
+
+	tests := []struct {
+		name      string
+		existing  *entity.LeaveRequest
+		setupMock func()
+		wantCode  int
+	}{
+		{
+			name:     "Approved request cannot be edited",
+			existing: &entity.LeaveRequest{ID: 7, UserId: 1, Type: entity.Annual, Status: entity.Approved},
+			setupMock: func() {
+			},
+			wantCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:     "Waiting request is reset to draft",
+			existing: &entity.LeaveRequest{ID: 7, UserId: 1, Type: entity.Annual, Status: entity.WaitingApproval},
+			setupMock: func() {
+				mockHolidayRepo.On("GetHolidaysBetween", mock.Anything, mock.Anything).Return([]*entity.Holiday{}, nil).Once()
+				mockLeaveTypeRepo.On("FindByCode", entity.Annual).Return(annualLeave, nil).Twice()
+				mockLeavePolicyRepo.On("FindApplicable", 1, entity.Annual, mock.Anything).Return(nil, sql.ErrNoRows).Once()
+				mockRepo.On("OverlapApprovedLeaveExists", 1, mock.Anything, mock.Anything).Return(false, nil).Once()
+				mockBalanceRepo.On("LockUser", 1).Return(nil).Once()
+				mockBalanceRepo.On("GetBalance", 1, monday.Year(), entity.Annual, 7).
+					Return(&entity.LeaveBalance{Entitled: 12}, nil).Once()
+				mockRepo.On("Update", mock.Anything, mock.MatchedBy(func(lr *entity.LeaveRequest) bool {
+					return lr.ID == 7 && lr.Status == entity.Draft && lr.WorkingDays == 2
+				}), 1).Return(true, nil).Once()
+				mockApprovalRepo.On("DeleteSteps", 7).Return(nil).Once()
+				mockBalanceRepo.On("ReleaseReservation", 7).Return(nil).Once()
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo.Mock.ExpectedCalls = nil
+			mockBalanceRepo.Mock.ExpectedCalls = nil
+			mockHolidayRepo.Mock.ExpectedCalls = nil
+			mockApprovalRepo.Mock.ExpectedCalls = nil
+			mockLeaveTypeRepo.Mock.ExpectedCalls = nil
+			mockLeavePolicyRepo.Mock.ExpectedCalls = nil
+			mockRepo.On("FindById", 7).Return(tt.existing, nil).Once()
+
+			tt.setupMock()
+
+			res, errResp := uc.UpdateLeaveRequest(context.Background(), 7, &req, 1, 0)
+
+			if tt.wantCode != 0 {
+				assert.Nil(t, res)
+				assert.Equal(t, tt.wantCode, errResp.Code)
+			} else {
+				assert.Nil(t, errResp)
+				assert.Equal(t, string(entity.Draft), res.Status)
+			}
+
+			mockRepo.AssertExpectations(t)
+			mockBalanceRepo.AssertExpectations(t)
+			mockLeaveTypeRepo.AssertExpectations(t)
+			mockLeavePolicyRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestApproveAuthorization(t *testing.T) {
+	managerId := 5
+
+	tests := []struct {
+		name       string
+		approverID int
+		setupMock  func(sqlMock sqlmock.Sqlmock, mockDelegationRepo *MockDelegationRepo)
+		wantCode   int
+	}{
+		{
+			name:       "Requester cannot approve own leave",
+			approverID: 1,
+			setupMock: func(sqlMock sqlmock.Sqlmock, mockDelegationRepo *MockDelegationRepo) {
+			},
+			wantCode: http.StatusForbidden,
+		},
+		{
+			name:       "Admin cannot approve for an employee with a manager",
+			approverID: 2,
+			setupMock: func(sqlMock sqlmock.Sqlmock, mockDelegationRepo *MockDelegationRepo) {
+				sqlMock.ExpectQuery(`SELECT u.id`).WithArgs(2).
+					WillReturnRows(userRows().AddRow(2, "Ada Admin", "ada@example.com", "admin", nil, "", hiredOn))
+				mockDelegationRepo.On("GetActiveForDelegate", 2, mock.Anything).Return([]*entity.ApprovalDelegation{}, nil).Once()
+				sqlMock.ExpectQuery(`WITH RECURSIVE chain`).WithArgs(2, 1).
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+				sqlMock.ExpectQuery(`SELECT u.id`).WithArgs(1).
+					WillReturnRows(userRows().AddRow(1, "Jane Doe", "jane@example.com", "employee", managerId, "", hiredOn))
+			},
+			wantCode: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, sqlMock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("Error creating mock database: %v", err)
+			}
+			defer db.Close()
+
+			mockRepo := new(MockLeaveRequestRepo)
+			mockRepo.On("FindById", 7).
+				Return(&entity.LeaveRequest{ID: 7, UserId: 1, Type: entity.Annual, Status: entity.WaitingApproval}, nil).Once()
+			mockApprovalRepo := new(MockApprovalRepo)
+			mockApprovalRepo.On("GetSteps", 7).
+				Return([]*entity.ApprovalStep{{ID: 70, StepOrder: 1, ApproverKind: entity.ApproverManager, Status: entity.StepPending}}, nil).Once()
+			mockDelegationRepo := new(MockDelegationRepo)
+			tt.setupMock(sqlMock, mockDelegationRepo)
+
+			uc := newLeaveRequestUsecase(mockRepo, new(MockLeaveBalanceRepo), new(MockHolidayRepo), new(MockLeaveTypeRepo), new(MockLeavePolicyRepo), new(MockAutoApprovalRuleRepo), repository.NewUserRepository(db), mockApprovalRepo, mockDelegationRepo)
+
+			_, errResp := uc.Approve(context.Background(), 7, tt.approverID, 0, "")
+
+			assert.NotNil(t, errResp)
+			assert.Equal(t, tt.wantCode, errResp.Code)
+			mockDelegationRepo.AssertExpectations(t)
+			assert.NoError(t, sqlMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestApproveWalksApprovalChain(t *testing.T) {
+	tests := []struct {
+		name       string
+		steps      []*entity.ApprovalStep
+		setupMock  func(mockRepo *MockLeaveRequestRepo, mockBalanceRepo *MockLeaveBalanceRepo, mockLeaveTypeRepo *MockLeaveTypeRepo, mockLeavePolicyRepo *MockLeavePolicyRepo)
+		wantStatus entity.LeaveRequestStatus
+	}{
+		{
+			name: "First of two steps keeps the request waiting",
+			steps: []*entity.ApprovalStep{
+				{ID: 70, StepOrder: 1, ApproverKind: entity.ApproverManager, Status: entity.StepPending},
+				{ID: 71, StepOrder: 2, ApproverKind: entity.ApproverAdmin, Status: entity.StepPending},
+			},
+			setupMock: func(mockRepo *MockLeaveRequestRepo, mockBalanceRepo *MockLeaveBalanceRepo, mockLeaveTypeRepo *MockLeaveTypeRepo, mockLeavePolicyRepo *MockLeavePolicyRepo) {
+			},
+			wantStatus: entity.WaitingApproval,
+		},
+		{
+			name: "Last step approves the request",
+			steps: []*entity.ApprovalStep{
+				{ID: 70, StepOrder: 1, ApproverKind: entity.ApproverManager, Status: entity.StepApproved},
+				{ID: 71, StepOrder: 2, ApproverKind: entity.ApproverAdmin, Status: entity.StepPending},
+			},
+			setupMock: func(mockRepo *MockLeaveRequestRepo, mockBalanceRepo *MockLeaveBalanceRepo, mockLeaveTypeRepo *MockLeaveTypeRepo, mockLeavePolicyRepo *MockLeavePolicyRepo) {
+				mockRepo.On("OverlapApprovedLeaveExists", 1, mock.Anything, mock.Anything).Return(false, nil).Once()
+				mockRepo.On("Approve", 7, 9, "Enjoy").Return(true, nil).Once()
+				mockLeaveTypeRepo.On("FindByCode", entity.Unpaid).Return(unpaidLeave, nil).Twice()
+				mockLeavePolicyRepo.On("FindApplicable", 1, entity.Unpaid, mock.Anything).Return(nil, sql.ErrNoRows).Once()
+			},
+			wantStatus: entity.Approved,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, sqlMock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("Error creating mock database: %v", err)
+			}
+			defer db.Close()
+
+			sqlMock.ExpectQuery(`SELECT u.id`).WithArgs(9).
+				WillReturnRows(userRows().AddRow(9, "Super Admin", "root@example.com", "superadmin", nil, "", hiredOn))
+
+			mockRepo := new(MockLeaveRequestRepo)
+			mockRepo.On("FindById", 7).
+				Return(&entity.LeaveRequest{ID: 7, UserId: 1, Type: entity.Unpaid, Status: entity.WaitingApproval}, nil).Once()
+			mockBalanceRepo := new(MockLeaveBalanceRepo)
+			mockApprovalRepo := new(MockApprovalRepo)
+			mockLeaveTypeRepo := new(MockLeaveTypeRepo)
+			mockLeavePolicyRepo := new(MockLeavePolicyRepo)
+			mockApprovalRepo.On("GetSteps", 7).Return(tt.steps, nil).Once()
+
+			var pending *entity.ApprovalStep
+			for _, step := range tt.steps {
+				if step.Status == entity.StepPending {
+					pending = step
+					break
+				}
+			}
+			mockApprovalRepo.On("DecideStep", pending.ID, 9, (*int)(nil), entity.StepApproved, "Enjoy").Return(true, nil).Once()
+			tt.setupMock(mockRepo, mockBalanceRepo, mockLeaveTypeRepo, mockLeavePolicyRepo)
+
+			uc := newLeaveRequestUsecase(mockRepo, mockBalanceRepo, new(MockHolidayRepo), mockLeaveTypeRepo, mockLeavePolicyRepo, new(MockAutoApprovalRuleRepo), repository.NewUserRepository(db), mockApprovalRepo, new(MockDelegationRepo))
+
+			status, errResp := uc.Approve(context.Background(), 7, 9, 0, " Enjoy ")
+
+			assert.Nil(t, errResp)
+			assert.Equal(t, tt.wantStatus, status)
+			mockRepo.AssertExpectations(t)
+			mockApprovalRepo.AssertExpectations(t)
+			mockLeaveTypeRepo.AssertExpectations(t)
+			mockLeavePolicyRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestApproveAsDelegate(t *testing.T) {
+	managerId := 5
+
+	db, sqlMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating mock database: %v", err)
+	}
+	defer db.Close()
+
+	sqlMock.ExpectQuery(`SELECT u.id`).WithArgs(3).
+		WillReturnRows(userRows().AddRow(3, "Dan Delegate", "dan@example.com", "employee", nil, "", hiredOn))
+	sqlMock.ExpectQuery(`WITH RECURSIVE chain`).WithArgs(3, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	sqlMock.ExpectQuery(`SELECT u.id`).WithArgs(managerId).
+		WillReturnRows(userRows().AddRow(managerId, "Mia Manager", "mia@example.com", "employee", nil, "", hiredOn))
+	sqlMock.ExpectQuery(`WITH RECURSIVE chain`).WithArgs(managerId, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+
+	mockRepo := new(MockLeaveRequestRepo)
+	mockRepo.On("FindById", 7).
+		Return(&entity.LeaveRequest{ID: 7, UserId: 1, Type: entity.Unpaid, Status: entity.WaitingApproval}, nil).Once()
+	mockRepo.On("OverlapApprovedLeaveExists", 1, mock.Anything, mock.Anything).Return(false, nil).Once()
+	mockRepo.On("Approve", 7, 3, "").Return(true, nil).Once()
+	mockApprovalRepo := new(MockApprovalRepo)
+	mockApprovalRepo.On("GetSteps", 7).
+		Return([]*entity.ApprovalStep{{ID: 70, StepOrder: 1, ApproverKind: entity.ApproverManager, Status: entity.StepPending}}, nil).Once()
+	mockApprovalRepo.On("DecideStep", 70, 3, &managerId, entity.StepApproved, "").Return(true, nil).Once()
+	mockDelegationRepo := new(MockDelegationRepo)
+	mockDelegationRepo.On("GetActiveForDelegate", 3, mock.Anything).
+		Return([]*entity.ApprovalDelegation{{ID: 1, DelegatorId: managerId, DelegateId: 3}}, nil).Once()
+	mockLeaveTypeRepo := new(MockLeaveTypeRepo)
+	mockLeavePolicyRepo := new(MockLeavePolicyRepo)
+	mockLeaveTypeRepo.On("FindByCode", entity.Unpaid).Return(unpaidLeave, nil).Twice()
+	mockLeavePolicyRepo.On("FindApplicable", 1, entity.Unpaid, mock.Anything).Return(nil, sql.ErrNoRows).Once()
+
+	uc := newLeaveRequestUsecase(mockRepo, new(MockLeaveBalanceRepo), new(MockHolidayRepo), mockLeaveTypeRepo, mockLeavePolicyRepo, new(MockAutoApprovalRuleRepo), repository.NewUserRepository(db), mockApprovalRepo, mockDelegationRepo)
+
+	status, errResp := uc.Approve(context.Background(), 7, 3, 0, "")
+
+	assert.Nil(t, errResp)
+	assert.Equal(t, entity.Approved, status)
+	mockRepo.AssertExpectations(t)
+	mockApprovalRepo.AssertExpectations(t)
+	mockLeaveTypeRepo.AssertExpectations(t)
+	mockLeavePolicyRepo.AssertExpectations(t)
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+}
+
+func TestApproveConcurrency(t *testing.T) {
+	tests := []struct {
+		name      string
+		version   int
+		setupMock func(mockRepo *MockLeaveRequestRepo, mockApprovalRepo *MockApprovalRepo, mockLeaveTypeRepo *MockLeaveTypeRepo, mockLeavePolicyRepo *MockLeavePolicyRepo, sqlMock sqlmock.Sqlmock)
+		wantCode  int
+	}{
+		{
+			name:    "Stale If-Match version",
+			version: 3,
+			setupMock: func(mockRepo *MockLeaveRequestRepo, mockApprovalRepo *MockApprovalRepo, mockLeaveTypeRepo *MockLeaveTypeRepo, mockLeavePolicyRepo *MockLeavePolicyRepo, sqlMock sqlmock.Sqlmock) {
+			},
+			wantCode: http.StatusPreconditionFailed,
+		},
+		{
+			name:    "Request changed while approving",
+			version: 4,
+			setupMock: func(mockRepo *MockLeaveRequestRepo, mockApprovalRepo *MockApprovalRepo, mockLeaveTypeRepo *MockLeaveTypeRepo, mockLeavePolicyRepo *MockLeavePolicyRepo, sqlMock sqlmock.Sqlmock) {
+				sqlMock.ExpectQuery(`SELECT u.id`).WithArgs(9).
+					WillReturnRows(userRows().AddRow(9, "Super Admin", "root@example.com", "superadmin", nil, "", hiredOn))
+				mockApprovalRepo.On("GetSteps", 7).
+					Return([]*entity.ApprovalStep{{ID: 70, StepOrder: 1, ApproverKind: entity.ApproverManager, Status: entity.StepPending}}, nil).Once()
+				mockRepo.On("OverlapApprovedLeaveExists", 1, mock.Anything, mock.Anything).Return(false, nil).Once()
+				mockApprovalRepo.On("DecideStep", 70, 9, (*int)(nil), entity.StepApproved, "").Return(true, nil).Once()
+				mockLeaveTypeRepo.On("FindByCode", entity.Unpaid).Return(unpaidLeave, nil).Once()
+				mockLeavePolicyRepo.On("FindApplicable", 1, entity.Unpaid, mock.Anything).Return(nil, sql.ErrNoRows).Once()
+				mockRepo.On("Approve", 7, 9, "").Return(false, nil).Once()
+			},
+			wantCode: http.StatusConflict,
+		},
+		{
+			name:    "Overlapping request approved at the same time",
+			version: 4,
+			setupMock: func(mockRepo *MockLeaveRequestRepo, mockApprovalRepo *MockApprovalRepo, mockLeaveTypeRepo *MockLeaveTypeRepo, mockLeavePolicyRepo *MockLeavePolicyRepo, sqlMock sqlmock.Sqlmock) {
+				sqlMock.ExpectQuery(`SELECT u.id`).WithArgs(9).
+					WillReturnRows(userRows().AddRow(9, "Super Admin", "root@example.com", "superadmin", nil, "", hiredOn))
+				mockApprovalRepo.On("GetSteps", 7).
+					Return([]*entity.ApprovalStep{{ID: 70, StepOrder: 1, ApproverKind: entity.ApproverManager, Status: entity.StepPending}}, nil).Once()
+				mockRepo.On("OverlapApprovedLeaveExists", 1, mock.Anything, mock.Anything).Return(false, nil).Once()
+				mockApprovalRepo.On("DecideStep", 70, 9, (*int)(nil), entity.StepApproved, "").Return(true, nil).Once()
+				mockLeaveTypeRepo.On("FindByCode", entity.Unpaid).Return(unpaidLeave, nil).Once()
+				mockLeavePolicyRepo.On("FindApplicable", 1, entity.Unpaid, mock.Anything).Return(nil, sql.ErrNoRows).Once()
+				mockRepo.On("Approve", 7, 9, "").Return(false, &pq.Error{Code: "23P01"}).Once()
+			},
+			wantCode: http.StatusConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, sqlMock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("Error creating mock database: %v", err)
+			}
+			defer db.Close()
+
+			mockRepo := new(MockLeaveRequestRepo)
+			mockRepo.On("FindById", 7).
+				Return(&entity.LeaveRequest{ID: 7, UserId: 1, Type: entity.Unpaid, Status: entity.WaitingApproval, Version: 4}, nil).Once()
+			mockApprovalRepo := new(MockApprovalRepo)
+			mockLeaveTypeRepo := new(MockLeaveTypeRepo)
+			mockLeavePolicyRepo := new(MockLeavePolicyRepo)
+			tt.setupMock(mockRepo, mockApprovalRepo, mockLeaveTypeRepo, mockLeavePolicyRepo, sqlMock)
+
+			uc := newLeaveRequestUsecase(mockRepo, new(MockLeaveBalanceRepo), new(MockHolidayRepo), mockLeaveTypeRepo, mockLeavePolicyRepo, new(MockAutoApprovalRuleRepo), repository.NewUserRepository(db), mockApprovalRepo, new(MockDelegationRepo))
+
+			_, errResp := uc.Approve(context.Background(), 7, 9, tt.version, "")
+
+			assert.NotNil(t, errResp)
+			assert.Equal(t, tt.wantCode, errResp.Code)
+			mockRepo.AssertExpectations(t)
+			mockApprovalRepo.AssertExpectations(t)
+			mockLeaveTypeRepo.AssertExpectations(t)
+			mockLeavePolicyRepo.AssertExpectations(t)
+			assert.NoError(t, sqlMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestRejectRecordsReason(t *testing.T) {
+	tests := []struct {
+		name      string
+		reason    string
+		setupMock func(mockRepo *MockLeaveRequestRepo, mockApprovalRepo *MockApprovalRepo, mockLeaveTypeRepo *MockLeaveTypeRepo, sqlMock sqlmock.Sqlmock)
+		wantCode  int
+	}{
+		{
+			name:   "Blank reason is refused",
+			reason: "   ",
+			setupMock: func(mockRepo *MockLeaveRequestRepo, mockApprovalRepo *MockApprovalRepo, mockLeaveTypeRepo *MockLeaveTypeRepo, sqlMock sqlmock.Sqlmock) {
+			},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:   "Reason is kept on the step and the request",
+			reason: " Team is short-staffed that week ",
+			setupMock: func(mockRepo *MockLeaveRequestRepo, mockApprovalRepo *MockApprovalRepo, mockLeaveTypeRepo *MockLeaveTypeRepo, sqlMock sqlmock.Sqlmock) {
+				sqlMock.ExpectQuery(`SELECT u.id`).WithArgs(9).
+					WillReturnRows(userRows().AddRow(9, "Super Admin", "root@example.com", "superadmin", nil, "", hiredOn))
+				mockRepo.On("FindById", 7).
+					Return(&entity.LeaveRequest{ID: 7, UserId: 1, Type: entity.Unpaid, Status: entity.WaitingApproval}, nil).Once()
+				mockApprovalRepo.On("GetSteps", 7).
+					Return([]*entity.ApprovalStep{{ID: 70, StepOrder: 1, ApproverKind: entity.ApproverManager, Status: entity.StepPending}}, nil).Once()
+				mockApprovalRepo.On("DecideStep", 70, 9, (*int)(nil), entity.StepRejected, "Team is short-staffed that week").Return(true, nil).Once()
+				mockRepo.On("Reject", 7, 9, "Team is short-staffed that week").Return(true, nil).Once()
+				mockApprovalRepo.On("SkipPendingSteps", 7).Return(nil).Once()
+				mockLeaveTypeRepo.On("FindByCode", entity.Unpaid).Return(unpaidLeave, nil).Once()
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, sqlMock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("Error creating mock database: %v", err)
+			}
+			defer db.Close()
+
+			mockRepo := new(MockLeaveRequestRepo)
+			mockApprovalRepo := new(MockApprovalRepo)
+			mockLeaveTypeRepo := new(MockLeaveTypeRepo)
+			tt.setupMock(mockRepo, mockApprovalRepo, mockLeaveTypeRepo, sqlMock)
+
+			uc := newLeaveRequestUsecase(mockRepo, new(MockLeaveBalanceRepo), new(MockHolidayRepo), mockLeaveTypeRepo, new(MockLeavePolicyRepo), new(MockAutoApprovalRuleRepo), repository.NewUserRepository(db), mockApprovalRepo, new(MockDelegationRepo))
+
+			errResp := uc.Reject(context.Background(), 7, 9, 0, tt.reason)
+
+			if tt.wantCode != 0 {
+				assert.NotNil(t, errResp)
+				assert.Equal(t, tt.wantCode, errResp.Code)
+			} else {
+				assert.Nil(t, errResp)
+			}
+			mockRepo.AssertExpectations(t)
+			mockApprovalRepo.AssertExpectations(t)
+			mockLeaveTypeRepo.AssertExpectations(t)
+			assert.NoError(t, sqlMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestGetLeaveRequestHistoryAccess(t *testing.T) {
+	tests := []struct {
+		name      string
+		userID    int
+		deleted   bool
+		setupMock func(sqlMock sqlmock.Sqlmock)
+		wantCode  int
+	}{
+		{
+			name:   "Owner reads the history",
+			userID: 1,
+			setupMock: func(sqlMock sqlmock.Sqlmock) {
+			},
+		},
+		{
+			name:   "Admin reads the history",
+			userID: 2,
+			setupMock: func(sqlMock sqlmock.Sqlmock) {
+				sqlMock.ExpectQuery(`SELECT u.id`).WithArgs(2).
+					WillReturnRows(userRows().AddRow(2, "Ada Admin", "ada@example.com", "admin", nil, "", hiredOn))
+			},
+		},
+		{
+			name:   "Other employees are refused",
+			userID: 3,
+			setupMock: func(sqlMock sqlmock.Sqlmock) {
+				sqlMock.ExpectQuery(`SELECT u.id`).WithArgs(3).
+					WillReturnRows(userRows().AddRow(3, "Bob Builder", "bob@example.com", "employee", nil, "", hiredOn))
+			},
+			wantCode: http.StatusForbidden,
+		},
+		{
+			name:    "Admin reads the history of a deleted request",
+			userID:  2,
+			deleted: true,
+			setupMock: func(sqlMock sqlmock.Sqlmock) {
+				sqlMock.ExpectQuery(`SELECT u.id`).WithArgs(2).
+					WillReturnRows(userRows().AddRow(2, "Ada Admin", "ada@example.com", "admin", nil, "", hiredOn))
+			},
+		},
+		{
+			name:    "Owner cannot read the history of a deleted request",
+			userID:  1,
+			deleted: true,
+			setupMock: func(sqlMock sqlmock.Sqlmock) {
+				sqlMock.ExpectQuery(`SELECT u.id`).WithArgs(1).
+					WillReturnRows(userRows().AddRow(1, "Jane Doe", "jane@example.com", "employee", nil, "", hiredOn))
+			},
+			wantCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, sqlMock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("Error creating mock database: %v", err)
+			}
+			defer db.Close()
+
+			mockRepo := new(MockLeaveRequestRepo)
+			if tt.deleted {
+				mockRepo.On("FindById", 7).Return(nil, sql.ErrNoRows).Once()
+			} else {
+				mockRepo.On("FindById", 7).
+					Return(&entity.LeaveRequest{ID: 7, UserId: 1, Type: entity.Annual, Status: entity.Approved}, nil).Once()
+			}
+			if tt.wantCode == 0 {
+				mockRepo.On("GetEvents", 7).
+					Return([]*entity.LeaveRequestEvent{
+						{ID: 1, LeaveRequestId: 7, Action: entity.ActionCreate, NewStatus: entity.WaitingApproval},
+						{ID: 2, LeaveRequestId: 7, Action: entity.ActionApprove, PreviousStatus: entity.WaitingApproval, NewStatus: entity.Approved},
+					}, nil).Once()
+			}
+			tt.setupMock(sqlMock)
+
+			uc := newLeaveRequestUsecase(mockRepo, new(MockLeaveBalanceRepo), new(MockHolidayRepo), new(MockLeaveTypeRepo), new(MockLeavePolicyRepo), new(MockAutoApprovalRuleRepo), repository.NewUserRepository(db), new(MockApprovalRepo), new(MockDelegationRepo))
+
+			res, errResp := uc.GetLeaveRequestHistory(context.Background(), 7, tt.userID)
+
+			if tt.wantCode != 0 {
+				assert.Nil(t, res)
+				assert.Equal(t, tt.wantCode, errResp.Code)
+			} else {
+				assert.Nil(t, errResp)
+				assert.Len(t, res.Events, 2)
+				assert.Equal(t, "approve", res.Events[1].Action)
+			}
+			mockRepo.AssertExpectations(t)
+			assert.NoError(t, sqlMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestSubmitRollsBackFailedReservation(t *testing.T) {
+	mockRepo := new(MockLeaveRequestRepo)
+	mockBalanceRepo := new(MockLeaveBalanceRepo)
+	mockLeaveTypeRepo := new(MockLeaveTypeRepo)
+	mockLeavePolicyRepo := new(MockLeavePolicyRepo)
+	mockRuleRepo := new(MockAutoApprovalRuleRepo)
+	unitOfWork := &MockUnitOfWork{repos: &repository.Repositories{LeaveRequest: mockRepo, LeaveBalance: mockBalanceRepo}}
+	uc := usecase.NewLeaveRequestUsecase(mockRepo, mockBalanceRepo, new(MockHolidayRepo), mockLeaveTypeRepo, mockLeavePolicyRepo, mockRuleRepo, newUserRepo(t), new(MockApprovalRepo), new(MockDelegationRepo), new(MockAttachmentRepo), new(MockStorage), unitOfWork)
+	monday := nextMonday()
+
+	mockRepo.On("FindById", 7).
+		Return(&entity.LeaveRequest{ID: 7, UserId: 1, StartDate: monday, Type: entity.Annual, Status: entity.Draft, WorkingDays: 2}, nil).Once()
+	mockLeaveTypeRepo.On("FindByCode", entity.Annual).Return(annualLeave, nil).Twice()
+	mockLeavePolicyRepo.On("FindApplicable", 1, entity.Annual, mock.Anything).Return(nil, sql.ErrNoRows).Once()
+	mockRuleRepo.On("GetActive").Return([]*entity.AutoApprovalRule{}, nil).Once()
+	mockBalanceRepo.On("LockUser", 1).Return(nil).Once()
+	mockBalanceRepo.On("GetBalance", 1, monday.Year(), entity.Annual, 7).
+		Return(&entity.LeaveBalance{Entitled: 12}, nil).Once()
+	mockRepo.On("Submit", 7, 1).Return(true, nil).Once()
+	mockBalanceRepo.On("AddEntry", mock.Anything).Return(errors.New("connection reset")).Once()
+
+	_, errResp := uc.Submit(context.Background(), 7, 1, 0)
+
+	assert.NotNil(t, errResp)
+	assert.Equal(t, http.StatusInternalServerError, errResp.Code)
+	assert.True(t, unitOfWork.rolledBack, "The submission should be rolled back with the failed reservation")
+	mockRepo.AssertExpectations(t)
+	mockBalanceRepo.AssertExpectations(t)
+	mockLeaveTypeRepo.AssertExpectations(t)
+	mockLeavePolicyRepo.AssertExpectations(t)
+	mockRuleRepo.AssertExpectations(t)
+}
+
+func TestDeleteLeaveRequest(t *testing.T) {
+	monday := nextMonday()
+
+	tests := []struct {
+		name           string
+		status         entity.LeaveRequestStatus
+		deleteErr      error
+		wantCode       int
+		wantRolledBack bool
+	}{
+		{name: "Draft", status: entity.Draft},
+		{name: "Waiting request releases its reservation and steps", status: entity.WaitingApproval},
+		{name: "Failed delete rolls back the release", status: entity.WaitingApproval, deleteErr: errors.New("connection reset"), wantCode: http.StatusInternalServerError, wantRolledBack: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockLeaveRequestRepo)
+			mockBalanceRepo := new(MockLeaveBalanceRepo)
+			mockApprovalRepo := new(MockApprovalRepo)
+			mockAttachmentRepo := new(MockAttachmentRepo)
+			mockStorage := new(MockStorage)
+			mockLeaveTypeRepo := new(MockLeaveTypeRepo)
+			unitOfWork := &MockUnitOfWork{repos: &repository.Repositories{LeaveRequest: mockRepo, LeaveBalance: mockBalanceRepo, Approval: mockApprovalRepo}}
+			uc := usecase.NewLeaveRequestUsecase(mockRepo, mockBalanceRepo, new(MockHolidayRepo), mockLeaveTypeRepo, new(MockLeavePolicyRepo), new(MockAutoApprovalRuleRepo), newUserRepo(t), mockApprovalRepo, new(MockDelegationRepo), mockAttachmentRepo, mockStorage, unitOfWork)
+
+			leaveRequest := &entity.LeaveRequest{ID: 7, UserId: 1, StartDate: monday, EndDate: monday, Type: entity.Annual, Status: tt.status, WorkingDays: 1}
+			attachment := &entity.LeaveRequestAttachment{ID: 3, LeaveRequestId: 7, StorageKey: "leave-requests/7/note.pdf"}
+			mockRepo.On("FindById", 7).Return(leaveRequest, nil).Once()
+			mockAttachmentRepo.On("GetByLeaveRequest", 7).Return([]*entity.LeaveRequestAttachment{attachment}, nil).Once()
+			if tt.status == entity.WaitingApproval {
+				mockApprovalRepo.On("DeleteSteps", 7).Return(nil).Once()
+				mockLeaveTypeRepo.On("FindByCode", entity.Annual).Return(annualLeave, nil).Once()
+				mockBalanceRepo.On("ReleaseReservation", 7).Return(nil).Once()
+			}
+			mockRepo.On("Delete", leaveRequest, 1).Return(tt.deleteErr == nil, tt.deleteErr).Once()
+			if tt.deleteErr == nil {
+				mockStorage.On("Delete", attachment.StorageKey).Return(nil).Once()
+			}
+
+			errResp := uc.DeleteLeaveRequest(context.Background(), 7, 1, 0)
+
+			if tt.wantCode != 0 {
+				assert.Equal(t, tt.wantCode, errResp.Code)
+				mockStorage.AssertNotCalled(t, "Delete", mock.Anything)
+			} else {
+				assert.Nil(t, errResp)
+			}
+			assert.Equal(t, tt.wantRolledBack, unitOfWork.rolledBack)
+			mockRepo.AssertExpectations(t)
+			mockBalanceRepo.AssertExpectations(t)
+			mockApprovalRepo.AssertExpectations(t)
+			mockAttachmentRepo.AssertExpectations(t)
+			mockStorage.AssertExpectations(t)
+			mockLeaveTypeRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetLeaveRequestActions(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      entity.LeaveRequestStatus
+		userID      int
+		setupMock   func(mockApprovalRepo *MockApprovalRepo, sqlMock sqlmock.Sqlmock)
+		wantActions []string
+	}{
+		{
+			name:        "Owner of a draft",
+			status:      entity.Draft,
+			userID:      1,
+			setupMock:   func(mockApprovalRepo *MockApprovalRepo, sqlMock sqlmock.Sqlmock) {},
+			wantActions: []string{"edit", "delete", "submit", "cancel"},
+		},
+		{
+			name:        "Owner of approved leave",
+			status:      entity.Approved,
+			userID:      1,
+			setupMock:   func(mockApprovalRepo *MockApprovalRepo, sqlMock sqlmock.Sqlmock) {},
+			wantActions: []string{"cancel"},
+		},
+		{
+			name:        "Owner of a rejected request",
+			status:      entity.Rejected,
+			userID:      1,
+			setupMock:   func(mockApprovalRepo *MockApprovalRepo, sqlMock sqlmock.Sqlmock) {},
+			wantActions: []string{},
+		},
+		{
+			name:        "Someone else's draft",
+			status:      entity.Draft,
+			userID:      3,
+			setupMock:   func(mockApprovalRepo *MockApprovalRepo, sqlMock sqlmock.Sqlmock) {},
+			wantActions: []string{},
+		},
+		{
+			name:   "Approver of a waiting request",
+			status: entity.WaitingApproval,
+			userID: 9,
+			setupMock: func(mockApprovalRepo *MockApprovalRepo, sqlMock sqlmock.Sqlmock) {
+				mockApprovalRepo.On("GetSteps", 7).
+					Return([]*entity.ApprovalStep{{ID: 70, StepOrder: 1, ApproverKind: entity.ApproverManager, Status: entity.StepPending}}, nil).Once()
+				sqlMock.ExpectQuery(`SELECT u.id`).WithArgs(9).
+					WillReturnRows(userRows().AddRow(9, "Super Admin", "root@example.com", "superadmin", nil, "", hiredOn))
+			},
+			wantActions: []string{"approve", "reject"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, sqlMock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("Error creating mock database: %v", err)
+			}
+			defer db.Close()
+
+			mockRepo := new(MockLeaveRequestRepo)
+			mockRepo.On("FindById", 7).
+				Return(&entity.LeaveRequest{ID: 7, UserId: 1, Type: entity.Annual, Status: tt.status, Version: 2}, nil).Once()
+			mockApprovalRepo := new(MockApprovalRepo)
+			tt.setupMock(mockApprovalRepo, sqlMock)
+
+			uc := newLeaveRequestUsecase(mockRepo, new(MockLeaveBalanceRepo), new(MockHolidayRepo), new(MockLeaveTypeRepo), new(MockLeavePolicyRepo), new(MockAutoApprovalRuleRepo), repository.NewUserRepository(db), mockApprovalRepo, new(MockDelegationRepo))
+
+			res, errResp := uc.GetLeaveRequestActions(context.Background(), 7, tt.userID)
+
+			assert.Nil(t, errResp)
+			assert.Equal(t, tt.wantActions, res.Actions)
+			assert.Equal(t, 2, res.Version)
+			mockApprovalRepo.AssertExpectations(t)
+			assert.NoError(t, sqlMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestRejectDecidedRequest(t *testing.T) {
+	for _, status := range []entity.LeaveRequestStatus{entity.Approved, entity.Rejected} {
+		t.Run(string(status), func(t *testing.T) {
+			mockRepo := new(MockLeaveRequestRepo)
+			mockRepo.On("FindById", 7).
+				Return(&entity.LeaveRequest{ID: 7, UserId: 1, Type: entity.Annual, Status: status}, nil).Once()
+
+			uc := newLeaveRequestUsecase(mockRepo, new(MockLeaveBalanceRepo), new(MockHolidayRepo), new(MockLeaveTypeRepo), new(MockLeavePolicyRepo), new(MockAutoApprovalRuleRepo), newUserRepo(t), new(MockApprovalRepo), new(MockDelegationRepo))
+
+			errResp := uc.Reject(context.Background(), 7, 9, 0, "Too late")
+
+			assert.NotNil(t, errResp)
+			assert.Equal(t, http.StatusUnprocessableEntity, errResp.Code)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestBulkDecide(t *testing.T) {
+	db, sqlMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating mock database: %v", err)
+	}
+	defer db.Close()
+
+	mockRepo := new(MockLeaveRequestRepo)
+	mockApprovalRepo := new(MockApprovalRepo)
+	mockLeaveTypeRepo := new(MockLeaveTypeRepo)
+	mockLeavePolicyRepo := new(MockLeavePolicyRepo)
+	uc := newLeaveRequestUsecase(mockRepo, new(MockLeaveBalanceRepo), new(MockHolidayRepo), mockLeaveTypeRepo, mockLeavePolicyRepo, new(MockAutoApprovalRuleRepo), repository.NewUserRepository(db), mockApprovalRepo, new(MockDelegationRepo))
+
+	for _, id := range []int{7, 9} {
+		mockRepo.On("FindById", id).
+			Return(&entity.LeaveRequest{ID: id, UserId: 1, Type: entity.Unpaid, Status: entity.WaitingApproval}, nil).Once()
+		mockApprovalRepo.On("GetSteps", id).
+			Return([]*entity.ApprovalStep{{ID: id * 10, StepOrder: 1, ApproverKind: entity.ApproverManager, Status: entity.StepPending}}, nil).Once()
+		sqlMock.ExpectQuery(`SELECT u.id`).WithArgs(9).
+			WillReturnRows(userRows().AddRow(9, "Super Admin", "root@example.com", "superadmin", nil, "", hiredOn))
+	}
+	mockRepo.On("FindById", 8).Return(nil, sql.ErrNoRows).Once()
+	mockRepo.On("OverlapApprovedLeaveExists", 1, mock.Anything, mock.Anything).Return(false, nil).Once()
+	mockApprovalRepo.On("DecideStep", 70, 9, (*int)(nil), entity.StepApproved, "Enjoy").Return(true, nil).Once()
+	mockRepo.On("Approve", 7, 9, "Enjoy").Return(true, nil).Once()
+	mockLeaveTypeRepo.On("FindByCode", entity.Unpaid).Return(unpaidLeave, nil).Twice()
+	mockLeavePolicyRepo.On("FindApplicable", 1, entity.Unpaid, mock.Anything).Return(nil, sql.ErrNoRows).Once()
+	mockRepo.On("OverlapApprovedLeaveExists", 1, mock.Anything, mock.Anything).Return(true, nil).Once()
+
+	response, errResp := uc.BulkDecide(context.Background(), 9, &dto.BulkDecisionRequest{Ids: []int{7, 8, 7, 9}, Decision: "approve", Comment: " Enjoy "})
+
+	assert.Nil(t, errResp)
+	assert.Equal(t, 1, response.Succeeded)
+	assert.Equal(t, 2, response.Failed)
+	assert.Len(t, response.Results, 3)
+	assert.Equal(t, &dto.BulkDecisionResult{ID: 7, Result: dto.BulkDecisionOK, Status: "approved"}, response.Results[0])
+	assert.Equal(t, dto.BulkDecisionNotFound, response.Results[1].Result)
+	assert.Equal(t, dto.BulkDecisionOverlap, response.Results[2].Result)
+	mockRepo.AssertExpectations(t)
+	mockApprovalRepo.AssertExpectations(t)
+	mockLeaveTypeRepo.AssertExpectations(t)
+	mockLeavePolicyRepo.AssertExpectations(t)
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+
+	response, errResp = uc.BulkDecide(context.Background(), 9, &dto.BulkDecisionRequest{Ids: []int{7}, Decision: "reject", Comment: " "})
+
+	assert.Nil(t, response)
+	assert.Equal(t, http.StatusBadRequest, errResp.Code)
+}
+
+func TestRejectStartsMissingApprovalChainInTransaction(t *testing.T) {
+	tests := []struct {
+		name           string
+		rejectErr      error
+		wantCode       int
+		wantRolledBack bool
+	}{
+		{name: "Chain is created with the decision"},
+		{name: "Failed decision rolls back the chain", rejectErr: errors.New("connection reset"), wantCode: http.StatusInternalServerError, wantRolledBack: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, sqlMock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("Error creating mock database: %v", err)
+			}
+			defer db.Close()
+			userRepo := repository.NewUserRepository(db)
+
+			mockRepo := new(MockLeaveRequestRepo)
+			mockApprovalRepo := new(MockApprovalRepo)
+			txApprovalRepo := new(MockApprovalRepo)
+			mockLeaveTypeRepo := new(MockLeaveTypeRepo)
+			unitOfWork := &MockUnitOfWork{repos: &repository.Repositories{User: userRepo, LeaveRequest: mockRepo, Approval: txApprovalRepo}}
+			uc := usecase.NewLeaveRequestUsecase(mockRepo, new(MockLeaveBalanceRepo), new(MockHolidayRepo), mockLeaveTypeRepo, new(MockLeavePolicyRepo), new(MockAutoApprovalRuleRepo), userRepo, mockApprovalRepo, new(MockDelegationRepo), new(MockAttachmentRepo), new(MockStorage), unitOfWork)
+
+			sqlMock.ExpectQuery(`SELECT u.id`).WithArgs(9).
+				WillReturnRows(userRows().AddRow(9, "Super Admin", "root@example.com", "superadmin", nil, "", hiredOn))
+			mockRepo.On("FindById", 7).
+				Return(&entity.LeaveRequest{ID: 7, UserId: 1, Type: entity.Unpaid, Status: entity.WaitingApproval, WorkingDays: 1}, nil).Once()
+			mockApprovalRepo.On("GetSteps", 7).Return([]*entity.ApprovalStep{}, nil).Once()
+			mockApprovalRepo.On("FindChainFor", entity.Unpaid, 1.0).Return(nil, sql.ErrNoRows).Once()
+			txApprovalRepo.On("FindChainFor", entity.Unpaid, 1.0).Return(nil, sql.ErrNoRows).Once()
+			txApprovalRepo.On("CreateSteps", 7, entity.DefaultApprovalSteps).Return(nil).Once()
+			txApprovalRepo.On("GetSteps", 7).
+				Return([]*entity.ApprovalStep{{ID: 70, StepOrder: 1, ApproverKind: entity.ApproverManager, Status: entity.StepPending}}, nil).Once()
+			txApprovalRepo.On("DecideStep", 70, 9, (*int)(nil), entity.StepRejected, "Team is short-staffed").Return(true, nil).Once()
+			mockRepo.On("Reject", 7, 9, "Team is short-staffed").Return(tt.rejectErr == nil, tt.rejectErr).Once()
+			if tt.rejectErr == nil {
+				txApprovalRepo.On("SkipPendingSteps", 7).Return(nil).Once()
+				mockLeaveTypeRepo.On("FindByCode", entity.Unpaid).Return(unpaidLeave, nil).Once()
+			}
+
+			errResp := uc.Reject(context.Background(), 7, 9, 0, "Team is short-staffed")
+
+			if tt.wantCode != 0 {
+				assert.Equal(t, tt.wantCode, errResp.Code)
+			} else {
+				assert.Nil(t, errResp)
+			}
+			assert.Equal(t, tt.wantRolledBack, unitOfWork.rolledBack)
+			mockApprovalRepo.AssertNotCalled(t, "CreateSteps", mock.Anything, mock.Anything)
+			mockRepo.AssertExpectations(t)
+			mockApprovalRepo.AssertExpectations(t)
+			txApprovalRepo.AssertExpectations(t)
+			mockLeaveTypeRepo.AssertExpectations(t)
+			assert.NoError(t, sqlMock.ExpectationsWereMet())
+		})
+	}
+}
+
+// MockUnitOfWork hands the usecase the same repositories it was built with, outside any
+// transaction.
+type MockUnitOfWork struct {
+	repos      *repository.Repositories
+	rolledBack bool
+}
+
+func (m *MockUnitOfWork) Do(ctx context.Context, fn func(repos *repository.Repositories) error) error {
+	err := fn(m.repos)
+	m.rolledBack = err != nil
+	return err
+}
+
+func newLeaveRequestUsecase(leaveRequestRepo repository.LeaveRequestRepository, leaveBalanceRepo repository.LeaveBalanceRepository, holidayRepo repository.HolidayRepository, leaveTypeRepo repository.LeaveTypeRepository, leavePolicyRepo repository.LeavePolicyRepository, autoApprovalRuleRepo repository.AutoApprovalRuleRepository, userRepo *repository.User, approvalRepo repository.ApprovalRepository, delegationRepo repository.DelegationRepository) *usecase.LeaveRequest {
+	unitOfWork := &MockUnitOfWork{repos: &repository.Repositories{
+		User:         userRepo,
+		LeaveRequest: leaveRequestRepo,
+		LeaveBalance: leaveBalanceRepo,
+		Approval:     approvalRepo,
+	}}
+
+	return usecase.NewLeaveRequestUsecase(leaveRequestRepo, leaveBalanceRepo, holidayRepo, leaveTypeRepo, leavePolicyRepo, autoApprovalRuleRepo, userRepo, approvalRepo, delegationRepo, new(MockAttachmentRepo), new(MockStorage), unitOfWork)
+}
+
+// hiredOn is the hire date of the users the tests put in userRows.
+var hiredOn = time.Date(2020, time.January, 6, 0, 0, 0, 0, time.UTC)
+
+func userRows() *sqlmock.Rows {
+	return sqlmock.NewRows([]string{"id", "full_name", "email", "role", "manager_id", "department", "hire_date"})
+}
+
+// newUserRepo returns a user repository for tests that never reach the users table.
+func newUserRepo(t *testing.T) *repository.User {
+	db, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating mock database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return repository.NewUserRepository(db)
+}
+
+func TestExportLeaveRequests(t *testing.T) {
+	decidedAt := time.Date(2026, time.February, 20, 9, 30, 0, 0, time.UTC)
+	fullDays := &entity.LeaveRequestExport{
+		LeaveRequest: entity.LeaveRequest{ID: 4, Type: entity.Unpaid, Status: entity.Approved, StartDate: time.Date(2026, time.March, 2, 0, 0, 0, 0, time.UTC), EndDate: time.Date(2026, time.March, 3, 0, 0, 0, 0, time.UTC), WorkingDays: 2, Reason: "=HYPERLINK(\"x\")", DecidedAt: &decidedAt},
+		FullName:     "Alice",
+		Email:        "alice@example.com",
+	}
+	fullDays.SetDayPeriod(entity.SessionAM, entity.SessionPM)
+	hourly := &entity.LeaveRequestExport{
+		LeaveRequest: entity.LeaveRequest{ID: 5, Type: entity.Annual, Status: entity.Approved, StartDate: time.Date(2026, time.March, 5, 0, 0, 0, 0, time.UTC), WorkingDays: 0.25, Reason: "Dentist, downtown"},
+		FullName:     "Bob",
+		Email:        "bob@example.com",
+	}
+	hourly.SetHourPeriod(9*time.Hour, 11*time.Hour)
+
+	t.Run("CSV", func(t *testing.T) {
+		mockRepo := new(MockLeaveRequestRepo)
+		uc := newLeaveRequestUsecase(mockRepo, new(MockLeaveBalanceRepo), new(MockHolidayRepo), new(MockLeaveTypeRepo), new(MockLeavePolicyRepo), new(MockAutoApprovalRuleRepo), newUserRepo(t), new(MockApprovalRepo), new(MockDelegationRepo))
+		filter := entity.LeaveRequestFilter{Status: "approved"}
+		mockRepo.On("ExportLeaveRequests", "start_date", "DESC", "", filter).
+			Return([]*entity.LeaveRequestExport{fullDays, hourly}, nil).Once()
+
+		var buf bytes.Buffer
+		errResp := uc.ExportLeaveRequests(context.Background(), &buf, util.ExportCSV, "startDate", "desc", "", filter)
+
+		assert.Nil(t, errResp)
+		assert.Equal(t, strings.Join([]string{
+			"ID,Employee,Email,Type,Status,Start Date,End Date,Start Session,End Session,Hours,Working Days,Reason,Decided At",
+			`4,Alice,alice@example.com,unpaid,approved,2026-03-02,2026-03-03,am,pm,,2,"'=HYPERLINK(""x"")",2026-02-20T09:30:00Z`,
+			`5,Bob,bob@example.com,annual,approved,2026-03-05,2026-03-05,am,pm,2,0.25,"Dentist, downtown",`,
+			"",
+		}, "\n"), buf.String())
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Invalid sort writes nothing", func(t *testing.T) {
+		mockRepo := new(MockLeaveRequestRepo)
+		uc := newLeaveRequestUsecase(mockRepo, new(MockLeaveBalanceRepo), new(MockHolidayRepo), new(MockLeaveTypeRepo), new(MockLeavePolicyRepo), new(MockAutoApprovalRuleRepo), newUserRepo(t), new(MockApprovalRepo), new(MockDelegationRepo))
+
+		var buf bytes.Buffer
+		errResp := uc.ExportLeaveRequests(context.Background(), &buf, util.ExportXLSX, "salary", "asc", "", entity.LeaveRequestFilter{})
+
+		assert.Equal(t, http.StatusBadRequest, errResp.Code)
+		assert.Zero(t, buf.Len())
+		mockRepo.AssertNotCalled(t, "ExportLeaveRequests", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
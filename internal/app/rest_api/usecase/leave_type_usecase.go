@@ -0,0 +1,109 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"regexp"
+
+	entity "github.com/devonLoen/leave-request-service/internal/app/rest_api/entity"
+	models "github.com/devonLoen/leave-request-service/internal/app/rest_api/model"
+	"github.com/devonLoen/leave-request-service/internal/app/rest_api/model/dto"
+	"github.com/devonLoen/leave-request-service/internal/app/rest_api/repository"
+)
+
+type LeaveTypeUsecase interface {
+	GetLeaveTypes(ctx context.Context) (*dto.GetLeaveTypesResponse, *models.ErrorResponse)
+	UpsertLeaveType(ctx context.Context, code string, req *dto.UpsertLeaveTypeRequest) (*dto.LeaveTypeResponse, *models.ErrorResponse)
+}
+
+type LeaveType struct {
+	leaveTypeRepo repository.LeaveTypeRepository
+}
+
+func NewLeaveTypeUsecase(leaveTypeRepo repository.LeaveTypeRepository) *LeaveType {
+	return &LeaveType{leaveTypeRepo: leaveTypeRepo}
+}
+
+// leaveTypeCode matches the codes the leave_types table accepts.
+var leaveTypeCode = regexp.MustCompile(`^[a-z][a-z0-9_]{0,29}$`)
+
+func (us *LeaveType) GetLeaveTypes(ctx context.Context) (*dto.GetLeaveTypesResponse, *models.ErrorResponse) {
+	response := &dto.GetLeaveTypesResponse{}
+
+	leaveTypes, err := us.leaveTypeRepo.GetAll(ctx)
+	if err != nil {
+		return nil, &models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal Server Error",
+		}
+	}
+
+	response.MapLeaveTypesResponse(leaveTypes)
+
+	return response, nil
+}
+
+// UpsertLeaveType adds a leave type or replaces its attributes. Leave types are never
+// deleted, since requests and ledger entries refer to them; deactivating one stops new
+// requests of that type. Whether a type deducts balance is fixed once it exists, so the
+// reservations and debits already in the ledger are always released and refunded.
+func (us *LeaveType) UpsertLeaveType(ctx context.Context, code string, req *dto.UpsertLeaveTypeRequest) (*dto.LeaveTypeResponse, *models.ErrorResponse) {
+	response := &dto.LeaveTypeResponse{}
+
+	if !leaveTypeCode.MatchString(code) {
+		return nil, &models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Leave type code must be lowercase letters, digits or underscores",
+		}
+	}
+
+	leaveType := req.ToLeaveType(entity.LeaveRequestType(code))
+
+	existing, err := us.leaveTypeRepo.FindByCode(ctx, leaveType.Code)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, &models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal Server Error",
+		}
+	}
+	if existing != nil && existing.DeductsBalance != leaveType.DeductsBalance {
+		return nil, &models.ErrorResponse{
+			Code:    http.StatusConflict,
+			Message: "deductsBalance cannot be changed for an existing leave type",
+		}
+	}
+
+	err = us.leaveTypeRepo.Upsert(ctx, leaveType)
+	if err != nil {
+		return nil, &models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to save leave type",
+		}
+	}
+
+	response.MapLeaveTypeResponse(leaveType)
+
+	return response, nil
+}
+
+// findLeaveType loads the leave type a request, policy or ledger entry refers to. An unknown
+// code is the caller's mistake.
+func findLeaveType(ctx context.Context, leaveTypeRepo repository.LeaveTypeRepository, code entity.LeaveRequestType) (*entity.LeaveType, *models.ErrorResponse) {
+	leaveType, err := leaveTypeRepo.FindByCode(ctx, code)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, &models.ErrorResponse{
+				Code:    http.StatusBadRequest,
+				Message: "Leave type not valid",
+			}
+		}
+		return nil, &models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal Server Error",
+		}
+	}
+
+	return leaveType, nil
+}
@@ -0,0 +1,224 @@
+package usecase_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	entity "github.com/devonLoen/leave-request-service/internal/app/rest_api/entity"
+	"github.com/devonLoen/leave-request-service/internal/app/rest_api/model/dto"
+	"github.com/devonLoen/leave-request-service/internal/app/rest_api/repository"
+	"github.com/devonLoen/leave-request-service/internal/app/rest_api/usecase"
+)
+
+type MockLeaveTypeRepo struct {
+	mock.Mock
+}
+
+func (m *MockLeaveTypeRepo) GetAll(ctx context.Context) ([]*entity.LeaveType, error) {
+	args := m.Called()
+	if args.Get(0) != nil {
+		return args.Get(0).([]*entity.LeaveType), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockLeaveTypeRepo) FindByCode(ctx context.Context, code entity.LeaveRequestType) (*entity.LeaveType, error) {
+	args := m.Called(code)
+	if args.Get(0) != nil {
+		return args.Get(0).(*entity.LeaveType), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockLeaveTypeRepo) Upsert(ctx context.Context, leaveType *entity.LeaveType) error {
+	return m.Called(leaveType).Error(0)
+}
+
+// The leave types every installation starts with.
+var (
+	annualLeave = &entity.LeaveType{Code: entity.Annual, Name: "Annual Leave", Paid: true, DeductsBalance: true, Active: true}
+	sickLeave   = &entity.LeaveType{Code: entity.Sick, Name: "Sick Leave", Paid: true, DeductsBalance: true, RequiresAttachment: true, AttachmentAfterDays: 2, Active: true}
+	unpaidLeave = &entity.LeaveType{Code: entity.Unpaid, Name: "Unpaid Leave", Active: true}
+)
+
+func TestUpsertLeaveType(t *testing.T) {
+	tests := []struct {
+		name           string
+		code           string
+		deductsBalance bool
+		setupMock      func(repo *MockLeaveTypeRepo)
+		wantCode       int
+	}{
+		{name: "Invalid code", code: "Study Leave", setupMock: func(repo *MockLeaveTypeRepo) {}, wantCode: http.StatusBadRequest},
+		{
+			name:           "Deducting balance cannot change",
+			code:           "unpaid",
+			deductsBalance: true,
+			setupMock: func(repo *MockLeaveTypeRepo) {
+				repo.On("FindByCode", entity.Unpaid).Return(&entity.LeaveType{Code: entity.Unpaid, Name: "Unpaid Leave", Active: true}, nil).Once()
+			},
+			wantCode: http.StatusConflict,
+		},
+		{
+			name: "Repo error",
+			code: "study",
+			setupMock: func(repo *MockLeaveTypeRepo) {
+				repo.On("FindByCode", entity.LeaveRequestType("study")).Return(nil, errors.New("db error")).Once()
+			},
+			wantCode: http.StatusInternalServerError,
+		},
+		{
+			name: "Success",
+			code: "study",
+			setupMock: func(repo *MockLeaveTypeRepo) {
+				repo.On("FindByCode", entity.LeaveRequestType("study")).Return(nil, sql.ErrNoRows).Once()
+				repo.On("Upsert", mock.MatchedBy(func(lt *entity.LeaveType) bool {
+					return lt.Code == "study" && lt.MaxConsecutiveDays != nil && *lt.MaxConsecutiveDays == 5
+				})).Return(nil).Once()
+			},
+			wantCode: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(MockLeaveTypeRepo)
+			tt.setupMock(repo)
+			uc := usecase.NewLeaveTypeUsecase(repo)
+
+			maxDays := 5.0
+			res, err := uc.UpsertLeaveType(context.Background(), tt.code, &dto.UpsertLeaveTypeRequest{
+				Name:               "Study Leave",
+				Paid:               true,
+				DeductsBalance:     tt.deductsBalance,
+				MaxConsecutiveDays: &maxDays,
+				AllowedRoles:       []string{"employee"},
+				Active:             true,
+			})
+
+			if tt.wantCode != 0 {
+				assert.Nil(t, res)
+				assert.Equal(t, tt.wantCode, err.Code)
+				repo.AssertNotCalled(t, "Upsert", mock.Anything)
+			} else {
+				assert.Nil(t, err)
+				assert.Equal(t, "study", res.Code)
+				assert.Equal(t, []string{"employee"}, res.AllowedRoles)
+			}
+			repo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCreateLeaveRequestLeaveTypeRules(t *testing.T) {
+	monday := nextMonday()
+	maxDays := 2.0
+
+	tests := []struct {
+		name      string
+		leaveType *entity.LeaveType
+		req       dto.CreateLeaveRequestRequest
+		userRole  string
+		wantCode  int
+		wantMsg   string
+	}{
+		{
+			name:     "Unknown type",
+			req:      dto.CreateLeaveRequestRequest{StartDate: monday, EndDate: monday, Type: "sabbatical", Status: "draft"},
+			wantCode: http.StatusBadRequest,
+			wantMsg:  "Leave type not valid",
+		},
+		{
+			name:      "Inactive type",
+			leaveType: &entity.LeaveType{Code: "study", Name: "Study Leave"},
+			req:       dto.CreateLeaveRequestRequest{StartDate: monday, EndDate: monday, Type: "study", Status: "draft"},
+			wantCode:  http.StatusUnprocessableEntity,
+			wantMsg:   "Study Leave can no longer be requested.",
+		},
+		{
+			name:      "Longer than allowed",
+			leaveType: &entity.LeaveType{Code: "study", Name: "Study Leave", MaxConsecutiveDays: &maxDays, Active: true},
+			req:       dto.CreateLeaveRequestRequest{StartDate: monday, EndDate: monday.AddDate(0, 0, 2), Type: "study", Status: "draft"},
+			wantCode:  http.StatusUnprocessableEntity,
+			wantMsg:   "Study Leave is limited to 2.0 consecutive working day(s), 3.0 requested.",
+		},
+		{
+			name:      "Role not allowed",
+			leaveType: &entity.LeaveType{Code: "study", Name: "Study Leave", AllowedRoles: []entity.UserRole{entity.RoleAdmin}, Active: true},
+			req:       dto.CreateLeaveRequestRequest{StartDate: monday, EndDate: monday, Type: "study", Status: "draft"},
+			userRole:  "employee",
+			wantCode:  http.StatusForbidden,
+			wantMsg:   "Study Leave is not available to your role.",
+		},
+		{
+			name:      "Not enough notice when submitting",
+			leaveType: &entity.LeaveType{Code: "study", Name: "Study Leave", MinNoticeDays: 30, Active: true},
+			req:       dto.CreateLeaveRequestRequest{StartDate: monday, EndDate: monday, Type: "study", Status: "waiting_approval"},
+			wantCode:  http.StatusUnprocessableEntity,
+			wantMsg:   "Study Leave must be requested at least 30 day(s) in advance.",
+		},
+		{
+			name:      "Drafts do not need notice",
+			leaveType: &entity.LeaveType{Code: "study", Name: "Study Leave", MinNoticeDays: 30, AllowedRoles: []entity.UserRole{entity.RoleEmployee}, Active: true},
+			req:       dto.CreateLeaveRequestRequest{StartDate: monday, EndDate: monday, Type: "study", Status: "draft"},
+			userRole:  "employee",
+			wantCode:  0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, sqlMock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("Error creating mock database: %v", err)
+			}
+			defer db.Close()
+			if tt.userRole != "" {
+				sqlMock.ExpectQuery(`SELECT u.id`).WillReturnRows(userRows().AddRow(1, "Jane Doe", "jane@example.com", tt.userRole, nil, "", hiredOn))
+			}
+
+			leaveTypeRepo := new(MockLeaveTypeRepo)
+			leavePolicyRepo := new(MockLeavePolicyRepo)
+			if tt.leaveType != nil {
+				leaveTypeRepo.On("FindByCode", tt.leaveType.Code).Return(tt.leaveType, nil).Once()
+				leavePolicyRepo.On("FindApplicable", 1, tt.leaveType.Code, mock.Anything).Return(nil, sql.ErrNoRows).Once()
+			} else {
+				leaveTypeRepo.On("FindByCode", entity.LeaveRequestType(tt.req.Type)).Return(nil, sql.ErrNoRows).Once()
+			}
+
+			mockRepo := new(MockLeaveRequestRepo)
+			mockHolidayRepo := new(MockHolidayRepo)
+			mockHolidayRepo.On("GetHolidaysBetween", mock.Anything, mock.Anything).Return([]*entity.Holiday{}, nil)
+			if tt.wantCode == 0 {
+				mockRepo.On("OverlapApprovedLeaveExists", 1, mock.Anything, mock.Anything).Return(false, nil).Once()
+				mockRepo.On("Create", mock.Anything, 1).Return(nil).Once()
+			}
+
+			userRepo := repository.NewUserRepository(db)
+			uc := usecase.NewLeaveRequestUsecase(mockRepo, new(MockLeaveBalanceRepo), mockHolidayRepo, leaveTypeRepo, leavePolicyRepo, new(MockAutoApprovalRuleRepo), userRepo, new(MockApprovalRepo), new(MockDelegationRepo), new(MockAttachmentRepo), new(MockStorage), &MockUnitOfWork{repos: &repository.Repositories{User: userRepo, LeaveRequest: mockRepo}})
+
+			tt.req.Reason = "Preparing for the certification exam"
+			res, errCreate := uc.CreateLeaveRequest(context.Background(), &tt.req, 1)
+
+			if tt.wantCode != 0 {
+				assert.Nil(t, res)
+				assert.Equal(t, tt.wantCode, errCreate.Code)
+				assert.Equal(t, tt.wantMsg, errCreate.Message)
+			} else {
+				assert.Nil(t, errCreate)
+				assert.NotNil(t, res)
+			}
+			mockRepo.AssertExpectations(t)
+			leaveTypeRepo.AssertExpectations(t)
+			leavePolicyRepo.AssertExpectations(t)
+			assert.NoError(t, sqlMock.ExpectationsWereMet())
+		})
+	}
+}
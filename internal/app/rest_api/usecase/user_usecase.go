@@ -1,9 +1,12 @@
 package usecase
 
 import (
+	"context"
 	"database/sql"
+	"encoding/csv"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"strings"
 
@@ -12,17 +15,19 @@ import (
 	"github.com/devonLoen/leave-request-service/internal/app/rest_api/model/dto"
 	"github.com/devonLoen/leave-request-service/internal/app/rest_api/pkg/util"
 	repository "github.com/devonLoen/leave-request-service/internal/app/rest_api/repository"
+	"github.com/go-playground/validator/v10"
 )
 
 type User struct {
-	userRepo *repository.User
+	userRepo   *repository.User
+	unitOfWork repository.UnitOfWork
 }
 
-func NewUserUsecase(userRepo *repository.User) *User {
-	return &User{userRepo: userRepo}
+func NewUserUsecase(userRepo *repository.User, unitOfWork repository.UnitOfWork) *User {
+	return &User{userRepo: userRepo, unitOfWork: unitOfWork}
 }
 
-func (us *User) GetAllUsers(limit, offset int, sortBy, orderBy, search string, filter entity.UserFilter) (*dto.GetAllUsersResponse, *models.ErrorResponse) {
+func (us *User) GetAllUsers(ctx context.Context, limit, offset int, sortBy, orderBy, search string, filter entity.UserFilter) (*dto.GetAllUsersResponse, *models.ErrorResponse) {
 	response := &dto.GetAllUsersResponse{}
 
 	allowedSorts := map[string]bool{
@@ -62,7 +67,7 @@ func (us *User) GetAllUsers(limit, offset int, sortBy, orderBy, search string, f
 		}
 	}
 
-	queriedUsers, err := us.userRepo.GetAllUsers(limit, offset, safeSortBy, safeOrderBy, search, filter)
+	queriedUsers, err := us.userRepo.GetAllUsers(ctx, limit, offset, safeSortBy, safeOrderBy, search, filter)
 	if err != nil {
 		return nil, &models.ErrorResponse{
 			Code:    http.StatusInternalServerError,
@@ -75,10 +80,10 @@ func (us *User) GetAllUsers(limit, offset int, sortBy, orderBy, search string, f
 	return response, nil
 }
 
-func (us *User) GetUser(userID int) (*dto.UserResponse, *models.ErrorResponse) {
+func (us *User) GetUser(ctx context.Context, userID int) (*dto.UserResponse, *models.ErrorResponse) {
 	response := &dto.UserResponse{}
 
-	user, err := us.userRepo.FindById(userID)
+	user, err := us.userRepo.FindById(ctx, userID)
 
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -98,23 +103,15 @@ func (us *User) GetUser(userID int) (*dto.UserResponse, *models.ErrorResponse) {
 	return response, nil
 }
 
-func (us *User) CreateUser(createUserRequest *dto.CreateUserRequest) (*dto.CreateUserResponse, *models.ErrorResponse) {
+func (us *User) CreateUser(ctx context.Context, createUserRequest *dto.CreateUserRequest) (*dto.CreateUserResponse, *models.ErrorResponse) {
 	userResponse := &dto.CreateUserResponse{}
 
-	errEmail := us.checkIfEmailExists(createUserRequest.Email)
+	errEmail := us.checkIfEmailExists(ctx, createUserRequest.Email)
 	if errEmail != nil {
 		return nil, errEmail
 	}
 
-	plainPassword, errPass := util.GenerateSecurePassword(12)
-	if errPass != nil {
-		return nil, &models.ErrorResponse{
-			Code:    http.StatusInternalServerError,
-			Message: "Internal Server Error",
-		}
-	}
-
-	hashedPassword, errPass := util.HashPassword(plainPassword)
+	plainPassword, hashedPassword, errPass := newUserPassword()
 	if errPass != nil {
 		return nil, &models.ErrorResponse{
 			Code:    http.StatusInternalServerError,
@@ -125,7 +122,7 @@ func (us *User) CreateUser(createUserRequest *dto.CreateUserRequest) (*dto.Creat
 	user := createUserRequest.ToUser()
 	user.Password = hashedPassword
 
-	err := us.userRepo.Create(user)
+	err := us.userRepo.Create(ctx, user)
 	if err != nil {
 		return nil, &models.ErrorResponse{
 			Code:    http.StatusInternalServerError,
@@ -139,8 +136,386 @@ func (us *User) CreateUser(createUserRequest *dto.CreateUserRequest) (*dto.Creat
 	return userResponse.FromUser(user), nil
 }
 
-func (us *User) checkIfEmailExists(email string) *models.ErrorResponse {
-	userWithEmail, err := us.userRepo.FindByEmail(email)
+// SetManager puts the user under managerId in the reporting line, or takes them out of it
+// when managerId is nil. A manager cannot report to one of their own reports.
+func (us *User) SetManager(ctx context.Context, userID int, setManagerRequest *dto.SetManagerRequest) (*dto.UserResponse, *models.ErrorResponse) {
+	response := &dto.UserResponse{}
+
+	user, err := us.userRepo.FindById(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, &models.ErrorResponse{
+				Code:    http.StatusNotFound,
+				Message: "User Not Found",
+			}
+		}
+		return nil, &models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal Server Error",
+		}
+	}
+
+	managerId := setManagerRequest.ManagerId
+	if managerId != nil {
+		if *managerId == userID {
+			return nil, &models.ErrorResponse{
+				Code:    http.StatusUnprocessableEntity,
+				Message: "A user cannot be their own manager",
+			}
+		}
+
+		_, err = us.userRepo.FindById(ctx, *managerId)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil, &models.ErrorResponse{
+					Code:    http.StatusUnprocessableEntity,
+					Message: "Manager Not Found",
+				}
+			}
+			return nil, &models.ErrorResponse{
+				Code:    http.StatusInternalServerError,
+				Message: "Internal Server Error",
+			}
+		}
+
+		isReport, err := us.userRepo.IsManagerOf(ctx, userID, *managerId)
+		if err != nil {
+			return nil, &models.ErrorResponse{
+				Code:    http.StatusInternalServerError,
+				Message: "Internal Server Error",
+			}
+		}
+		if isReport {
+			return nil, &models.ErrorResponse{
+				Code:    http.StatusUnprocessableEntity,
+				Message: "The manager already reports to this user",
+			}
+		}
+	}
+
+	err = us.userRepo.SetManager(ctx, userID, managerId)
+	if err != nil {
+		return nil, &models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to set manager",
+		}
+	}
+
+	user.ManagerId = managerId
+	response.MapUserResponse(user)
+
+	return response, nil
+}
+
+// importUserRow is one data row of a user import. managerId is set when the manager is an
+// existing user and managerRow when it is another row of the same file.
+type importUserRow struct {
+	response   *dto.ImportUserRowResponse
+	request    dto.CreateUserRequest
+	manager    string
+	managerId  *int
+	managerRow *importUserRow
+	user       *entity.User
+}
+
+func (row *importUserRow) valid() bool {
+	return len(row.response.Errors) == 0
+}
+
+func (row *importUserRow) reject(field, message string) {
+	if row.response.Errors == nil {
+		row.response.Errors = make(map[string]string)
+	}
+	if _, ok := row.response.Errors[field]; !ok {
+		row.response.Errors[field] = message
+	}
+}
+
+// ImportUsers creates the users of a CSV file with fullName, email and role columns and optional
+// manager (the email of an existing user or of another row), department and hireDate columns.
+// Every row is checked like a single CreateUser request; the valid ones are created in one
+// transaction and the others are reported with their errors. A dry run only reports.
+func (us *User) ImportUsers(ctx context.Context, csvFile io.Reader, dryRun bool) (*dto.ImportUsersResponse, *models.ErrorResponse) {
+	rows, errResp := readImportUserRows(csvFile)
+	if errResp != nil {
+		return nil, errResp
+	}
+
+	validate := validator.New()
+	byEmail := make(map[string]*importUserRow)
+	for _, row := range rows {
+		if err := validate.Struct(row.request); err != nil {
+			var ve validator.ValidationErrors
+			if !errors.As(err, &ve) {
+				return nil, &models.ErrorResponse{
+					Code:    http.StatusInternalServerError,
+					Message: "Internal Server Error",
+				}
+			}
+			for _, fe := range ve {
+				row.reject(fe.Field(), util.MsgForTag(fe))
+			}
+		}
+
+		if row.request.Email == "" {
+			continue
+		}
+
+		email := strings.ToLower(row.request.Email)
+		if _, ok := byEmail[email]; ok {
+			row.reject("Email", "Email appears more than once in the file")
+			continue
+		}
+		byEmail[email] = row
+
+		errEmail := us.checkIfEmailExists(ctx, row.request.Email)
+		if errEmail != nil {
+			if errEmail.Code == http.StatusInternalServerError {
+				return nil, errEmail
+			}
+			row.reject("Email", errEmail.Message)
+		}
+	}
+
+	for _, row := range rows {
+		errResp := us.resolveImportManager(ctx, row, byEmail)
+		if errResp != nil {
+			return nil, errResp
+		}
+	}
+
+	for _, row := range rows {
+		steps := 0
+		for manager := row.managerRow; manager != nil && steps <= len(rows); manager = manager.managerRow {
+			if manager == row {
+				row.reject("Manager", "Managers in the file report to each other")
+				break
+			}
+			steps++
+		}
+	}
+
+	for changed := true; changed; {
+		changed = false
+		for _, row := range rows {
+			if row.valid() && row.managerRow != nil && !row.managerRow.valid() {
+				row.reject("Manager", "The manager's row is invalid")
+				changed = true
+			}
+		}
+	}
+
+	response := &dto.ImportUsersResponse{DryRun: dryRun, Rows: []*dto.ImportUserRowResponse{}}
+	var validRows []*importUserRow
+	for _, row := range rows {
+		response.Rows = append(response.Rows, row.response)
+		if !row.valid() {
+			row.response.Status = "invalid"
+			response.Invalid++
+			continue
+		}
+		row.response.Status = "valid"
+		response.Valid++
+		validRows = append(validRows, row)
+	}
+
+	if dryRun || len(validRows) == 0 {
+		response.Message = "No users were created."
+		return response, nil
+	}
+
+	// Hashing is slow on purpose, so it happens before the transaction opens and only the
+	// inserts count against its timeout.
+	plainPasswords := make([]string, len(validRows))
+	for i, row := range validRows {
+		plainPassword, hashedPassword, err := newUserPassword()
+		if err != nil {
+			return nil, &models.ErrorResponse{
+				Code:    http.StatusInternalServerError,
+				Message: "Failed to import users",
+			}
+		}
+		plainPasswords[i] = plainPassword
+
+		row.user = row.request.ToUser()
+		row.user.Password = hashedPassword
+		row.user.ManagerId = row.managerId
+	}
+
+	err := us.unitOfWork.Do(ctx, func(repos *repository.Repositories) error {
+		for _, row := range validRows {
+			err := repos.User.Create(ctx, row.user)
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, row := range validRows {
+			if row.managerRow == nil {
+				continue
+			}
+
+			err := repos.User.SetManager(ctx, row.user.ID, &row.managerRow.user.ID)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, &models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to import users",
+		}
+	}
+
+	for i, row := range validRows {
+		fmt.Println(plainPasswords[i])
+
+		//send email implementation.....
+
+		row.response.Status = "created"
+		response.Created++
+	}
+	response.Message = "Users imported successfully."
+
+	return response, nil
+}
+
+// resolveImportManager points row at its manager, preferring an existing user over a row of
+// the file with the same email.
+func (us *User) resolveImportManager(ctx context.Context, row *importUserRow, byEmail map[string]*importUserRow) *models.ErrorResponse {
+	if row.manager == "" {
+		return nil
+	}
+
+	if strings.EqualFold(row.manager, row.request.Email) {
+		row.reject("Manager", "A user cannot be their own manager")
+		return nil
+	}
+
+	manager, err := us.userRepo.FindByEmail(ctx, row.manager)
+	if err == nil {
+		row.managerId = &manager.ID
+		return nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return &models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal Server Error",
+		}
+	}
+
+	managerRow, ok := byEmail[strings.ToLower(row.manager)]
+	if !ok {
+		row.reject("Manager", "Manager Not Found")
+		return nil
+	}
+	row.managerRow = managerRow
+
+	return nil
+}
+
+// readImportUserRows reads the rows of a user import. Header names are matched ignoring case,
+// spaces, dashes and underscores, so "Full Name" and "full_name" both name the fullName column.
+func readImportUserRows(csvFile io.Reader) ([]*importUserRow, *models.ErrorResponse) {
+	reader := csv.NewReader(csvFile)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, &models.ErrorResponse{
+				Code:    http.StatusBadRequest,
+				Message: "CSV file is empty",
+			}
+		}
+		return nil, &models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid CSV file: " + err.Error(),
+		}
+	}
+
+	columns := make(map[string]int)
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(name), "\ufeff"))
+		name = strings.NewReplacer(" ", "", "_", "", "-", "").Replace(name)
+		columns[name] = i
+	}
+	for _, required := range []string{"fullname", "email", "role"} {
+		if _, ok := columns[required]; !ok {
+			return nil, &models.ErrorResponse{
+				Code:    http.StatusBadRequest,
+				Message: "CSV file must have fullName, email and role columns",
+			}
+		}
+	}
+
+	var rows []*importUserRow
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, &models.ErrorResponse{
+				Code:    http.StatusBadRequest,
+				Message: "Invalid CSV file: " + err.Error(),
+			}
+		}
+
+		field := func(name string) string {
+			i, ok := columns[name]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+
+		line, _ := reader.FieldPos(0)
+		rows = append(rows, &importUserRow{
+			response: &dto.ImportUserRowResponse{Row: line, Email: field("email")},
+			request: dto.CreateUserRequest{
+				FullName:   field("fullname"),
+				Email:      field("email"),
+				Role:       field("role"),
+				Department: field("department"),
+				HireDate:   field("hiredate"),
+			},
+			manager: field("manager"),
+		})
+	}
+
+	if len(rows) == 0 {
+		return nil, &models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "CSV file has no users",
+		}
+	}
+
+	return rows, nil
+}
+
+// newUserPassword generates the initial password of a new user, returning it in plain text
+// and hashed.
+func newUserPassword() (string, string, error) {
+	plainPassword, err := util.GenerateSecurePassword(12)
+	if err != nil {
+		return "", "", err
+	}
+
+	hashedPassword, err := util.HashPassword(plainPassword)
+	if err != nil {
+		return "", "", err
+	}
+
+	return plainPassword, hashedPassword, nil
+}
+
+func (us *User) checkIfEmailExists(ctx context.Context, email string) *models.ErrorResponse {
+	userWithEmail, err := us.userRepo.FindByEmail(ctx, email)
 	if err != nil && !errors.Is(err, sql.ErrNoRows) {
 		return &models.ErrorResponse{
 			Code:    http.StatusInternalServerError,
@@ -0,0 +1,115 @@
+package usecase_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/devonLoen/leave-request-service/internal/app/rest_api/repository"
+	"github.com/devonLoen/leave-request-service/internal/app/rest_api/usecase"
+)
+
+func newImportUserUsecase(t *testing.T) (*usecase.User, sqlmock.Sqlmock) {
+	db, sqlMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating mock database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	userRepo := repository.NewUserRepository(db)
+	unitOfWork := &MockUnitOfWork{repos: &repository.Repositories{User: userRepo}}
+
+	return usecase.NewUserUsecase(userRepo, unitOfWork), sqlMock
+}
+
+func TestImportUsers(t *testing.T) {
+	uc, sqlMock := newImportUserUsecase(t)
+
+	csvFile := strings.NewReader("Full Name,Email,Role,Manager,Department\n" +
+		"Mia Manager,mia@example.com,admin,,HR\n" +
+		"Eve Employee,eve@example.com,employee,mia@example.com,HR\n" +
+		"Al,not-an-email,intern,,\n" +
+		"Eve Again,EVE@example.com,employee,,\n" +
+		"Ken Known,KEN@example.com,employee,,\n")
+
+	sqlMock.ExpectQuery(`SELECT u.id`).WithArgs("mia@example.com").WillReturnRows(userRows())
+	sqlMock.ExpectQuery(`SELECT u.id`).WithArgs("eve@example.com").WillReturnRows(userRows())
+	sqlMock.ExpectQuery(`SELECT u.id`).WithArgs("not-an-email").WillReturnRows(userRows())
+	sqlMock.ExpectQuery(`WHERE LOWER\(u.email\) = LOWER\(\$1\)`).WithArgs("KEN@example.com").
+		WillReturnRows(userRows().AddRow(4, "Ken Known", "ken@example.com", "employee", nil, "", hiredOn))
+	sqlMock.ExpectQuery(`SELECT u.id`).WithArgs("mia@example.com").WillReturnRows(userRows())
+	sqlMock.ExpectQuery(`INSERT INTO users`).
+		WithArgs("Mia Manager", "mia@example.com", sqlmock.AnyArg(), "admin", nil, "HR", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(10))
+	sqlMock.ExpectQuery(`INSERT INTO users`).
+		WithArgs("Eve Employee", "eve@example.com", sqlmock.AnyArg(), "employee", nil, "HR", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(11))
+	sqlMock.ExpectExec(`UPDATE users SET manager_id`).WithArgs(11, 10).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	result, errResp := uc.ImportUsers(context.Background(), csvFile, false)
+
+	assert.Nil(t, errResp)
+	assert.Equal(t, 2, result.Created)
+	assert.Equal(t, 3, result.Invalid)
+	assert.Len(t, result.Rows, 5)
+
+	assert.Equal(t, 2, result.Rows[0].Row)
+	assert.Equal(t, "created", result.Rows[0].Status)
+	assert.Equal(t, "created", result.Rows[1].Status)
+
+	assert.Equal(t, "invalid", result.Rows[2].Status)
+	assert.Contains(t, result.Rows[2].Errors, "FullName")
+	assert.Contains(t, result.Rows[2].Errors, "Email")
+	assert.Contains(t, result.Rows[2].Errors, "Role")
+
+	assert.Equal(t, "Email appears more than once in the file", result.Rows[3].Errors["Email"])
+	assert.Equal(t, "Email already in use", result.Rows[4].Errors["Email"])
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+}
+
+func TestImportUsersDryRun(t *testing.T) {
+	uc, sqlMock := newImportUserUsecase(t)
+
+	csvFile := strings.NewReader("fullName,email,role,manager\n" +
+		"Ann Able,ann@example.com,employee,bob@example.com\n" +
+		"Bob Baker,bob@example.com,employee,ann@example.com\n" +
+		"Cat Cole,cat@example.com,employee,ann@example.com\n" +
+		"Dan Dale,dan@example.com,employee,nobody@example.com\n" +
+		"Eli Ellis,eli@example.com,employee\n")
+
+	for _, email := range []string{"ann@example.com", "bob@example.com", "cat@example.com", "dan@example.com", "eli@example.com"} {
+		sqlMock.ExpectQuery(`SELECT u.id`).WithArgs(email).WillReturnRows(userRows())
+	}
+	for _, manager := range []string{"bob@example.com", "ann@example.com", "ann@example.com", "nobody@example.com"} {
+		sqlMock.ExpectQuery(`SELECT u.id`).WithArgs(manager).WillReturnRows(userRows())
+	}
+
+	result, errResp := uc.ImportUsers(context.Background(), csvFile, true)
+
+	assert.Nil(t, errResp)
+	assert.True(t, result.DryRun)
+	assert.Equal(t, 0, result.Created)
+	assert.Equal(t, 1, result.Valid)
+	assert.Equal(t, "Managers in the file report to each other", result.Rows[0].Errors["Manager"])
+	assert.Equal(t, "Managers in the file report to each other", result.Rows[1].Errors["Manager"])
+	assert.Equal(t, "The manager's row is invalid", result.Rows[2].Errors["Manager"])
+	assert.Equal(t, "Manager Not Found", result.Rows[3].Errors["Manager"])
+	assert.Equal(t, "valid", result.Rows[4].Status)
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+}
+
+func TestImportUsersRejectsFile(t *testing.T) {
+	uc, _ := newImportUserUsecase(t)
+
+	for _, csvFile := range []string{"", "email,role\nann@example.com,employee\n", "fullName,email,role\n"} {
+		result, errResp := uc.ImportUsers(context.Background(), strings.NewReader(csvFile), false)
+
+		assert.Nil(t, result)
+		assert.Equal(t, http.StatusBadRequest, errResp.Code)
+	}
+}
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS leave_balance_entries;
+DROP TYPE IF EXISTS balance_entry_type;
\ No newline at end of file
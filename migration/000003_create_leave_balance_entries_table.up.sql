@@ -0,0 +1,16 @@
+CREATE TYPE balance_entry_type AS ENUM ('credit', 'debit', 'reservation');
+
+CREATE TABLE leave_balance_entries (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    leave_year INTEGER NOT NULL,
+    type leave_type_enum NOT NULL,
+    entry_type balance_entry_type NOT NULL,
+    days NUMERIC(6,2) NOT NULL CHECK (days > 0),
+    leave_request_id INTEGER REFERENCES leave_requests(id) ON DELETE CASCADE,
+    note TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_leave_balance_entries_user_year ON leave_balance_entries (user_id, leave_year, type);
+CREATE INDEX idx_leave_balance_entries_leave_request ON leave_balance_entries (leave_request_id);
\ No newline at end of file
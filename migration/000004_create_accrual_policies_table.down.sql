@@ -0,0 +1,10 @@
+DROP TABLE IF EXISTS accrual_policies;
+
+DROP INDEX IF EXISTS uq_leave_balance_entries_accrual_period;
+ALTER TABLE leave_balance_entries DROP COLUMN IF EXISTS accrual_period;
+
+DELETE FROM leave_balance_entries WHERE entry_type::text IN ('accrual', 'carry_over', 'expiry');
+ALTER TABLE leave_balance_entries ALTER COLUMN entry_type TYPE TEXT;
+DROP TYPE balance_entry_type;
+CREATE TYPE balance_entry_type AS ENUM ('credit', 'debit', 'reservation');
+ALTER TABLE leave_balance_entries ALTER COLUMN entry_type TYPE balance_entry_type USING entry_type::balance_entry_type;
\ No newline at end of file
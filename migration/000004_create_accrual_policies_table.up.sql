@@ -0,0 +1,20 @@
+ALTER TYPE balance_entry_type ADD VALUE IF NOT EXISTS 'accrual';
+ALTER TYPE balance_entry_type ADD VALUE IF NOT EXISTS 'carry_over';
+ALTER TYPE balance_entry_type ADD VALUE IF NOT EXISTS 'expiry';
+
+ALTER TABLE leave_balance_entries ADD COLUMN accrual_period DATE;
+
+CREATE UNIQUE INDEX uq_leave_balance_entries_accrual_period
+    ON leave_balance_entries (user_id, type, entry_type, accrual_period)
+    WHERE accrual_period IS NOT NULL;
+
+CREATE TABLE accrual_policies (
+    id SERIAL PRIMARY KEY,
+    type leave_type_enum UNIQUE NOT NULL,
+    days_per_month NUMERIC(5,2) NOT NULL CHECK (days_per_month >= 0),
+    carry_over_cap NUMERIC(5,2) NOT NULL DEFAULT 0 CHECK (carry_over_cap >= 0),
+    carry_over_expiry_months INTEGER NOT NULL DEFAULT 0 CHECK (carry_over_expiry_months BETWEEN 0 AND 12),
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
\ No newline at end of file
@@ -0,0 +1,3 @@
+ALTER TABLE leave_requests DROP COLUMN IF EXISTS working_days;
+
+DROP TABLE IF EXISTS public_holidays;
\ No newline at end of file
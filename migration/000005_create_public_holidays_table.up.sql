@@ -0,0 +1,15 @@
+CREATE TABLE public_holidays (
+    id SERIAL PRIMARY KEY,
+    holiday_date DATE UNIQUE NOT NULL,
+    name VARCHAR(150) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE leave_requests ADD COLUMN working_days NUMERIC(5,2) NOT NULL DEFAULT 0;
+
+UPDATE leave_requests lr SET working_days = (
+    SELECT COUNT(*)
+    FROM generate_series(lr.start_date, lr.end_date, interval '1 day') AS d
+    WHERE EXTRACT(ISODOW FROM d) < 6
+);
\ No newline at end of file
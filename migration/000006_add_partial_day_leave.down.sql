@@ -0,0 +1,13 @@
+ALTER TABLE leave_balance_entries ALTER COLUMN days TYPE NUMERIC(6,2);
+
+ALTER TABLE leave_requests
+    DROP CONSTRAINT IF EXISTS chk_leave_requests_period,
+    DROP COLUMN IF EXISTS period_end,
+    DROP COLUMN IF EXISTS period_start,
+    DROP COLUMN IF EXISTS end_session,
+    DROP COLUMN IF EXISTS start_session,
+    DROP COLUMN IF EXISTS duration_unit,
+    ALTER COLUMN working_days TYPE NUMERIC(5,2);
+
+DROP TYPE IF EXISTS day_session_enum;
+DROP TYPE IF EXISTS leave_duration_unit;
\ No newline at end of file
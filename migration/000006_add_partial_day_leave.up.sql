@@ -0,0 +1,19 @@
+CREATE TYPE leave_duration_unit AS ENUM ('day', 'hour');
+CREATE TYPE day_session_enum AS ENUM ('am', 'pm');
+
+ALTER TABLE leave_requests
+    ADD COLUMN duration_unit leave_duration_unit NOT NULL DEFAULT 'day',
+    ADD COLUMN start_session day_session_enum NOT NULL DEFAULT 'am',
+    ADD COLUMN end_session day_session_enum NOT NULL DEFAULT 'pm',
+    ADD COLUMN period_start TIMESTAMP,
+    ADD COLUMN period_end TIMESTAMP,
+    ALTER COLUMN working_days TYPE NUMERIC(7,3);
+
+UPDATE leave_requests SET period_start = start_date::timestamp, period_end = end_date + INTERVAL '1 day';
+
+ALTER TABLE leave_requests
+    ALTER COLUMN period_start SET NOT NULL,
+    ALTER COLUMN period_end SET NOT NULL,
+    ADD CONSTRAINT chk_leave_requests_period CHECK (period_start < period_end);
+
+ALTER TABLE leave_balance_entries ALTER COLUMN days TYPE NUMERIC(7,3);
\ No newline at end of file
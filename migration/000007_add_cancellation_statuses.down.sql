@@ -0,0 +1,7 @@
+UPDATE leave_requests SET status = 'approved' WHERE status = 'cancellation_requested';
+DELETE FROM leave_requests WHERE status = 'cancelled';
+
+ALTER TABLE leave_requests ALTER COLUMN status TYPE TEXT;
+DROP TYPE leave_status_enum;
+CREATE TYPE leave_status_enum AS ENUM ('draft', 'waiting_approval', 'approved', 'rejected');
+ALTER TABLE leave_requests ALTER COLUMN status TYPE leave_status_enum USING status::leave_status_enum;
\ No newline at end of file
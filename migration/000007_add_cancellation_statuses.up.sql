@@ -0,0 +1,2 @@
+ALTER TYPE leave_status_enum ADD VALUE IF NOT EXISTS 'cancellation_requested';
+ALTER TYPE leave_status_enum ADD VALUE IF NOT EXISTS 'cancelled';
\ No newline at end of file
@@ -0,0 +1,4 @@
+ALTER TABLE users ADD COLUMN manager_id INTEGER REFERENCES users(id) ON DELETE SET NULL;
+ALTER TABLE users ADD CONSTRAINT chk_users_manager_not_self CHECK (manager_id <> id);
+
+CREATE INDEX idx_users_manager ON users (manager_id);
\ No newline at end of file
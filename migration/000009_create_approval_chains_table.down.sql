@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS leave_request_approvals;
+DROP TABLE IF EXISTS approval_chains;
+
+DROP TYPE IF EXISTS approval_step_status_enum;
+DROP TYPE IF EXISTS approver_kind_enum;
\ No newline at end of file
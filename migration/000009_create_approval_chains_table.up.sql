@@ -0,0 +1,24 @@
+CREATE TYPE approver_kind_enum AS ENUM ('manager', 'admin');
+CREATE TYPE approval_step_status_enum AS ENUM ('pending', 'approved', 'rejected', 'skipped');
+
+CREATE TABLE approval_chains (
+    id SERIAL PRIMARY KEY,
+    type leave_type_enum NOT NULL,
+    min_working_days NUMERIC(7,3) NOT NULL DEFAULT 0 CHECK (min_working_days >= 0),
+    steps approver_kind_enum[] NOT NULL CHECK (cardinality(steps) > 0),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (type, min_working_days)
+);
+
+CREATE TABLE leave_request_approvals (
+    id SERIAL PRIMARY KEY,
+    leave_request_id INTEGER NOT NULL REFERENCES leave_requests(id) ON DELETE CASCADE,
+    step_order INTEGER NOT NULL CHECK (step_order > 0),
+    approver_kind approver_kind_enum NOT NULL,
+    status approval_step_status_enum NOT NULL DEFAULT 'pending',
+    approver_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    decided_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (leave_request_id, step_order)
+);
\ No newline at end of file
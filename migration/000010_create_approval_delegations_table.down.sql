@@ -0,0 +1,3 @@
+ALTER TABLE leave_request_approvals DROP COLUMN IF EXISTS on_behalf_of_id;
+
+DROP TABLE IF EXISTS approval_delegations;
\ No newline at end of file
@@ -0,0 +1,14 @@
+CREATE TABLE approval_delegations (
+    id SERIAL PRIMARY KEY,
+    delegator_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    delegate_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    start_date DATE NOT NULL,
+    end_date DATE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CHECK (start_date <= end_date),
+    CHECK (delegator_id <> delegate_id)
+);
+
+CREATE INDEX idx_approval_delegations_delegate ON approval_delegations (delegate_id, start_date, end_date);
+
+ALTER TABLE leave_request_approvals ADD COLUMN on_behalf_of_id INTEGER REFERENCES users(id) ON DELETE SET NULL;
\ No newline at end of file
@@ -0,0 +1,7 @@
+ALTER TABLE leave_request_approvals DROP COLUMN IF EXISTS comment;
+
+ALTER TABLE leave_requests
+    DROP COLUMN IF EXISTS approval_comment,
+    DROP COLUMN IF EXISTS rejection_reason,
+    DROP COLUMN IF EXISTS decided_at,
+    DROP COLUMN IF EXISTS decided_by;
\ No newline at end of file
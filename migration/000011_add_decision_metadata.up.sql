@@ -0,0 +1,7 @@
+ALTER TABLE leave_requests
+    ADD COLUMN decided_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    ADD COLUMN decided_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN rejection_reason TEXT,
+    ADD COLUMN approval_comment TEXT;
+
+ALTER TABLE leave_request_approvals ADD COLUMN comment TEXT;
\ No newline at end of file
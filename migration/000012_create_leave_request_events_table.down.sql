@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS leave_request_events;
+DROP TYPE IF EXISTS leave_request_action_enum;
\ No newline at end of file
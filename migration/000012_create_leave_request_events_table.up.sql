@@ -0,0 +1,14 @@
+CREATE TYPE leave_request_action_enum AS ENUM ('create', 'edit', 'submit', 'approve', 'reject', 'cancel', 'request_cancellation', 'reject_cancellation');
+
+CREATE TABLE leave_request_events (
+    id SERIAL PRIMARY KEY,
+    leave_request_id INTEGER NOT NULL REFERENCES leave_requests(id) ON DELETE CASCADE,
+    actor_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    action leave_request_action_enum NOT NULL,
+    previous_status leave_status_enum,
+    new_status leave_status_enum NOT NULL,
+    payload JSONB,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_leave_request_events_request ON leave_request_events (leave_request_id, created_at);
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE leave_requests DROP COLUMN IF EXISTS version;
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE leave_requests DROP CONSTRAINT IF EXISTS excl_leave_requests_approved_overlap;
\ No newline at end of file
@@ -0,0 +1,6 @@
+CREATE EXTENSION IF NOT EXISTS btree_gist;
+
+ALTER TABLE leave_requests
+    ADD CONSTRAINT excl_leave_requests_approved_overlap
+    EXCLUDE USING gist (user_id WITH =, tsrange(period_start, period_end) WITH &&)
+    WHERE (status IN ('approved', 'cancellation_requested'));
\ No newline at end of file
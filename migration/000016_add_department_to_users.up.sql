@@ -0,0 +1 @@
+ALTER TABLE users ADD COLUMN department VARCHAR(100);
\ No newline at end of file
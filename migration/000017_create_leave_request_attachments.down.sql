@@ -0,0 +1 @@
+DROP TABLE IF EXISTS leave_request_attachments;
\ No newline at end of file
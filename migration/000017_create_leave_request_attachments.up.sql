@@ -0,0 +1,12 @@
+CREATE TABLE leave_request_attachments (
+    id SERIAL PRIMARY KEY,
+    leave_request_id INTEGER NOT NULL REFERENCES leave_requests(id) ON DELETE CASCADE,
+    uploaded_by INTEGER NOT NULL REFERENCES users(id),
+    file_name VARCHAR(255) NOT NULL,
+    content_type VARCHAR(100) NOT NULL,
+    size_bytes BIGINT NOT NULL,
+    storage_key VARCHAR(255) NOT NULL UNIQUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_leave_request_attachments_leave_request ON leave_request_attachments (leave_request_id);
\ No newline at end of file
@@ -0,0 +1,19 @@
+CREATE TYPE leave_type_enum AS ENUM ('annual', 'sick', 'unpaid');
+
+ALTER TABLE approval_chains
+    DROP CONSTRAINT fk_approval_chains_type,
+    ALTER COLUMN type TYPE leave_type_enum USING type::leave_type_enum;
+
+ALTER TABLE accrual_policies
+    DROP CONSTRAINT fk_accrual_policies_type,
+    ALTER COLUMN type TYPE leave_type_enum USING type::leave_type_enum;
+
+ALTER TABLE leave_balance_entries
+    DROP CONSTRAINT fk_leave_balance_entries_type,
+    ALTER COLUMN type TYPE leave_type_enum USING type::leave_type_enum;
+
+ALTER TABLE leave_requests
+    DROP CONSTRAINT fk_leave_requests_type,
+    ALTER COLUMN type TYPE leave_type_enum USING type::leave_type_enum;
+
+DROP TABLE IF EXISTS leave_types;
\ No newline at end of file
@@ -0,0 +1,37 @@
+CREATE TABLE leave_types (
+    code VARCHAR(30) PRIMARY KEY CHECK (code ~ '^[a-z][a-z0-9_]*$'),
+    name VARCHAR(100) NOT NULL,
+    paid BOOLEAN NOT NULL DEFAULT TRUE,
+    deducts_balance BOOLEAN NOT NULL DEFAULT TRUE,
+    requires_attachment BOOLEAN NOT NULL DEFAULT FALSE,
+    attachment_after_days NUMERIC(5,1) NOT NULL DEFAULT 0 CHECK (attachment_after_days >= 0),
+    max_consecutive_days NUMERIC(5,1) CHECK (max_consecutive_days > 0),
+    min_notice_days INTEGER NOT NULL DEFAULT 0 CHECK (min_notice_days >= 0),
+    allowed_roles role_type[] NOT NULL DEFAULT '{}',
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO leave_types (code, name, paid, deducts_balance, requires_attachment, attachment_after_days) VALUES
+    ('annual', 'Annual Leave', TRUE, TRUE, FALSE, 0),
+    ('sick', 'Sick Leave', TRUE, TRUE, TRUE, 2),
+    ('unpaid', 'Unpaid Leave', FALSE, FALSE, FALSE, 0);
+
+ALTER TABLE leave_requests
+    ALTER COLUMN type TYPE VARCHAR(30) USING type::text,
+    ADD CONSTRAINT fk_leave_requests_type FOREIGN KEY (type) REFERENCES leave_types(code);
+
+ALTER TABLE leave_balance_entries
+    ALTER COLUMN type TYPE VARCHAR(30) USING type::text,
+    ADD CONSTRAINT fk_leave_balance_entries_type FOREIGN KEY (type) REFERENCES leave_types(code);
+
+ALTER TABLE accrual_policies
+    ALTER COLUMN type TYPE VARCHAR(30) USING type::text,
+    ADD CONSTRAINT fk_accrual_policies_type FOREIGN KEY (type) REFERENCES leave_types(code);
+
+ALTER TABLE approval_chains
+    ALTER COLUMN type TYPE VARCHAR(30) USING type::text,
+    ADD CONSTRAINT fk_approval_chains_type FOREIGN KEY (type) REFERENCES leave_types(code);
+
+DROP TYPE leave_type_enum;
\ No newline at end of file
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS leave_policies;
+ALTER TABLE users DROP COLUMN IF EXISTS hire_date;
\ No newline at end of file
@@ -0,0 +1,23 @@
+ALTER TABLE users ADD COLUMN hire_date DATE;
+UPDATE users SET hire_date = COALESCE(created_at::date, CURRENT_DATE);
+ALTER TABLE users ALTER COLUMN hire_date SET NOT NULL, ALTER COLUMN hire_date SET DEFAULT CURRENT_DATE;
+
+CREATE TABLE leave_policies (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    leave_type VARCHAR(30) NOT NULL REFERENCES leave_types(code),
+    role role_type,
+    department VARCHAR(100),
+    min_tenure_months INTEGER NOT NULL DEFAULT 0 CHECK (min_tenure_months >= 0),
+    max_tenure_months INTEGER CHECK (max_tenure_months > min_tenure_months),
+    effective_from DATE NOT NULL,
+    effective_to DATE CHECK (effective_to >= effective_from),
+    entitled BOOLEAN NOT NULL DEFAULT TRUE,
+    days_per_year NUMERIC(5,2) CHECK (days_per_year >= 0),
+    max_consecutive_days NUMERIC(5,1) CHECK (max_consecutive_days > 0),
+    min_notice_days INTEGER CHECK (min_notice_days >= 0),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_leave_policies_leave_type ON leave_policies (leave_type, effective_from);
\ No newline at end of file
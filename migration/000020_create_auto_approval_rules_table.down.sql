@@ -0,0 +1,3 @@
+ALTER TABLE leave_request_approvals DROP COLUMN IF EXISTS auto_approval_rule_id;
+ALTER TABLE leave_request_events DROP COLUMN IF EXISTS auto_approval_rule_id;
+DROP TABLE IF EXISTS auto_approval_rules;
\ No newline at end of file
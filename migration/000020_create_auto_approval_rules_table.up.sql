@@ -0,0 +1,17 @@
+CREATE TABLE auto_approval_rules (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    leave_type VARCHAR(30) REFERENCES leave_types(code),
+    role role_type,
+    department VARCHAR(100),
+    max_working_days NUMERIC(5,1) CHECK (max_working_days > 0),
+    min_notice_days INTEGER CHECK (min_notice_days >= 0),
+    min_balance_after NUMERIC(5,1),
+    max_team_absent INTEGER CHECK (max_team_absent >= 0),
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE leave_request_events ADD COLUMN auto_approval_rule_id INTEGER REFERENCES auto_approval_rules(id) ON DELETE SET NULL;
+ALTER TABLE leave_request_approvals ADD COLUMN auto_approval_rule_id INTEGER REFERENCES auto_approval_rules(id) ON DELETE SET NULL;
\ No newline at end of file
@@ -0,0 +1,10 @@
+DELETE FROM leave_request_events e WHERE NOT EXISTS (SELECT 1 FROM leave_requests lr WHERE lr.id = e.leave_request_id);
+
+ALTER TABLE leave_request_events ALTER COLUMN new_status SET NOT NULL;
+ALTER TABLE leave_request_events ADD CONSTRAINT leave_request_events_leave_request_id_fkey
+    FOREIGN KEY (leave_request_id) REFERENCES leave_requests(id) ON DELETE CASCADE;
+
+ALTER TABLE leave_request_events ALTER COLUMN action TYPE TEXT;
+DROP TYPE leave_request_action_enum;
+CREATE TYPE leave_request_action_enum AS ENUM ('create', 'edit', 'submit', 'approve', 'reject', 'cancel', 'request_cancellation', 'reject_cancellation');
+ALTER TABLE leave_request_events ALTER COLUMN action TYPE leave_request_action_enum USING action::leave_request_action_enum;
\ No newline at end of file
@@ -0,0 +1,4 @@
+ALTER TYPE leave_request_action_enum ADD VALUE IF NOT EXISTS 'delete';
+
+ALTER TABLE leave_request_events DROP CONSTRAINT IF EXISTS leave_request_events_leave_request_id_fkey;
+ALTER TABLE leave_request_events ALTER COLUMN new_status DROP NOT NULL;
\ No newline at end of file